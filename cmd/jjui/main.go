@@ -172,6 +172,10 @@ func run() int {
 	}
 	defer scripting.CloseVM(appContext)
 
+	// plugins that fail to load are reported in the UI, and don't stop jjui
+	// from starting
+	scripting.LoadPlugins(appContext, config.Current, filepath.Join(config.GetConfigDir(), "plugins"))
+
 	if luaSource, err := config.LoadLuaConfigFile(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading config.lua: %v\n", err)
 		return 1
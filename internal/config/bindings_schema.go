@@ -139,3 +139,19 @@ func validateActionReferencesAndArgs(actions []ActionConfig, bindings []BindingC
 	}
 	return nil
 }
+
+// GetBindingKeyHint returns the first key bound to the action, or an empty string.
+func GetBindingKeyHint(c *Config, action string) string {
+	for _, b := range c.Bindings {
+		if b.Action != action {
+			continue
+		}
+		if len(b.Key) > 0 {
+			return b.Key[0]
+		}
+		if len(b.Seq) > 0 {
+			return strings.Join(b.Seq, " ")
+		}
+	}
+	return ""
+}
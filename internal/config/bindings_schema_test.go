@@ -127,3 +127,13 @@ key = ["b"]
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "requires arg")
 }
+
+func TestGetBindingKeyHint(t *testing.T) {
+	config := &Config{Bindings: []BindingConfig{
+		{Action: "ui.rebase_onto_trunk", Scope: "revisions", Key: StringList{"shift+t"}},
+		{Action: "ui.open_git", Scope: "revisions", Seq: StringList{"g", "g"}},
+	}}
+	assert.Equal(t, "shift+t", GetBindingKeyHint(config, "ui.rebase_onto_trunk"))
+	assert.Equal(t, "g g", GetBindingKeyHint(config, "ui.open_git"))
+	assert.Equal(t, "", GetBindingKeyHint(config, "ui.unknown"))
+}
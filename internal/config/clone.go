@@ -0,0 +1,56 @@
+package config
+
+import "reflect"
+
+// Clone returns a copy of the config that shares nothing with it, so that
+// changes can be made to the copy and checked before they are kept.
+func (c *Config) Clone() *Config {
+	clone := new(Config)
+	deepCopy(reflect.ValueOf(clone).Elem(), reflect.ValueOf(c).Elem())
+	return clone
+}
+
+func deepCopy(dst reflect.Value, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.New(src.Type().Elem()))
+		deepCopy(dst.Elem(), src.Elem())
+	case reflect.Struct:
+		dst.Set(src)
+		for i := range src.NumField() {
+			if dst.Field(i).CanSet() {
+				deepCopy(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := range src.Len() {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		for iter := src.MapRange(); iter.Next(); {
+			value := reflect.New(src.Type().Elem()).Elem()
+			deepCopy(value, iter.Value())
+			dst.SetMapIndex(iter.Key(), value)
+		}
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		value := reflect.New(src.Elem().Type()).Elem()
+		deepCopy(value, src.Elem())
+		dst.Set(value)
+	default:
+		dst.Set(src)
+	}
+}
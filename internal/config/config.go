@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -26,8 +27,11 @@ type Config struct {
 	Preview         PreviewConfig   `toml:"preview"`
 	OpLog           OpLogConfig     `toml:"oplog"`
 	Limit           int             `toml:"limit"`
+	Bookmarks       BookmarksConfig `toml:"bookmarks"`
 	Git             GitConfig       `toml:"git"`
+	Timeouts        TimeoutsConfig  `toml:"timeouts"`
 	Ssh             SshConfig       `toml:"ssh"`
+	Exec            ExecConfig      `toml:"exec"`
 }
 
 type Color struct {
@@ -109,14 +113,60 @@ type UIConfig struct {
 	Colors map[string]Color `toml:"colors"`
 	// TODO(ilyagr): It might make sense to rename this to `auto_refresh_period` to match `--period` option
 	// once we have a mechanism to deprecate the old name softly.
-	AutoRefreshInterval        int `toml:"auto_refresh_interval"`
-	FlashMessageDisplaySeconds int `toml:"flash_message_display_seconds"`
+	AutoRefreshInterval        int                 `toml:"auto_refresh_interval"`
+	FlashMessageDisplaySeconds int                 `toml:"flash_message_display_seconds"`
+	Notifications              NotificationsConfig `toml:"notifications"`
+	Terminal                   TerminalConfig      `toml:"terminal"`
 }
 
 func GetExpiringFlashMessageTimeout(c *Config) time.Duration {
 	return time.Duration(c.UI.FlashMessageDisplaySeconds) * time.Second
 }
 
+// NotificationsConfig controls the terminal notifications sent when a command
+// finishes while the terminal is not focused.
+type NotificationsConfig struct {
+	Method      string `toml:"method"`
+	MinDuration int    `toml:"min_duration"`
+}
+
+type NotificationMethod int
+
+const (
+	NotificationMethodNone NotificationMethod = iota
+	NotificationMethodOSC9
+	NotificationMethodOSC777
+	NotificationMethodBell
+)
+
+func GetNotificationMethod(c *Config) (NotificationMethod, error) {
+	switch value := c.UI.Notifications.Method; value {
+	case "", "none":
+		return NotificationMethodNone, nil
+	case "osc9":
+		return NotificationMethodOSC9, nil
+	case "osc777":
+		return NotificationMethodOSC777, nil
+	case "bell":
+		return NotificationMethodBell, nil
+	default:
+		return NotificationMethodNone, fmt.Errorf("invalid value for 'ui.notifications.method': %q (expected one of: none, osc9, osc777, bell)", value)
+	}
+}
+
+// GetNotificationMinDuration is how long a command has to run before its
+// completion is notified.
+func GetNotificationMinDuration(c *Config) time.Duration {
+	return time.Duration(c.UI.Notifications.MinDuration) * time.Second
+}
+
+// TerminalConfig controls the pane that runs shell commands and interactive
+// jj commands without suspending the UI.
+type TerminalConfig struct {
+	Embedded         bool    `toml:"embedded"`
+	HeightPercentage float64 `toml:"height_percentage"`
+}
+
 type RevisionsConfig struct {
 	LogBatching  bool   `toml:"log_batching"`
 	LogBatchSize int    `toml:"log_batch_size"`
@@ -228,6 +278,9 @@ type SuggestConfig struct {
 
 type SuggestExecConfig struct {
 	Mode string `toml:"mode"`
+	// Completion completes jj subcommands, flags and their values in the
+	// exec prompt.
+	Completion bool `toml:"completion"`
 }
 
 func GetSuggestExecMode(c *Config) (SuggestMode, error) {
@@ -243,8 +296,41 @@ func GetSuggestExecMode(c *Config) (SuggestMode, error) {
 	}
 }
 
+type BookmarksConfig struct {
+	// NameTemplates prefill the set bookmark input, e.g. "{user}/{description_slug}" or "push-{change_id}".
+	NameTemplates []string `toml:"name_templates"`
+	// NamePattern is a regular expression new bookmark names must match.
+	NamePattern string `toml:"name_pattern"`
+}
+
+func GetBookmarkNamePattern(c *Config) (*regexp.Regexp, error) {
+	pattern := strings.TrimSpace(c.Bookmarks.NamePattern)
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for 'bookmarks.name_pattern': %w", err)
+	}
+	return re, nil
+}
+
+// ValidateBookmarkName checks a new bookmark name against the naming policy.
+func ValidateBookmarkName(c *Config, name string) error {
+	pattern, err := GetBookmarkNamePattern(c)
+	if err != nil {
+		return err
+	}
+	if pattern != nil && !pattern.MatchString(name) {
+		return fmt.Errorf("bookmark name %q does not match the naming policy %q", name, pattern.String())
+	}
+	return nil
+}
+
 type GitConfig struct {
-	DefaultRemote string `toml:"default_remote"`
+	DefaultRemote     string   `toml:"default_remote"`
+	ConfirmPush       []string `toml:"confirm_push"`
+	AutoFetchInterval int      `toml:"auto_fetch_interval"`
 }
 
 func GetGitDefaultRemote(c *Config) string {
@@ -255,6 +341,59 @@ func GetGitDefaultRemote(c *Config) string {
 	return remote
 }
 
+// ShouldConfirmPush reports whether the push menu item of the given kind
+// needs a dry-run preview before pushing. "*" matches every kind.
+func ShouldConfirmPush(c *Config, kind string) bool {
+	for _, k := range c.Git.ConfirmPush {
+		k = strings.TrimSpace(k)
+		if k == "*" || k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Command classes, each with its own entry in the `timeouts` section.
+const (
+	CommandClassPreview  = "preview"
+	CommandClassLog      = "log"
+	CommandClassMutation = "mutation"
+	CommandClassGit      = "git"
+)
+
+// TimeoutsConfig holds the number of seconds a command of each class may run
+// before it is killed. 0 means no timeout.
+type TimeoutsConfig struct {
+	Preview  int `toml:"preview"`
+	Log      int `toml:"log"`
+	Mutation int `toml:"mutation"`
+	Git      int `toml:"git"`
+	// Script is how long a Lua script may run before it gives control back to
+	// jjui, for example by waiting for a command.
+	Script int `toml:"script"`
+}
+
+func GetCommandTimeout(c *Config, class string) time.Duration {
+	var seconds int
+	switch class {
+	case CommandClassPreview:
+		seconds = c.Timeouts.Preview
+	case CommandClassLog:
+		seconds = c.Timeouts.Log
+	case CommandClassMutation:
+		seconds = c.Timeouts.Mutation
+	case CommandClassGit:
+		seconds = c.Timeouts.Git
+	}
+	return time.Duration(max(0, seconds)) * time.Second
+}
+
 type SshConfig struct {
 	HijackAskpass bool `toml:"hijack_askpass"`
 }
+
+type ExecConfig struct {
+	// Pager shows the output of read-only jj commands run with `:` in a pager,
+	// the other commands run in the terminal.
+	Pager bool `toml:"pager"`
+}
@@ -55,6 +55,55 @@ flash_message_display_seconds = 10
 	assert.Equal(t, 10*time.Second, GetExpiringFlashMessageTimeout(config))
 }
 
+func TestLoad_Notifications(t *testing.T) {
+	content := `
+[ui.notifications]
+method = "osc777"
+min_duration = 30
+`
+	config := &Config{}
+	err := config.Load(content, "")
+	assert.NoError(t, err)
+	method, err := GetNotificationMethod(config)
+	assert.NoError(t, err)
+	assert.Equal(t, NotificationMethodOSC777, method)
+	assert.Equal(t, 30*time.Second, GetNotificationMinDuration(config))
+
+	config.UI.Notifications.Method = "popup"
+	_, err = GetNotificationMethod(config)
+	assert.Error(t, err)
+}
+
+func TestLoad_ConfirmPush(t *testing.T) {
+	content := `
+[git]
+confirm_push = ["all", "deleted"]
+`
+	config := &Config{}
+	err := config.Load(content, "")
+	assert.NoError(t, err)
+	assert.True(t, ShouldConfirmPush(config, "all"))
+	assert.True(t, ShouldConfirmPush(config, "deleted"))
+	assert.False(t, ShouldConfirmPush(config, "tracked"))
+
+	config.Git.ConfirmPush = []string{"*"}
+	assert.True(t, ShouldConfirmPush(config, "tracked"))
+}
+
+func TestLoad_Timeouts(t *testing.T) {
+	content := `
+[timeouts]
+preview = 5
+git = 120
+`
+	config := &Config{}
+	err := config.Load(content, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, GetCommandTimeout(config, CommandClassPreview))
+	assert.Equal(t, 2*time.Minute, GetCommandTimeout(config, CommandClassGit))
+	assert.Zero(t, GetCommandTimeout(config, CommandClassMutation))
+}
+
 func TestLoad_Colors_StringAndObject(t *testing.T) {
 	content := `
 [ui.colors]
@@ -92,3 +141,36 @@ explicit_false = { fg = "blue", underline = false }
 		assert.False(t, *config.UI.Colors["explicit_false"].Underline)
 	}
 }
+
+func TestValidateBookmarkName(t *testing.T) {
+	c := &Config{}
+	assert.NoError(t, ValidateBookmarkName(c, "anything"))
+
+	c.Bookmarks.NamePattern = `^(feat|fix)/[a-z0-9-]+$`
+	assert.NoError(t, ValidateBookmarkName(c, "feat/templates"))
+	assert.ErrorContains(t, ValidateBookmarkName(c, "templates"), "does not match the naming policy")
+
+	c.Bookmarks.NamePattern = `(`
+	assert.ErrorContains(t, ValidateBookmarkName(c, "templates"), "bookmarks.name_pattern")
+}
+
+func TestConfig_CloneSharesNothing(t *testing.T) {
+	bold := true
+	c := &Config{
+		Actions: []ActionConfig{{Name: "a"}},
+		UI: UIConfig{Colors: map[string]Color{
+			"selected": {Fg: "red", Bold: &bold},
+		}},
+	}
+
+	clone := c.Clone()
+	clone.Actions[0].Name = "b"
+	*clone.UI.Colors["selected"].Bold = false
+	clone.UI.Colors["text"] = Color{Fg: "blue"}
+
+	assert.Equal(t, "a", c.Actions[0].Name)
+	assert.True(t, *c.UI.Colors["selected"].Bold)
+	assert.NotContains(t, c.UI.Colors, "text")
+	assert.Equal(t, "red", clone.UI.Colors["selected"].Fg)
+	assert.Equal(t, loadDefaultConfig(), loadDefaultConfig().Clone())
+}
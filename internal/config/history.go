@@ -51,6 +51,9 @@ type History struct {
 	// appendOnly becomes true after read from fs
 	appendOnly bool
 
+	// limit is how many entries the file keeps, 0 for no limit
+	limit int
+
 	// unique optimizations
 	unique       bool
 	loadedUnique uniqueMap
@@ -68,6 +71,13 @@ func (h *History) Entries() HistoryEntries {
 	return append(h.loaded, h.append...)
 }
 
+// Loaded returns only the entries read from the filesystem, i.e. the ones
+// stored by earlier sessions.
+func (h *History) Loaded() HistoryEntries {
+	h.Entries()
+	return h.loaded
+}
+
 func (h *History) Append(line string) {
 	if h.unique {
 		// check first, most likely smaller
@@ -82,6 +92,20 @@ func (h *History) Append(line string) {
 	h.append = append(h.append, line)
 }
 
+// Remove drops an entry appended by the current runtime, so that it isn't
+// written to the filesystem.
+func (h *History) Remove(line string) {
+	if i := slices.Index(h.append, line); i >= 0 {
+		h.append = slices.Delete(h.append, i, i+1)
+		delete(h.appendUnique, line)
+	}
+}
+
+// SetLimit makes flushing keep only the newest limit entries in the file.
+func (h *History) SetLimit(limit int) {
+	h.limit = limit
+}
+
 // Should be called ONLY at program termination to
 // flush new history entries into the filesystem.
 func (h Histories) Flush() {
@@ -111,6 +135,10 @@ func (h *History) flush() {
 	if err := os.MkdirAll(basedir, 0755); err != nil {
 		return // do nothing, could not have directory
 	}
+	if h.limit > 0 {
+		h.rewrite(file, content)
+		return
+	}
 	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return
@@ -120,6 +148,30 @@ func (h *History) flush() {
 	f.Write(data)
 }
 
+// rewrite replaces the file with its newest entries, re-reading it first since
+// another session may have written to it in the meantime.
+func (h *History) rewrite(file string, content HistoryEntries) {
+	entries := slices.DeleteFunc(h.load(), func(entry string) bool {
+		return len(strings.TrimSpace(entry)) == 0
+	})
+	entries = append(entries, content...)
+	entries = entries[max(0, len(entries)-h.limit):]
+	f, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return
+	}
+	_, err = f.WriteString(strings.Join([]string(entries), "\n") + "\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), file)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
+
 func (h *History) historyFile() string {
 	return filepath.Join(h.historyDir(), strings.ReplaceAll(string(h.key), " ", "_"))
 }
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory_FlushKeepsNewestEntriesUpToLimit(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("relies on XDG_CACHE_HOME to keep the history out of the user's cache")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	earlier := NewHistories()
+	earlier.GetHistory("commands", false).Append("first")
+	earlier.GetHistory("commands", false).Append("second")
+	earlier.Flush()
+
+	current := NewHistories()
+	h := current.GetHistory("commands", false)
+	h.SetLimit(3)
+	h.Append("third")
+	h.Append("deleted")
+	h.Append("fourth")
+	h.Remove("deleted")
+	current.Flush()
+
+	// the file ends with a newline, so the last entry read back is empty
+	assert.Equal(t, HistoryEntries{"second", "third", "fourth", ""}, NewHistories().GetHistory("commands", false).Loaded())
+}
@@ -13,6 +13,10 @@ type JJConfig struct {
 	Templates struct {
 		Log string `toml:"log"`
 	} `toml:"templates"`
+	User struct {
+		Name  string `toml:"name"`
+		Email string `toml:"email"`
+	} `toml:"user"`
 }
 
 func (c *JJConfig) GetApplicableColors() map[string]Color {
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -71,6 +73,34 @@ func GetConfigDir() string {
 	return filepath.Dir(configFile)
 }
 
+// GetStateDir returns the directory jjui keeps its state in, such as the data
+// scripts store. XDG_STATE_HOME is respected on every platform.
+func GetStateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "jjui")
+	}
+	if runtime.GOOS != "windows" {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, ".local", "state", "jjui")
+		}
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "jjui", "state")
+	}
+	return filepath.Join(os.TempDir(), "jjui", "state")
+}
+
+// GetRepoID names the state jjui keeps per repository, such as the command log
+// and the data scripts store. It is a hash of the repository's absolute
+// location since the path itself can't be used as a file name.
+func GetRepoID(location string) string {
+	if abs, err := filepath.Abs(location); err == nil {
+		location = abs
+	}
+	sum := sha256.Sum256([]byte(location))
+	return hex.EncodeToString(sum[:8])
+}
+
 func loadDefaultConfig() *Config {
 	data, err := configFS.ReadFile("default/config.toml")
 	if err != nil {
@@ -1,12 +1,17 @@
 package jj
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 )
 
 const (
 	moveBookmarkTemplate = `separate(";", name, if(remote, "remote", "."), tracked, conflict, normal_target.contained_in("%s"), normal_target.commit_id().shortest(1)) ++ "\n"`
 	allBookmarkTemplate  = `separate(";", name, if(remote, remote, "."), tracked, conflict, 'false', normal_target.commit_id().shortest(1)) ++ "\n"`
+	// the tracking counts of a remote bookmark are from the remote's point of view,
+	// so its behind count is how far the local bookmark is ahead
+	bookmarkSyncTemplate = `if(remote, if(tracked, separate(";", name, remote, tracking_behind_count.lower(), tracking_ahead_count.lower()) ++ "\n"))`
 )
 
 type BookmarkRemote struct {
@@ -32,6 +37,20 @@ func (b Bookmark) IsTrackable() bool {
 	return b.Local != nil && len(b.Remotes) == 0
 }
 
+// TrackedRemote returns the first tracked entry of Remotes, or false when there is
+// none or the bookmark has no local target.
+func (b Bookmark) TrackedRemote() (BookmarkRemote, bool) {
+	if b.Local == nil {
+		return BookmarkRemote{}, false
+	}
+	for _, remote := range b.Remotes {
+		if remote.Tracked {
+			return remote, true
+		}
+	}
+	return BookmarkRemote{}, false
+}
+
 func ParseBookmarkListOutput(output string) []Bookmark {
 	lines := strings.Split(output, "\n")
 	bookmarkMap := make(map[string]*Bookmark)
@@ -98,3 +117,78 @@ func ParseBookmarkListOutput(output string) []Bookmark {
 	}
 	return bookmarks
 }
+
+// BookmarkSync describes how a local bookmark relates to its tracked remote counterpart.
+type BookmarkSync struct {
+	Remote   string
+	Ahead    int
+	Behind   int
+	Conflict bool
+}
+
+func (s BookmarkSync) NeedsPush() bool {
+	return s.Ahead > 0 && s.Behind == 0
+}
+
+func (s BookmarkSync) NeedsPull() bool {
+	return s.Behind > 0 && s.Ahead == 0
+}
+
+func (s BookmarkSync) Diverged() bool {
+	return s.Ahead > 0 && s.Behind > 0
+}
+
+func (s BookmarkSync) InSync() bool {
+	return s.Ahead == 0 && s.Behind == 0 && !s.Conflict
+}
+
+func (s BookmarkSync) String() string {
+	switch {
+	case s.Conflict:
+		return "conflicted"
+	case s.InSync():
+		return "in sync"
+	}
+	var parts []string
+	if s.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("↑%d", s.Ahead))
+	}
+	if s.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("↓%d", s.Behind))
+	}
+	if s.Diverged() {
+		parts = append(parts, "diverged")
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseBookmarkSyncOutput reads the output of BookmarkSyncList and returns the sync
+// status of each bookmark against the remote picked by TrackedRemote.
+func ParseBookmarkSyncOutput(bookmarks []Bookmark, output string) map[string]BookmarkSync {
+	counts := make(map[string]BookmarkSync)
+	for line := range strings.Lines(output) {
+		parts := strings.Split(strings.TrimSpace(line), ";")
+		if len(parts) < 4 {
+			continue
+		}
+		ahead, _ := strconv.Atoi(parts[2])
+		behind, _ := strconv.Atoi(parts[3])
+		counts[parts[0]+"@"+parts[1]] = BookmarkSync{Remote: parts[1], Ahead: ahead, Behind: behind}
+	}
+
+	syncs := make(map[string]BookmarkSync)
+	for _, b := range bookmarks {
+		remote, ok := b.TrackedRemote()
+		if !ok {
+			continue
+		}
+		if b.Conflict {
+			syncs[b.Name] = BookmarkSync{Remote: remote.Remote, Conflict: true}
+			continue
+		}
+		if sync, ok := counts[b.Name+"@"+remote.Remote]; ok {
+			syncs[b.Name] = sync
+		}
+	}
+	return syncs
+}
@@ -117,3 +117,69 @@ feature;origin;true;false;false;b`,
 		})
 	}
 }
+
+func TestBookmark_TrackedRemote(t *testing.T) {
+	bookmarks := ParseBookmarkListOutput(`main;.;false;false;false;b
+main;upstream;true;false;false;a
+main;origin;true;false;false;b
+feature;.;false;false;false;c
+feature;origin;false;false;false;c
+remote-only;origin;true;false;false;d`)
+
+	remote, ok := bookmarks[0].TrackedRemote()
+	assert.True(t, ok)
+	assert.Equal(t, "origin", remote.Remote)
+
+	_, ok = bookmarks[1].TrackedRemote()
+	assert.False(t, ok, "untracked remotes should be ignored")
+
+	_, ok = bookmarks[2].TrackedRemote()
+	assert.False(t, ok, "bookmarks without a local target have nothing to compare")
+}
+
+func TestParseBookmarkSyncOutput(t *testing.T) {
+	bookmarks := ParseBookmarkListOutput(`in-sync;.;false;false;false;a
+in-sync;origin;true;false;false;a
+ahead;.;false;false;false;b
+ahead;origin;true;false;false;c
+behind;.;false;false;false;d
+behind;origin;true;false;false;e
+diverged;.;false;false;false;f
+diverged;upstream;true;false;false;g
+diverged;origin;true;false;false;h
+conflicted;.;false;true;false;i
+conflicted;origin;true;false;false;j
+it's;.;false;false;false;k
+it's;origin;true;false;false;k
+local;.;false;false;false;l`)
+	syncs := ParseBookmarkSyncOutput(bookmarks, `in-sync;origin;0;0
+ahead;origin;2;0
+behind;origin;0;1
+diverged;upstream;5;5
+diverged;origin;1;2
+conflicted;origin;0;0
+it's;origin;0;0
+`)
+
+	tests := []struct {
+		name  string
+		want  BookmarkSync
+		label string
+	}{
+		{name: "in-sync", want: BookmarkSync{Remote: "origin"}, label: "in sync"},
+		{name: "ahead", want: BookmarkSync{Remote: "origin", Ahead: 2}, label: "↑2"},
+		{name: "behind", want: BookmarkSync{Remote: "origin", Behind: 1}, label: "↓1"},
+		{name: "diverged", want: BookmarkSync{Remote: "origin", Ahead: 1, Behind: 2}, label: "↑1 ↓2 diverged"},
+		{name: "conflicted", want: BookmarkSync{Remote: "origin", Conflict: true}, label: "conflicted"},
+		{name: "it's", want: BookmarkSync{Remote: "origin"}, label: "in sync"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sync, ok := syncs[tt.name]
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, sync)
+			assert.Equal(t, tt.label, sync.String())
+		})
+	}
+	assert.NotContains(t, syncs, "local", "untracked bookmarks have no sync status")
+}
@@ -0,0 +1,93 @@
+package jj
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+type CommandRecoveryKind int
+
+const (
+	// RecoveryRetry re-runs the failed command with an extra flag.
+	RecoveryRetry CommandRecoveryKind = iota
+	// RecoveryReload reloads the repository state.
+	RecoveryReload
+)
+
+// CommandRecovery is an action offered for a failed command.
+type CommandRecovery struct {
+	Kind CommandRecoveryKind
+	Flag string
+}
+
+func (r CommandRecovery) String() string {
+	if r.Kind == RecoveryReload {
+		return "reload"
+	}
+	return "retry with " + r.Flag
+}
+
+// RetryArgs returns the arguments of the failed command with the recovery
+// flag added, before the `--` that ends the flags if there is one.
+func (r CommandRecovery) RetryArgs(args []string) []string {
+	end := slices.Index(args, "--")
+	if end < 0 {
+		end = len(args)
+	}
+	if r.Flag == "" || slices.Contains(args[:end], r.Flag) {
+		return slices.Clone(args)
+	}
+	return slices.Insert(slices.Clone(args), end, r.Flag)
+}
+
+// bookmarkMoves are the subcommands that refuse to move a bookmark backwards
+// or sideways without --allow-backwards.
+var bookmarkMoves = [][]string{{"bookmark", "move"}, {"bookmark", "m"}, {"bookmark", "set"}, {"bookmark", "s"},
+	{"b", "move"}, {"b", "m"}, {"b", "set"}, {"b", "s"}}
+
+// commandErrorRecoveries are checked in order; the first match wins. Those
+// with subcommands are only offered for them, as only they take the flag.
+var commandErrorRecoveries = []struct {
+	pattern     *regexp.Regexp
+	subcommands [][]string
+	recovery    CommandRecovery
+}{
+	{regexp.MustCompile(`Commit \S+ is immutable`), nil, CommandRecovery{Kind: RecoveryRetry, Flag: "--ignore-immutable"}},
+	{regexp.MustCompile(`Refusing to move bookmark backwards or sideways`), bookmarkMoves, CommandRecovery{Kind: RecoveryRetry, Flag: "--allow-backwards"}},
+	{regexp.MustCompile(`Refusing to create new remote bookmark`), [][]string{{"git", "push"}}, CommandRecovery{Kind: RecoveryRetry, Flag: "--allow-new"}},
+	{regexp.MustCompile(`(?i)concurrent modification|sibling of the working copy's operation|working copy is stale`), nil, CommandRecovery{Kind: RecoveryReload}},
+}
+
+// ParseCommandError finds a recovery action for the stderr of the failed jj
+// command given by args.
+func ParseCommandError(args []string, stderr string) (CommandRecovery, bool) {
+	subcommand := subcommandOf(args, 2)
+	for _, r := range commandErrorRecoveries {
+		if r.subcommands != nil && !slices.ContainsFunc(r.subcommands, func(s []string) bool { return slices.Equal(s, subcommand) }) {
+			continue
+		}
+		if r.pattern.MatchString(stderr) {
+			return r.recovery, true
+		}
+	}
+	return CommandRecovery{}, false
+}
+
+// subcommandOf returns up to n words of the subcommand of the jj command
+// given by args, skipping the global flags and their values.
+func subcommandOf(args []string, n int) []string {
+	var subcommand []string
+	for i := 0; i < len(args) && len(subcommand) < n; i++ {
+		switch arg := args[i]; {
+		case arg == "--":
+			return subcommand
+		case GlobalFlagsWithValue[arg]:
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			subcommand = append(subcommand, arg)
+		}
+	}
+	return subcommand
+}
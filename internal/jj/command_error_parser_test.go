@@ -0,0 +1,72 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommandError(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		stderr   string
+		expected CommandRecovery
+	}{
+		{
+			name:     "immutable commit",
+			args:     []string{"abandon", "-r", "3a1b2c3d"},
+			stderr:   "Error: Commit \x1b[1m\x1b[38;5;4m3a1b2c3d\x1b[0m is immutable\nHint: Pass `--ignore-immutable` or configure the set of immutable commits",
+			expected: CommandRecovery{Kind: RecoveryRetry, Flag: "--ignore-immutable"},
+		},
+		{
+			name:     "sideways bookmark move",
+			args:     []string{"--color", "always", "bookmark", "move", "topic", "--to", "@"},
+			stderr:   "Error: Refusing to move bookmark backwards or sideways: topic\nHint: Use --allow-backwards to allow it.",
+			expected: CommandRecovery{Kind: RecoveryRetry, Flag: "--allow-backwards"},
+		},
+		{
+			name:     "new remote bookmark",
+			args:     []string{"git", "push", "--bookmark", "feature"},
+			stderr:   "Error: Refusing to create new remote bookmark feature@origin",
+			expected: CommandRecovery{Kind: RecoveryRetry, Flag: "--allow-new"},
+		},
+		{
+			name:     "concurrent operation",
+			args:     []string{"new"},
+			stderr:   "Error: The repo was loaded at operation 1a2b, which seems to be a sibling of the working copy's operation 3c4d",
+			expected: CommandRecovery{Kind: RecoveryReload},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recovery, ok := ParseCommandError(tt.args, tt.stderr)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, recovery)
+		})
+	}
+}
+
+func TestParseCommandError_Unknown(t *testing.T) {
+	_, ok := ParseCommandError([]string{"new", "xyz"}, "Error: Revision `xyz` doesn't exist")
+	assert.False(t, ok)
+}
+
+func TestParseCommandError_OnlyRetriesCommandsTakingTheFlag(t *testing.T) {
+	stderr := "Error: Refusing to move bookmark backwards or sideways: topic"
+	_, ok := ParseCommandError([]string{"git", "push", "--bookmark", "topic"}, stderr)
+	assert.False(t, ok, "git push has no --allow-backwards")
+	recovery, ok := ParseCommandError([]string{"b", "s", "topic", "-r", "@-"}, stderr)
+	assert.True(t, ok)
+	assert.Equal(t, "--allow-backwards", recovery.Flag)
+}
+
+func TestCommandRecovery_RetryArgs(t *testing.T) {
+	recovery := CommandRecovery{Kind: RecoveryRetry, Flag: "--ignore-immutable"}
+	args := []string{"abandon", "-r", "abc"}
+	assert.Equal(t, []string{"abandon", "-r", "abc", "--ignore-immutable"}, recovery.RetryArgs(args))
+	assert.Equal(t, []string{"abandon", "-r", "abc"}, args)
+	assert.Equal(t, []string{"abandon", "--ignore-immutable"}, recovery.RetryArgs([]string{"abandon", "--ignore-immutable"}))
+	assert.Equal(t, []string{"squash", "--ignore-immutable", "--", "file.txt"},
+		recovery.RetryArgs([]string{"squash", "--", "file.txt"}), "flags go before the separator")
+}
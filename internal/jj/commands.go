@@ -178,6 +178,12 @@ func BookmarkSet(revision string, name string) CommandArgs {
 	return []string{"bookmark", "set", "-r", revision, name}
 }
 
+// BookmarkNameFields prints the revision metadata available to bookmark name templates, one field per line.
+func BookmarkNameFields(revision string) CommandArgs {
+	const template = `change_id.short() ++ "\n" ++ commit_id.short() ++ "\n" ++ author.email().local() ++ "\n" ++ description.first_line() ++ "\n"`
+	return []string{"log", "-r", revision, "-n", "1", "--no-graph", "--template", template, "--color", "never", "--quiet", "--ignore-working-copy"}
+}
+
 func BookmarkMove(revision string, bookmark string, extraFlags ...string) CommandArgs {
 	args := []string{"bookmark", "move", bookmark, "--to", revision}
 	if extraFlags != nil {
@@ -186,24 +192,32 @@ func BookmarkMove(revision string, bookmark string, extraFlags ...string) Comman
 	return args
 }
 
-func BookmarkDelete(name string) CommandArgs {
-	return []string{"bookmark", "delete", name}
+func BookmarkCreate(revision string, name string) CommandArgs {
+	return []string{"bookmark", "create", "-r", revision, name}
+}
+
+func BookmarkRename(oldName string, newName string) CommandArgs {
+	return []string{"bookmark", "rename", oldName, newName}
 }
 
-func BookmarkForget(name string) CommandArgs {
-	return []string{"bookmark", "forget", name}
+func BookmarkDelete(names ...string) CommandArgs {
+	return append([]string{"bookmark", "delete"}, names...)
 }
 
-func BookmarkTrack(name string, remote string) CommandArgs {
-	args := []string{"bookmark", "track", name}
+func BookmarkForget(names ...string) CommandArgs {
+	return append([]string{"bookmark", "forget"}, names...)
+}
+
+func BookmarkTrack(remote string, names ...string) CommandArgs {
+	args := append([]string{"bookmark", "track"}, names...)
 	if remote != "" {
 		args = append(args, "--remote", remote)
 	}
 	return args
 }
 
-func BookmarkUntrack(name string, remote string) CommandArgs {
-	args := []string{"bookmark", "untrack", name}
+func BookmarkUntrack(remote string, names ...string) CommandArgs {
+	args := append([]string{"bookmark", "untrack"}, names...)
 	if remote != "" {
 		args = append(args, "--remote", remote)
 	}
@@ -253,6 +267,16 @@ func BookmarkListAll() CommandArgs {
 	return []string{"bookmark", "list", "-a", "--template", allBookmarkTemplate, "--color", "never", "--ignore-working-copy"}
 }
 
+// BookmarkSyncList reports, for every tracked remote bookmark, how many revisions the
+// local bookmark is ahead of and behind it.
+func BookmarkSyncList() CommandArgs {
+	return []string{"bookmark", "list", "--all-remotes", "--template", bookmarkSyncTemplate, "--color", "never", "--ignore-working-copy"}
+}
+
+func BookmarkListStale() CommandArgs {
+	return []string{"bookmark", "list", "-a", "--template", staleBookmarkTemplate, "--color", "never", "--ignore-working-copy"}
+}
+
 func TagList() CommandArgs {
 	return []string{"tag", "list", "--template", "name ++ '\n'", "--color", "never", "--ignore-working-copy"}
 }
@@ -273,8 +297,29 @@ func GitPush(flags ...string) CommandArgs {
 	return args
 }
 
+// UtilCompletion returns the completion script jj generates for the shell.
+func UtilCompletion(shell string) CommandArgs {
+	return []string{"util", "completion", shell, "--ignore-working-copy"}
+}
+
 func GitRemoteList() CommandArgs {
-	return []string{"git", "remote", "list"}
+	return []string{"git", "remote", "list", "--ignore-working-copy"}
+}
+
+func GitRemoteAdd(name string, url string) CommandArgs {
+	return []string{"git", "remote", "add", name, url}
+}
+
+func GitRemoteRemove(name string) CommandArgs {
+	return []string{"git", "remote", "remove", name}
+}
+
+func GitRemoteRename(oldName string, newName string) CommandArgs {
+	return []string{"git", "remote", "rename", oldName, newName}
+}
+
+func GitRemoteSetUrl(name string, url string) CommandArgs {
+	return []string{"git", "remote", "set-url", name, url}
 }
 
 func Rebase(from SelectedRevisions, sourcePrefix string, to string, target string, skipEmptied bool, ignoreImmutable bool) CommandArgs {
@@ -290,6 +335,11 @@ func Rebase(from SelectedRevisions, sourcePrefix string, to string, target strin
 	return args
 }
 
+// RebaseStacksOntoTrunk rebases the user's own mutable stacks that are not yet in trunk onto trunk().
+func RebaseStacksOntoTrunk() CommandArgs {
+	return []string{"rebase", "-s", "roots(trunk()..(mutable() & mine()))", "-d", "trunk()", "--skip-emptied"}
+}
+
 func RebaseInsert(from SelectedRevisions, sourcePrefix string, insertAfter string, insertBefore string, skipEmptied bool, ignoreImmutable bool) CommandArgs {
 	args := []string{"rebase"}
 	args = append(args, from.AsPrefixedArgs(sourcePrefix)...)
@@ -451,6 +501,28 @@ func GetIdsFromRevset(revset string) CommandArgs {
 	return []string{"log", "-r", revset, "--color", "never", "--no-graph", "--quiet", "--ignore-working-copy", "--template", template}
 }
 
+// ChangeIdList lists the change ids of the revisions of the default log
+// revset with the first line of their descriptions.
+func ChangeIdList(limit int) CommandArgs {
+	const template = `change_id.shortest(8) ++ "\t" ++ description.first_line() ++ "\n"`
+	return []string{"log", "--limit", strconv.Itoa(limit), "--color", "never", "--no-graph", "--quiet", "--ignore-working-copy", "--template", template}
+}
+
+// OpIdList lists the ids of the latest operations with their descriptions.
+func OpIdList(limit int) CommandArgs {
+	const template = `id.short() ++ "\t" ++ description.first_line() ++ "\n"`
+	return []string{"op", "log", "--limit", strconv.Itoa(limit), "--color", "never", "--no-graph", "--quiet", "--ignore-working-copy", "--template", template}
+}
+
+// FileList lists the files of the working copy.
+func FileList() CommandArgs {
+	return []string{
+		"file", "list",
+		"--color", "never", "--no-pager", "--quiet", "--ignore-working-copy",
+		"--template", "self.path() ++ \"\n\"",
+	}
+}
+
 func RevsetValidate(revset string) CommandArgs {
 	return []string{"log", "-r", revset, "-n", "1", "--ignore-working-copy"}
 }
@@ -0,0 +1,119 @@
+package jj
+
+import (
+	"fmt"
+	"strings"
+)
+
+type PushAction int
+
+const (
+	PushAdd PushAction = iota
+	PushMoveForward
+	PushMoveSideways
+	PushMoveBackward
+	PushDelete
+)
+
+func (a PushAction) String() string {
+	switch a {
+	case PushAdd:
+		return "create"
+	case PushMoveForward:
+		return "move forward"
+	case PushMoveSideways:
+		return "move sideways"
+	case PushMoveBackward:
+		return "move backward"
+	case PushDelete:
+		return "delete"
+	}
+	return ""
+}
+
+// PushUpdate is a single bookmark change planned by `jj git push --dry-run`.
+type PushUpdate struct {
+	Action   PushAction
+	Bookmark string
+	From     string
+	To       string
+}
+
+func (u PushUpdate) String() string {
+	switch u.Action {
+	case PushAdd:
+		return fmt.Sprintf("%s %s to %s", u.Action, u.Bookmark, u.To)
+	case PushDelete:
+		return fmt.Sprintf("%s %s from %s", u.Action, u.Bookmark, u.From)
+	}
+	return fmt.Sprintf("%s %s from %s to %s", u.Action, u.Bookmark, u.From, u.To)
+}
+
+type PushPlan struct {
+	Remote  string
+	Updates []PushUpdate
+}
+
+var pushActionPrefixes = []struct {
+	prefix string
+	action PushAction
+}{
+	{"Add bookmark ", PushAdd},
+	{"Move forward bookmark ", PushMoveForward},
+	{"Move sideways bookmark ", PushMoveSideways},
+	{"Move backward bookmark ", PushMoveBackward},
+	{"Delete bookmark ", PushDelete},
+}
+
+// ParsePushDryRunOutput extracts the planned bookmark updates from the
+// output of `jj git push --dry-run`. Lines it doesn't recognise are ignored.
+func ParsePushDryRunOutput(output string) PushPlan {
+	var plan PushPlan
+	for line := range strings.SplitSeq(output, "\n") {
+		line = strings.TrimSpace(line)
+		if remote, ok := strings.CutPrefix(line, "Changes to push to "); ok {
+			plan.Remote = strings.TrimSuffix(remote, ":")
+			continue
+		}
+		for _, p := range pushActionPrefixes {
+			rest, ok := strings.CutPrefix(line, p.prefix)
+			if !ok {
+				continue
+			}
+			if update, ok := parsePushUpdate(p.action, rest); ok {
+				plan.Updates = append(plan.Updates, update)
+			}
+			break
+		}
+	}
+	return plan
+}
+
+func parsePushUpdate(action PushAction, rest string) (PushUpdate, bool) {
+	update := PushUpdate{Action: action}
+	switch action {
+	case PushAdd:
+		name, to, ok := strings.Cut(rest, " to ")
+		if !ok {
+			return update, false
+		}
+		update.Bookmark, update.To = name, to
+	case PushDelete:
+		name, from, ok := strings.Cut(rest, " from ")
+		if !ok {
+			return update, false
+		}
+		update.Bookmark, update.From = name, from
+	default:
+		name, fromTo, ok := strings.Cut(rest, " from ")
+		if !ok {
+			return update, false
+		}
+		from, to, ok := strings.Cut(fromTo, " to ")
+		if !ok {
+			return update, false
+		}
+		update.Bookmark, update.From, update.To = name, from, to
+	}
+	return update, true
+}
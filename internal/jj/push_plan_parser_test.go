@@ -0,0 +1,39 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePushDryRunOutput(t *testing.T) {
+	output := `Changes to push to origin:
+  Add bookmark feature to 1a2b3c4d5e6f
+  Move forward bookmark main from 0a0a0a0a0a0a to 1b1b1b1b1b1b
+  Move sideways bookmark topic from 2c2c2c2c2c2c to 3d3d3d3d3d3d
+  Move backward bookmark release from 4e4e4e4e4e4e to 5f5f5f5f5f5f
+  Delete bookmark old from 6a6a6a6a6a6a
+Dry-run requested, not pushing.`
+
+	assert.Equal(t, PushPlan{
+		Remote: "origin",
+		Updates: []PushUpdate{
+			{Action: PushAdd, Bookmark: "feature", To: "1a2b3c4d5e6f"},
+			{Action: PushMoveForward, Bookmark: "main", From: "0a0a0a0a0a0a", To: "1b1b1b1b1b1b"},
+			{Action: PushMoveSideways, Bookmark: "topic", From: "2c2c2c2c2c2c", To: "3d3d3d3d3d3d"},
+			{Action: PushMoveBackward, Bookmark: "release", From: "4e4e4e4e4e4e", To: "5f5f5f5f5f5f"},
+			{Action: PushDelete, Bookmark: "old", From: "6a6a6a6a6a6a"},
+		},
+	}, ParsePushDryRunOutput(output))
+}
+
+func TestParsePushDryRunOutput_NothingChanged(t *testing.T) {
+	plan := ParsePushDryRunOutput("Nothing changed.")
+	assert.Empty(t, plan.Updates)
+}
+
+func TestPushUpdate_String(t *testing.T) {
+	assert.Equal(t, "create feature to abc", PushUpdate{Action: PushAdd, Bookmark: "feature", To: "abc"}.String())
+	assert.Equal(t, "move sideways topic from abc to def", PushUpdate{Action: PushMoveSideways, Bookmark: "topic", From: "abc", To: "def"}.String())
+	assert.Equal(t, "delete old from abc", PushUpdate{Action: PushDelete, Bookmark: "old", From: "abc"}.String())
+}
@@ -0,0 +1,36 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteArgs_SplitsBackIntoTheArgs(t *testing.T) {
+	tests := [][]string{
+		{"new", "main"},
+		{"log", "-r", "main::@ | trunk()"},
+		{"describe", "-m", "it's a \"fix\""},
+		{"bookmark", "set", "-r", "@-", "feature/x"},
+		{"describe", "-m", ""},
+		{"file", "show", `C:\dir\file with spaces.txt`},
+	}
+	for _, args := range tests {
+		line := QuoteArgs(args)
+		split, err := SplitArgs(line)
+		require.NoError(t, err, line)
+		assert.Equal(t, args, split, line)
+	}
+	assert.Equal(t, "bookmark set -r @- feature/x", QuoteArgs(tests[3]))
+	assert.Equal(t, "log -r 'main::@ | trunk()'", QuoteArgs(tests[1]))
+}
+
+func TestSplitArgs(t *testing.T) {
+	args, err := SplitArgs(`describe  -m "fix \"the\" bug" -r 'a | b' c\ d`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"describe", "-m", `fix "the" bug`, "-r", "a | b", "c d"}, args)
+
+	_, err = SplitArgs(`describe -m "unterminated`)
+	assert.Error(t, err)
+}
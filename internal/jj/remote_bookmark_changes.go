@@ -0,0 +1,82 @@
+package jj
+
+import (
+	"fmt"
+	"strings"
+)
+
+type RemoteBookmarkChangeKind int
+
+const (
+	RemoteBookmarkCreated RemoteBookmarkChangeKind = iota
+	RemoteBookmarkMoved
+	RemoteBookmarkDeleted
+)
+
+func (k RemoteBookmarkChangeKind) String() string {
+	switch k {
+	case RemoteBookmarkCreated:
+		return "created"
+	case RemoteBookmarkMoved:
+		return "moved"
+	case RemoteBookmarkDeleted:
+		return "deleted"
+	}
+	return ""
+}
+
+type RemoteBookmarkChange struct {
+	Name   string
+	Remote string
+	Kind   RemoteBookmarkChangeKind
+}
+
+func (c RemoteBookmarkChange) String() string {
+	return fmt.Sprintf("%s@%s %s", c.Name, c.Remote, c.Kind)
+}
+
+// DiffRemoteBookmarks compares two snapshots of `jj bookmark list -a` and
+// reports the remote bookmarks that were created, moved or deleted in between.
+func DiffRemoteBookmarks(before []Bookmark, after []Bookmark) []RemoteBookmarkChange {
+	targets := func(bookmarks []Bookmark) map[[2]string]string {
+		m := make(map[[2]string]string)
+		for _, b := range bookmarks {
+			for _, remote := range b.Remotes {
+				m[[2]string{b.Name, remote.Remote}] = remote.CommitId
+			}
+		}
+		return m
+	}
+	old, current := targets(before), targets(after)
+
+	var changes []RemoteBookmarkChange
+	for _, b := range after {
+		for _, remote := range b.Remotes {
+			key := [2]string{b.Name, remote.Remote}
+			previous, existed := old[key]
+			switch {
+			case !existed:
+				changes = append(changes, RemoteBookmarkChange{Name: b.Name, Remote: remote.Remote, Kind: RemoteBookmarkCreated})
+			case previous != remote.CommitId:
+				changes = append(changes, RemoteBookmarkChange{Name: b.Name, Remote: remote.Remote, Kind: RemoteBookmarkMoved})
+			}
+		}
+	}
+	for _, b := range before {
+		for _, remote := range b.Remotes {
+			if _, ok := current[[2]string{b.Name, remote.Remote}]; !ok {
+				changes = append(changes, RemoteBookmarkChange{Name: b.Name, Remote: remote.Remote, Kind: RemoteBookmarkDeleted})
+			}
+		}
+	}
+	return changes
+}
+
+// SummarizeRemoteBookmarkChanges renders changes as a single comma separated line.
+func SummarizeRemoteBookmarkChanges(changes []RemoteBookmarkChange) string {
+	parts := make([]string, 0, len(changes))
+	for _, c := range changes {
+		parts = append(parts, c.String())
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,31 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffRemoteBookmarks(t *testing.T) {
+	before := ParseBookmarkListOutput(`main;.;false;false;false;1a
+main;origin;true;false;false;1a
+feature;origin;true;false;false;2b
+stable;origin;false;false;false;3c`)
+	after := ParseBookmarkListOutput(`main;.;false;false;false;1a
+main;origin;true;false;false;4d
+stable;origin;false;false;false;3c
+release;origin;false;false;false;5e`)
+
+	changes := DiffRemoteBookmarks(before, after)
+	assert.Equal(t, []RemoteBookmarkChange{
+		{Name: "main", Remote: "origin", Kind: RemoteBookmarkMoved},
+		{Name: "release", Remote: "origin", Kind: RemoteBookmarkCreated},
+		{Name: "feature", Remote: "origin", Kind: RemoteBookmarkDeleted},
+	}, changes)
+	assert.Equal(t, "main@origin moved, release@origin created, feature@origin deleted", SummarizeRemoteBookmarkChanges(changes))
+}
+
+func TestDiffRemoteBookmarks_NoChanges(t *testing.T) {
+	bookmarks := ParseBookmarkListOutput("main;origin;true;false;false;1a")
+	assert.Empty(t, DiffRemoteBookmarks(bookmarks, bookmarks))
+}
@@ -21,3 +21,36 @@ func ParseRemoteListOutput(output string) []string {
 	}
 	return remotes
 }
+
+type Remote struct {
+	Name             string
+	URL              string
+	TrackedBookmarks int
+}
+
+// ParseRemotes parses `jj git remote list` output into remotes with their
+// URLs, counting the bookmarks each remote has tracked locally.
+func ParseRemotes(output string, bookmarks []Bookmark) []Remote {
+	tracked := make(map[string]int)
+	for _, b := range bookmarks {
+		for _, remote := range b.Remotes {
+			if remote.Tracked {
+				tracked[remote.Remote]++
+			}
+		}
+	}
+
+	var remotes []Remote
+	for line := range strings.SplitSeq(strings.TrimSpace(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		remote := Remote{Name: fields[0], TrackedBookmarks: tracked[fields[0]]}
+		if len(fields) > 1 {
+			remote.URL = fields[1]
+		}
+		remotes = append(remotes, remote)
+	}
+	return remotes
+}
@@ -46,3 +46,19 @@ func TestParseRemoteListOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRemotes(t *testing.T) {
+	bookmarks := ParseBookmarkListOutput(`main;.;false;false;false;1a
+main;origin;true;false;false;1a
+main;upstream;true;false;false;2b
+feature;.;false;false;false;3c
+feature;origin;true;false;false;3c
+stale;upstream;false;false;false;4d`)
+
+	output := "origin git@github.com:me/repo.git\nupstream https://github.com/them/repo.git\nempty\n"
+	assert.Equal(t, []Remote{
+		{Name: "origin", URL: "git@github.com:me/repo.git", TrackedBookmarks: 2},
+		{Name: "upstream", URL: "https://github.com/them/repo.git", TrackedBookmarks: 1},
+		{Name: "empty"},
+	}, ParseRemotes(output, bookmarks))
+}
@@ -0,0 +1,65 @@
+package jj
+
+import (
+	"strings"
+)
+
+// RevisionDetails describes a revision beyond the ids the log shows.
+type RevisionDetails struct {
+	// FullCommitId is the complete commit id, which the shorter ids shown in
+	// the log are prefixes of.
+	FullCommitId string
+	ChangeId     string
+	CommitId     string
+	Description  string
+	AuthorName   string
+	AuthorEmail  string
+	// Timestamp is when the author made the change, in RFC 3339 and UTC.
+	Timestamp   string
+	Bookmarks   []string
+	Parents     []string
+	Empty       bool
+	Conflict    bool
+	Immutable   bool
+	WorkingCopy bool
+}
+
+const revisionDetailsTemplate = `commit_id ++ "\t" ++ change_id.shortest(8) ++ "\t" ++ commit_id.shortest(8) ++ "\t" ++
+author.name() ++ "\t" ++ author.email() ++ "\t" ++ author.timestamp().utc().format("%Y-%m-%dT%H:%M:%SZ") ++ "\t" ++
+local_bookmarks.map(|b| b.name()).join(" ") ++ "\t" ++ parents.map(|c| c.change_id().shortest(8)).join(" ") ++ "\t" ++
+empty ++ "\t" ++ conflict ++ "\t" ++ immutable ++ "\t" ++ current_working_copy ++ "\t" ++ description ++ "\0"`
+
+// RevisionDetailsList lists the details of the revisions in the revset, to be
+// parsed with ParseRevisionDetails.
+func RevisionDetailsList(revset string) CommandArgs {
+	return []string{"log", "-r", revset, "--no-graph", "--color", "never", "--quiet", "--ignore-working-copy", "--template", revisionDetailsTemplate}
+}
+
+// ParseRevisionDetails parses the output of RevisionDetailsList.
+func ParseRevisionDetails(output string) []RevisionDetails {
+	var revisions []RevisionDetails
+	for record := range strings.SplitSeq(output, "\x00") {
+		record = strings.TrimLeft(record, "\n")
+		// the description is last since it may have tabs of its own
+		fields := strings.SplitN(record, "\t", 13)
+		if len(fields) < 13 {
+			continue
+		}
+		revisions = append(revisions, RevisionDetails{
+			FullCommitId: fields[0],
+			ChangeId:     fields[1],
+			CommitId:     fields[2],
+			AuthorName:   fields[3],
+			AuthorEmail:  fields[4],
+			Timestamp:    fields[5],
+			Bookmarks:    strings.Fields(fields[6]),
+			Parents:      strings.Fields(fields[7]),
+			Empty:        fields[8] == "true",
+			Conflict:     fields[9] == "true",
+			Immutable:    fields[10] == "true",
+			WorkingCopy:  fields[11] == "true",
+			Description:  strings.TrimRight(fields[12], "\n"),
+		})
+	}
+	return revisions
+}
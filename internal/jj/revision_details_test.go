@@ -0,0 +1,38 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRevisionDetails(t *testing.T) {
+	output := "abcdef0123\tkxyz\tabcd\tJane Doe\tjane@example.com\t2026-01-02T03:04:05Z\tmain feature\tmnop qrst\tfalse\ttrue\tfalse\ttrue\tfirst line\n\twith a tab\n\x00" +
+		"0000000000\tzzzz\t0000\t\t\t1970-01-01T00:00:00Z\t\t\ttrue\tfalse\ttrue\tfalse\t\x00"
+
+	assert.Equal(t, []RevisionDetails{
+		{
+			FullCommitId: "abcdef0123",
+			ChangeId:     "kxyz",
+			CommitId:     "abcd",
+			Description:  "first line\n\twith a tab",
+			AuthorName:   "Jane Doe",
+			AuthorEmail:  "jane@example.com",
+			Timestamp:    "2026-01-02T03:04:05Z",
+			Bookmarks:    []string{"main", "feature"},
+			Parents:      []string{"mnop", "qrst"},
+			Conflict:     true,
+			WorkingCopy:  true,
+		},
+		{
+			FullCommitId: "0000000000",
+			ChangeId:     "zzzz",
+			CommitId:     "0000",
+			Timestamp:    "1970-01-01T00:00:00Z",
+			Bookmarks:    []string{},
+			Parents:      []string{},
+			Empty:        true,
+			Immutable:    true,
+		},
+	}, ParseRevisionDetails(output))
+}
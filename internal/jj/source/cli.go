@@ -0,0 +1,287 @@
+package source
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/idursun/jjui/internal/jj"
+)
+
+// CommandSpec describes a jj command, its flags and its subcommands, as jj
+// tells the shells that complete it.
+type CommandSpec struct {
+	Name        string
+	Help        string
+	Flags       []FlagSpec
+	Subcommands []*CommandSpec
+	// Values are the values the positional arguments can take, when jj
+	// knows them.
+	Values []Item
+}
+
+// FlagSpec describes a flag of a command.
+type FlagSpec struct {
+	Short      string
+	Long       string
+	Help       string
+	TakesValue bool
+	// Values are the values the flag can take, when jj knows them.
+	Values []Item
+}
+
+// Name returns the flag the way it is typed, preferring the long form.
+func (f FlagSpec) Name() string {
+	if f.Long != "" {
+		return "--" + f.Long
+	}
+	return "-" + f.Short
+}
+
+// Matches reports whether arg, e.g. `-r` or `--revision`, names the flag.
+func (f FlagSpec) Matches(arg string) bool {
+	return (f.Long != "" && arg == "--"+f.Long) || (f.Short != "" && arg == "-"+f.Short)
+}
+
+// Subcommand returns the subcommand with the given name, if any.
+func (c *CommandSpec) Subcommand(name string) *CommandSpec {
+	for _, sub := range c.Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// Flag returns the flag that arg names, if any.
+func (c *CommandSpec) Flag(arg string) (FlagSpec, bool) {
+	for _, flag := range c.Flags {
+		if flag.Matches(arg) {
+			return flag, true
+		}
+	}
+	return FlagSpec{}, false
+}
+
+func (c *CommandSpec) ensure(path []string) *CommandSpec {
+	current := c
+	for _, name := range path {
+		sub := current.Subcommand(name)
+		if sub == nil {
+			sub = &CommandSpec{Name: name}
+			current.Subcommands = append(current.Subcommands, sub)
+		}
+		current = sub
+	}
+	return current
+}
+
+var commandSpecCache struct {
+	sync.Mutex
+	spec *CommandSpec
+}
+
+// LoadCommandSpec asks jj for its fish completion script and parses it. The
+// result is cached, since it only changes with jj itself.
+func LoadCommandSpec(runner Runner) (*CommandSpec, error) {
+	commandSpecCache.Lock()
+	defer commandSpecCache.Unlock()
+	if commandSpecCache.spec != nil {
+		return commandSpecCache.spec, nil
+	}
+	output, err := runner(jj.UtilCompletion("fish"))
+	if err != nil {
+		return nil, err
+	}
+	commandSpecCache.spec = ParseFishCompletion(string(output))
+	return commandSpecCache.spec, nil
+}
+
+// ParseFishCompletion builds the command tree from the `complete` lines of
+// the fish completion script jj generates.
+func ParseFishCompletion(script string) *CommandSpec {
+	root := &CommandSpec{Name: "jj"}
+	for _, words := range shellLines(script) {
+		if len(words) < 3 || words[0] != "complete" {
+			continue
+		}
+		var (
+			condition, help, candidates string
+			flag                        FlagSpec
+			hasCandidates               bool
+		)
+		for i := 1; i < len(words); i++ {
+			word := words[i]
+			value := ""
+			if i+1 < len(words) {
+				value = words[i+1]
+			}
+			switch word {
+			case "-n", "--condition":
+				condition = value
+				i++
+			case "-s", "--short-option":
+				flag.Short = value
+				i++
+			case "-l", "--long-option", "-o", "--old-option":
+				flag.Long = value
+				i++
+			case "-d", "--description":
+				help = value
+				i++
+			case "-a", "--arguments":
+				candidates, hasCandidates = value, true
+				i++
+			case "-r", "--require-parameter", "-x", "--exclusive":
+				flag.TakesValue = true
+			case "-c", "--command", "-w", "--wraps":
+				i++
+			}
+		}
+
+		path := commandPath(condition)
+		command := root.ensure(path)
+		switch {
+		case flag.Short != "" || flag.Long != "":
+			flag.Help = help
+			flag.Values = parseCandidates(candidates)
+			if len(flag.Values) > 0 {
+				flag.TakesValue = true
+			}
+			if _, exists := command.Flag(flag.Name()); !exists {
+				command.Flags = append(command.Flags, flag)
+			}
+		case hasCandidates:
+			values := parseCandidates(candidates)
+			// a single candidate with a description is a subcommand
+			if len(values) == 1 && help != "" {
+				sub := command.ensure([]string{values[0].Name})
+				sub.Help = help
+				continue
+			}
+			command.Values = append(command.Values, values...)
+		}
+	}
+	return root
+}
+
+// commandPath works out which command a completion belongs to from its
+// condition, e.g. `__fish_jj_using_subcommand git; and __fish_seen_subcommand_from push`.
+// Negated clauses list what may follow a command, so they don't lead further.
+func commandPath(condition string) []string {
+	var path []string
+	for _, clause := range strings.Split(condition, ";") {
+		fields := strings.Fields(clause)
+		if len(fields) > 0 && fields[0] == "and" {
+			fields = fields[1:]
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "__fish_jj_using_subcommand", "__fish_seen_subcommand_from":
+			path = append(path, fields[1])
+		}
+	}
+	return path
+}
+
+// parseCandidates parses the argument of `-a`, which lists one candidate per
+// line with an optional description after a tab, or several on one line.
+func parseCandidates(candidates string) []Item {
+	// fish keeps `\t` as is in double quotes and reads it as a tab here
+	candidates = strings.TrimSpace(strings.ReplaceAll(candidates, `\t`, "\t"))
+	if candidates == "" || strings.HasPrefix(candidates, "(") {
+		// a command substitution, completed by fish itself
+		return nil
+	}
+	var lines []string
+	if strings.Contains(candidates, "\n") || strings.Contains(candidates, "\t") {
+		lines = strings.Split(candidates, "\n")
+	} else {
+		lines = strings.Fields(candidates)
+	}
+	var items []Item
+	for _, line := range lines {
+		name, help, _ := strings.Cut(strings.TrimSpace(line), "\t")
+		if name == "" {
+			continue
+		}
+		help = strings.Trim(help, "'")
+		items = append(items, Item{Name: name, Kind: KindValue, SignatureHelp: help})
+	}
+	return items
+}
+
+// shellLines splits a script into the words of each of its commands,
+// following the quoting rules of fish closely enough for the completion
+// script, where a quoted word may span lines.
+func shellLines(script string) [][]string {
+	var (
+		lines   [][]string
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+		comment bool
+	)
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endLine := func() {
+		endWord()
+		if len(words) > 0 {
+			lines = append(lines, words)
+			words = nil
+		}
+	}
+	for _, r := range script {
+		switch {
+		case comment:
+			if r == '\n' {
+				comment = false
+				endLine()
+			}
+		case escaped:
+			escaped = false
+			if quote == 0 && r == '\n' {
+				// a line continuation
+				continue
+			}
+			if quote == '"' && r != '"' && r != '\\' && r != '$' {
+				word.WriteRune('\\')
+			}
+			if quote == '\'' && r != '\'' && r != '\\' {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			inWord = true
+		case r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '#' && !inWord:
+			comment = true
+		case r == '\n':
+			endLine()
+		case r == ' ' || r == '\t':
+			endWord()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	endLine()
+	return lines
+}
@@ -0,0 +1,78 @@
+package source
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fishCompletion = `# Print an optspec for argparse to handle cmd's options that are independent of any subcommand.
+function __fish_jj_global_optspecs
+	string join \n R/repository= at-op= color= h/help
+end
+
+complete -c jj -n "__fish_jj_needs_command" -s R -l repository -d 'Path to repository to operate on' -r -f -a "(__fish_complete_directories)"
+complete -c jj -n "__fish_jj_needs_command" -l color -d 'When to colorize output' -r -f -a "always\t''
+never\t''
+auto\t''"
+complete -c jj -n "__fish_jj_needs_command" -s h -l help -d 'Print help'
+complete -c jj -n "__fish_jj_needs_command" -f -a "bookmark" -d 'Manage bookmarks'
+complete -c jj -n "__fish_jj_needs_command" -f -a "log" -d 'Show revision history'
+complete -c jj -n "__fish_jj_using_subcommand log" -s r -l revisions -d 'Which revisions to show' -r
+complete -c jj -n "__fish_jj_using_subcommand log" -l reversed -d 'Show revisions in the opposite order'
+complete -c jj -n "__fish_jj_using_subcommand bookmark; and not __fish_seen_subcommand_from create delete" -f -a "create" -d 'Create a new bookmark'
+complete -c jj -n "__fish_jj_using_subcommand bookmark; and not __fish_seen_subcommand_from create delete" -f -a "delete" -d 'Delete an existing bookmark'
+complete -c jj -n "__fish_jj_using_subcommand bookmark; and __fish_seen_subcommand_from create" -s r -l revision -d 'The bookmark\'s target revision' -r
+complete -c jj -n "__fish_jj_using_subcommand bookmark; and __fish_seen_subcommand_from delete" -l dry-run -d 'Don\'t delete'
+`
+
+func TestParseFishCompletion(t *testing.T) {
+	spec := ParseFishCompletion(fishCompletion)
+
+	color, ok := spec.Flag("--color")
+	require.True(t, ok)
+	assert.True(t, color.TakesValue)
+	assert.Equal(t, []string{"always", "never", "auto"}, itemNames(color.Values))
+
+	repository, ok := spec.Flag("-R")
+	require.True(t, ok)
+	assert.Equal(t, "--repository", repository.Name())
+	assert.Empty(t, repository.Values)
+
+	log := spec.Subcommand("log")
+	require.NotNil(t, log)
+	assert.Equal(t, "Show revision history", log.Help)
+	revisions, ok := log.Flag("-r")
+	require.True(t, ok)
+	assert.True(t, revisions.TakesValue)
+	reversed, ok := log.Flag("--reversed")
+	require.True(t, ok)
+	assert.False(t, reversed.TakesValue)
+
+	bookmark := spec.Subcommand("bookmark")
+	require.NotNil(t, bookmark)
+	assert.Equal(t, []string{"create", "delete"}, []string{bookmark.Subcommands[0].Name, bookmark.Subcommands[1].Name})
+	create := bookmark.Subcommand("create")
+	revision, ok := create.Flag("--revision")
+	require.True(t, ok)
+	assert.Equal(t, "The bookmark's target revision", revision.Help)
+	_, ok = bookmark.Subcommand("delete").Flag("--dry-run")
+	assert.True(t, ok)
+}
+
+func TestParseDescribedList(t *testing.T) {
+	items := parseDescribedList("abc\tfirst change\nxyz\t\n\n", KindRevision)
+	assert.Equal(t, []Item{
+		{Name: "abc", Kind: KindRevision, SignatureHelp: "first change"},
+		{Name: "xyz", Kind: KindRevision},
+	}, items)
+}
+
+func itemNames(items []Item) []string {
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	return names
+}
@@ -16,6 +16,13 @@ const (
 	KindHistory
 	KindBookmark
 	KindTag
+	KindCommand
+	KindFlag
+	KindValue
+	KindRevision
+	KindRemote
+	KindFile
+	KindOperation
 )
 
 // Item represents a completion/picker item from any source.
@@ -0,0 +1,81 @@
+package source
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/idursun/jjui/internal/jj"
+)
+
+// valueLimit is how many revisions and operations are offered as values.
+const valueLimit = 100
+
+// RevisionSource loads the change ids of the revisions in the default log
+// revset.
+type RevisionSource struct{}
+
+func (s RevisionSource) Fetch(runner Runner) ([]Item, error) {
+	output, err := runner(jj.ChangeIdList(valueLimit))
+	if err != nil {
+		return nil, err
+	}
+	return parseDescribedList(string(output), KindRevision), nil
+}
+
+// OperationSource loads the ids of the latest operations.
+type OperationSource struct{}
+
+func (s OperationSource) Fetch(runner Runner) ([]Item, error) {
+	output, err := runner(jj.OpIdList(valueLimit))
+	if err != nil {
+		return nil, err
+	}
+	return parseDescribedList(string(output), KindOperation), nil
+}
+
+// RemoteSource loads the git remotes.
+type RemoteSource struct{}
+
+func (s RemoteSource) Fetch(runner Runner) ([]Item, error) {
+	output, err := runner(jj.GitRemoteList())
+	if err != nil {
+		return nil, err
+	}
+	var items []Item
+	for _, name := range jj.ParseRemoteListOutput(string(output)) {
+		items = append(items, Item{Name: name, Kind: KindRemote})
+	}
+	return items, nil
+}
+
+// FileSource loads the files of the working copy.
+type FileSource struct{}
+
+func (s FileSource) Fetch(runner Runner) ([]Item, error) {
+	output, err := runner(jj.FileList())
+	if err != nil {
+		return nil, err
+	}
+	var items []Item
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		if path := strings.TrimSpace(scanner.Text()); path != "" {
+			items = append(items, Item{Name: path, Kind: KindFile})
+		}
+	}
+	return items, nil
+}
+
+// parseDescribedList parses lines of an id and a description separated by a
+// tab.
+func parseDescribedList(output string, kind Kind) []Item {
+	var items []Item
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		id, description, _ := strings.Cut(scanner.Text(), "\t")
+		if id = strings.TrimSpace(id); id != "" {
+			items = append(items, Item{Name: id, Kind: kind, SignatureHelp: strings.TrimSpace(description)})
+		}
+	}
+	return items
+}
@@ -0,0 +1,93 @@
+package jj
+
+import (
+	"strings"
+)
+
+const staleBookmarkTemplate = `separate(";", name, if(remote, remote, "."), tracked, present, if(normal_target, normal_target.contained_in("::trunk()"), false), if(normal_target, normal_target.contained_in("trunk()"), false), if(normal_target, normal_target.hidden(), false)) ++ "\n"`
+
+type StaleReason int
+
+const (
+	StaleMerged StaleReason = iota
+	StaleHidden
+	StaleRemoteDeleted
+)
+
+func (r StaleReason) String() string {
+	switch r {
+	case StaleMerged:
+		return "merged into trunk()"
+	case StaleHidden:
+		return "points at an abandoned revision"
+	case StaleRemoteDeleted:
+		return "deleted on remote"
+	}
+	return ""
+}
+
+// StaleBookmark is a local bookmark (Remote is empty) or a remote bookmark that is a cleanup candidate.
+type StaleBookmark struct {
+	Name     string
+	Remote   string
+	HasLocal bool
+	Reason   StaleReason
+}
+
+func (s StaleBookmark) String() string {
+	if s.Remote == "" {
+		return s.Name
+	}
+	return s.Name + "@" + s.Remote
+}
+
+func ParseStaleBookmarkListOutput(output string) []StaleBookmark {
+	type line struct {
+		name, remote                       string
+		tracked, present                   bool
+		inTrunkAncestry, atTrunk, isHidden bool
+	}
+	var lines []line
+	locals := make(map[string]bool)
+	for l := range strings.Lines(output) {
+		parts := strings.Split(strings.TrimSpace(l), ";")
+		if len(parts) < 7 || parts[1] == "git" {
+			continue
+		}
+		parsed := line{
+			name:            strings.Trim(parts[0], "\""),
+			remote:          parts[1],
+			tracked:         parts[2] == "true",
+			present:         parts[3] == "true",
+			inTrunkAncestry: parts[4] == "true",
+			atTrunk:         parts[5] == "true",
+			isHidden:        parts[6] == "true",
+		}
+		if parsed.remote == "." {
+			locals[parsed.name] = true
+		}
+		lines = append(lines, parsed)
+	}
+
+	var stale []StaleBookmark
+	for _, l := range lines {
+		candidate := StaleBookmark{Name: l.name, HasLocal: locals[l.name]}
+		if l.remote != "." {
+			candidate.Remote = l.remote
+		}
+		switch {
+		// bookmarks at trunk() are left alone: the trunk bookmark itself is one of
+		// them, whatever trunk() resolves to in this repository
+		case l.remote == "." && l.inTrunkAncestry && !l.atTrunk:
+			candidate.Reason = StaleMerged
+		case l.isHidden:
+			candidate.Reason = StaleHidden
+		case l.remote != "." && l.tracked && !l.present && candidate.HasLocal:
+			candidate.Reason = StaleRemoteDeleted
+		default:
+			continue
+		}
+		stale = append(stale, candidate)
+	}
+	return stale
+}
@@ -0,0 +1,36 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStaleBookmarkListOutput(t *testing.T) {
+	output := `main;.;true;true;true;true;false
+main;git;false;true;true;true;false
+main;origin;true;true;true;true;false
+merged-feature;.;true;true;true;false;false
+merged-feature;origin;true;true;true;false;false
+develop;.;true;true;true;true;false
+develop;origin;true;true;true;true;false
+trunk;.;false;true;true;false;false
+abandoned;origin;false;true;false;false;true
+gone;.;true;true;false;false;false
+gone;origin;true;false;false;false;false
+active;.;true;true;false;false;false
+active;origin;true;true;false;false;false`
+
+	assert.Equal(t, []StaleBookmark{
+		{Name: "merged-feature", HasLocal: true, Reason: StaleMerged},
+		{Name: "trunk", HasLocal: true, Reason: StaleMerged},
+		{Name: "abandoned", Remote: "origin", Reason: StaleHidden},
+		{Name: "gone", Remote: "origin", HasLocal: true, Reason: StaleRemoteDeleted},
+	}, ParseStaleBookmarkListOutput(output))
+}
+
+func TestStaleBookmark_String(t *testing.T) {
+	assert.Equal(t, "feature", StaleBookmark{Name: "feature"}.String())
+	assert.Equal(t, "feature@origin", StaleBookmark{Name: "feature", Remote: "origin"}.String())
+	assert.Equal(t, "deleted on remote", StaleRemoteDeleted.String())
+}
@@ -0,0 +1,113 @@
+package jj
+
+import (
+	"slices"
+	"strings"
+)
+
+// GlobalFlagsWithValue are the global flags that take their value as the next
+// argument, which mustn't be mistaken for the subcommand.
+var GlobalFlagsWithValue = map[string]bool{
+	"-R":             true,
+	"--repository":   true,
+	"--at-op":        true,
+	"--at-operation": true,
+	"--color":        true,
+	"--config":       true,
+	"--config-file":  true,
+}
+
+// NeedsTerminal reports whether the jj command given by args may need a
+// terminal, because it opens an editor, a diff editor or a merge tool, or
+// talks to a remote that may ask for credentials. Commands that don't can
+// have their output captured instead.
+func NeedsTerminal(args []string) bool {
+	var subcommand []string
+	hasMessage, listing := false, false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-i" || arg == "--interactive" || arg == "--tool" || strings.HasPrefix(arg, "--tool=") || arg == "--edit":
+			return true
+		case strings.HasPrefix(arg, "-m") || arg == "--message" || strings.HasPrefix(arg, "--message=") || arg == "--stdin" || arg == "--no-edit",
+			arg == "-u" || arg == "--use-destination-message":
+			hasMessage = true
+		case arg == "-l" || arg == "--list":
+			listing = true
+		case GlobalFlagsWithValue[arg]:
+			i++
+		case strings.HasPrefix(arg, "-"):
+		case len(subcommand) < 2:
+			subcommand = append(subcommand, arg)
+		}
+	}
+	if len(subcommand) == 0 {
+		return false
+	}
+	switch subcommand[0] {
+	case "describe", "desc", "commit", "ci", "squash":
+		return !hasMessage
+	case "split", "diffedit":
+		return true
+	case "resolve":
+		return !listing
+	case "config", "sparse":
+		return len(subcommand) > 1 && subcommand[1] == "edit"
+	case "git":
+		return len(subcommand) > 1 && (subcommand[1] == "push" || subcommand[1] == "fetch" || subcommand[1] == "clone")
+	}
+	return false
+}
+
+// readOnlyCommands are the jj commands that only show the repository, keyed
+// by the subcommand with the listing subcommands it has, if any.
+var readOnlyCommands = map[string][]string{
+	"log":       nil,
+	"show":      nil,
+	"diff":      nil,
+	"status":    nil,
+	"st":        nil,
+	"evolog":    nil,
+	"obslog":    nil,
+	"interdiff": nil,
+	"root":      nil,
+	"version":   nil,
+	"help":      nil,
+	"operation": {"log", "show", "diff"},
+	"op":        {"log", "show", "diff"},
+	"file":      {"show", "list", "annotate", "search"},
+	"bookmark":  {"list", "l"},
+	"b":         {"list", "l"},
+	"tag":       {"list", "l"},
+	"workspace": {"list", "root"},
+	"config":    {"list", "l", "get", "path"},
+	"sparse":    {"list"},
+}
+
+// IsReadOnly reports whether the jj command given by args only shows the
+// repository, so it can run without a terminal and without waiting for the
+// commands that change it.
+func IsReadOnly(args []string) bool {
+	var subcommand []string
+	for i := 0; i < len(args) && len(subcommand) < 2; i++ {
+		arg := args[i]
+		switch {
+		case GlobalFlagsWithValue[arg]:
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			subcommand = append(subcommand, arg)
+		}
+	}
+	if len(subcommand) == 0 {
+		return false
+	}
+	listing, ok := readOnlyCommands[subcommand[0]]
+	if !ok {
+		return false
+	}
+	if listing == nil {
+		return true
+	}
+	return len(subcommand) > 1 && slices.Contains(listing, subcommand[1])
+}
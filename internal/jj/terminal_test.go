@@ -0,0 +1,74 @@
+package jj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNeedsTerminal(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"log -r ::@", false},
+		{"show", false},
+		{"config list", false},
+		{"new -m wip", false},
+		{"git push --dry-run", true},
+		{"git fetch", true},
+		{"--color always git clone https://example.com/repo", true},
+		{"git remote list", false},
+		{"--color always log", false},
+		{"-R ../other describe", true},
+		{"describe", true},
+		{"describe -m message", false},
+		{"describe -mmessage", false},
+		{"describe --message=message", false},
+		{"commit", true},
+		{"squash", true},
+		{"squash -u", false},
+		{"squash -i", true},
+		{"restore --interactive", true},
+		{"split -r @ file.txt", true},
+		{"diffedit", true},
+		{"resolve", true},
+		{"resolve --list", false},
+		{"config edit --user", true},
+		{"config get ui.editor", false},
+		{"new --edit", true},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			assert.Equal(t, tt.want, NeedsTerminal(strings.Fields(tt.line)))
+		})
+	}
+}
+
+func TestIsReadOnly(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"log -r ::@", true},
+		{"--color always show", true},
+		{"-R ../other diff", true},
+		{"op log", true},
+		{"file show -r @ README.md", true},
+		{"bookmark list --all-remotes", true},
+		{"bookmark set main", false},
+		{"op restore", false},
+		{"config edit --user", false},
+		{"new -m wip", false},
+		{"git push", false},
+		{"file", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsReadOnly(strings.Fields(tt.line)))
+		})
+	}
+}
@@ -28,6 +28,8 @@ func InitVM(ctx *uicontext.MainContext) error {
 	}
 	registerAPI(L, ctx)
 	ensureActionRegistry(L)
+	ensureHookRegistry(L)
+	installCommandHooks(ctx)
 	L.SetGlobal(actionCounterName, lua.LNumber(0))
 	ctx.ScriptVM = L
 	return nil
@@ -37,6 +39,9 @@ func CloseVM(ctx *uicontext.MainContext) {
 	if ctx == nil {
 		return
 	}
+	if s := schedulerOf(ctx); s != nil {
+		s.stop()
+	}
 	if ctx.ScriptVM != nil {
 		ctx.ScriptVM.Close()
 		ctx.ScriptVM = nil
@@ -53,6 +58,41 @@ func RunSetup(ctx *uicontext.MainContext, current *config.Config, source string)
 		return err
 	}
 
+	configTable := newConfigTable(L, ctx, current)
+	chunk, err := L.Load(strings.NewReader(source), "config.lua")
+	if err != nil {
+		return fmt.Errorf("config.lua: %w", err)
+	}
+	if err := callWithLimits(L, lua.P{Fn: chunk, NRet: 0, Protect: true}); err != nil {
+		return fmt.Errorf("config.lua: %w", err)
+	}
+
+	setupFn := L.GetGlobal("setup")
+	if setupFn == lua.LNil {
+		return nil
+	}
+	fn, ok := setupFn.(*lua.LFunction)
+	if !ok {
+		return fmt.Errorf("config.lua: setup is not a function")
+	}
+	if err := callWithLimits(L, lua.P{Fn: fn, NRet: 0, Protect: true}, configTable); err != nil {
+		return fmt.Errorf("config.lua: setup(): %w", err)
+	}
+
+	// convert lua table back to config object
+	if err = fromLuaTable(configTable, current); err != nil {
+		return fmt.Errorf("config.lua: setup(): %w", err)
+	}
+	if err = current.ValidateBindingsAndActions(); err != nil {
+		return fmt.Errorf("config.lua: setup(): %w", err)
+	}
+
+	return nil
+}
+
+// newConfigTable is the config table setup functions are called with, which
+// is current as a table along with the functions to add actions and bindings.
+func newConfigTable(L *lua.LState, ctx *uicontext.MainContext, current *config.Config) *lua.LTable {
 	registry := ensureActionRegistry(L)
 
 	configTable := toLuaTable(L, current)
@@ -180,32 +220,7 @@ func RunSetup(ctx *uicontext.MainContext, current *config.Config, source string)
 		bindingsTable.Append(toLuaTable(L, binding))
 		return 0
 	}))
-
-	if err := L.DoString(source); err != nil {
-		return fmt.Errorf("config.lua: %w", err)
-	}
-
-	setupFn := L.GetGlobal("setup")
-	if setupFn == lua.LNil {
-		return nil
-	}
-	fn, ok := setupFn.(*lua.LFunction)
-	if !ok {
-		return fmt.Errorf("config.lua: setup is not a function")
-	}
-	if err := L.CallByParam(lua.P{Fn: fn, NRet: 0, Protect: true}, configTable); err != nil {
-		return fmt.Errorf("config.lua: setup(): %w", err)
-	}
-
-	// convert lua table back to config object
-	if err = fromLuaTable(configTable, current); err != nil {
-		return fmt.Errorf("config.lua: setup(): %w", err)
-	}
-	if err = current.ValidateBindingsAndActions(); err != nil {
-		return fmt.Errorf("config.lua: setup(): %w", err)
-	}
-
-	return nil
+	return configTable
 }
 
 func vmFromContext(ctx *uicontext.MainContext) (*lua.LState, error) {
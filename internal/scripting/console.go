@@ -0,0 +1,250 @@
+package scripting
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	uicontext "github.com/idursun/jjui/internal/ui/context"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const (
+	consoleName = "__jjui_console"
+	// consoleChunkName is what errors of the console report the line of
+	// the input with.
+	consoleChunkName = "console"
+	consolePackName  = "__jjui_console_pack"
+	// maxInspectDepth is how deep tables are shown before they are cut
+	// short.
+	maxInspectDepth = 5
+)
+
+// ErrIncompleteInput is returned by Evaluate for input that is only the
+// start of a chunk, such as the first line of a function.
+var ErrIncompleteInput = errors.New("incomplete input")
+
+// ConsoleResultMsg is what the console input evaluated to: what it printed,
+// followed by the values it returned, or the error it failed with.
+type ConsoleResultMsg struct {
+	Output []string
+	Err    error
+}
+
+// console is the state of the console in a VM. Globals the input sets are
+// set in the VM, but print writes to the console instead of the terminal.
+type console struct {
+	env    *lua.LTable
+	output []string
+}
+
+func consoleOf(L *lua.LState) *console {
+	if ud, ok := L.GetGlobal(consoleName).(*lua.LUserData); ok {
+		if c, ok := ud.Value.(*console); ok {
+			return c
+		}
+	}
+	c := &console{}
+	globals := L.Get(lua.GlobalsIndex)
+	c.env = L.NewTable()
+	c.env.RawSetString("print", L.NewFunction(func(L *lua.LState) int {
+		parts := make([]string, 0, L.GetTop())
+		for i := 1; i <= L.GetTop(); i++ {
+			parts = append(parts, L.ToStringMeta(L.Get(i)).String())
+		}
+		c.output = append(c.output, strings.Split(strings.Join(parts, "\t"), "\n")...)
+		return 0
+	}))
+	c.env.RawSetString(consolePackName, L.NewFunction(func(L *lua.LState) int {
+		n := L.GetTop()
+		L.Insert(lua.LNumber(n), 1)
+		return n + 1
+	}))
+	mt := L.NewTable()
+	mt.RawSetString("__index", globals)
+	mt.RawSetString("__newindex", globals)
+	L.SetMetatable(c.env, mt)
+
+	ud := L.NewUserData()
+	ud.Value = c
+	L.SetGlobal(consoleName, ud)
+	return c
+}
+
+// takeOutput returns what was printed since it was last taken.
+func (c *console) takeOutput() []string {
+	output := c.output
+	c.output = nil
+	return output
+}
+
+// Evaluate runs the console input in the VM. Input that is an expression
+// returns its value, so that typing a name shows what it holds. The runner
+// is returned if the input waits for a message, and must be given messages
+// until it is done; the result is sent as a ConsoleResultMsg.
+func Evaluate(ctx *uicontext.MainContext, src string) (*Runner, tea.Cmd, error) {
+	L, err := vmFromContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	// an expression is returned along with how many values it has, as
+	// nothing and nil look the same once returned
+	expression := true
+	fn, err := L.Load(strings.NewReader("return "+consolePackName+"("+src+"\n)"), consoleChunkName)
+	if err != nil {
+		expression = false
+		fn, err = L.Load(strings.NewReader(src), consoleChunkName)
+	}
+	if err != nil {
+		if isIncomplete(err) {
+			return nil, nil, ErrIncompleteInput
+		}
+		return nil, nil, err
+	}
+	c := consoleOf(L)
+	L.SetFEnv(fn, c.env)
+
+	r := newRunner(ctx, L, "", fn, func(values []lua.LValue, err error) tea.Cmd {
+		output := c.takeOutput()
+		if err == nil {
+			for _, value := range consoleValues(values, expression) {
+				output = append(output, strings.Split(Inspect(value), "\n")...)
+			}
+		}
+		return func() tea.Msg {
+			return ConsoleResultMsg{Output: output, Err: err}
+		}
+	})
+	r.quiet = true
+	cmd := r.start()
+	if r.Done() {
+		return nil, cmd, nil
+	}
+	return r, cmd, nil
+}
+
+// consoleValues are the values to show of what the input returned.
+func consoleValues(values []lua.LValue, expression bool) []lua.LValue {
+	if expression {
+		if len(values) == 0 {
+			return nil
+		}
+		n := int(lua.LVAsNumber(values[0]))
+		return values[1:min(len(values), n+1)]
+	}
+	// statements that return nothing still give back a nil
+	if len(values) == 1 && values[0] == lua.LNil {
+		return nil
+	}
+	return values
+}
+
+// isIncomplete tells whether the chunk failed to compile only because more
+// of it is to come.
+func isIncomplete(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, " at EOF:") && !strings.HasSuffix(msg, "unterminated string")
+}
+
+// Inspect formats the value the way it would be written in Lua, with the
+// keys of tables sorted and nested tables indented.
+func Inspect(value lua.LValue) string {
+	var b strings.Builder
+	inspect(&b, value, 0, map[*lua.LTable]bool{})
+	return b.String()
+}
+
+func inspect(b *strings.Builder, value lua.LValue, depth int, seen map[*lua.LTable]bool) {
+	switch value := value.(type) {
+	case lua.LString:
+		fmt.Fprintf(b, "%q", string(value))
+	case *lua.LTable:
+		inspectTable(b, value, depth, seen)
+	default:
+		b.WriteString(value.String())
+	}
+}
+
+func inspectTable(b *strings.Builder, tbl *lua.LTable, depth int, seen map[*lua.LTable]bool) {
+	if seen[tbl] {
+		b.WriteString("<cycle>")
+		return
+	}
+	if depth >= maxInspectDepth {
+		b.WriteString("{...}")
+		return
+	}
+	// the border # gives can be past a hole, which would show as nils
+	length := 0
+	for tbl.RawGetInt(length+1) != lua.LNil {
+		length++
+	}
+	var keys []lua.LValue
+	tbl.ForEach(func(key, _ lua.LValue) {
+		if n, ok := key.(lua.LNumber); ok && float64(n) == float64(int(n)) && int(n) >= 1 && int(n) <= length {
+			return
+		}
+		keys = append(keys, key)
+	})
+	if length == 0 && len(keys) == 0 {
+		b.WriteString("{}")
+		return
+	}
+	// numbers first, then everything else by how it reads
+	slices.SortFunc(keys, func(a, b lua.LValue) int {
+		an, aIsNumber := a.(lua.LNumber)
+		bn, bIsNumber := b.(lua.LNumber)
+		switch {
+		case aIsNumber && bIsNumber:
+			return cmp.Compare(an, bn)
+		case aIsNumber:
+			return -1
+		case bIsNumber:
+			return 1
+		}
+		return strings.Compare(a.String(), b.String())
+	})
+
+	seen[tbl] = true
+	defer delete(seen, tbl)
+	indent := strings.Repeat("  ", depth+1)
+	b.WriteString("{\n")
+	for i := 1; i <= length; i++ {
+		b.WriteString(indent)
+		inspect(b, tbl.RawGetInt(i), depth+1, seen)
+		b.WriteString(",\n")
+	}
+	for _, key := range keys {
+		b.WriteString(indent)
+		if s, ok := key.(lua.LString); ok && isIdentifier(string(s)) {
+			b.WriteString(string(s))
+		} else {
+			b.WriteString("[")
+			inspect(b, key, depth+1, seen)
+			b.WriteString("]")
+		}
+		b.WriteString(" = ")
+		inspect(b, tbl.RawGet(key), depth+1, seen)
+		b.WriteString(",\n")
+	}
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString("}")
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
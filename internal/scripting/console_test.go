@@ -0,0 +1,108 @@
+package scripting
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	uicontext "github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lua "github.com/yuin/gopher-lua"
+)
+
+func evaluate(t *testing.T, ctx *uicontext.MainContext, src string) ConsoleResultMsg {
+	t.Helper()
+	runner, cmd, err := Evaluate(ctx, src)
+	require.NoError(t, err)
+	require.Nil(t, runner)
+	var result *ConsoleResultMsg
+	test.SimulateModel(ignoreMsgs{}, cmd, func(msg tea.Msg) {
+		if msg, ok := msg.(ConsoleResultMsg); ok {
+			result = &msg
+		}
+	})
+	require.NotNil(t, result)
+	return *result
+}
+
+func TestEvaluate_ShowsValueOfExpressions(t *testing.T) {
+	ctx := setupVM(t)
+
+	assert.Equal(t, []string{"3"}, evaluate(t, ctx, "1 + 2").Output)
+	assert.Equal(t, []string{"1", `"two"`, "nil"}, evaluate(t, ctx, `1, "two", nil`).Output)
+	assert.Equal(t, []string{"nil"}, evaluate(t, ctx, "undefined_name").Output)
+	assert.Empty(t, evaluate(t, ctx, "x = 1").Output)
+	assert.Empty(t, evaluate(t, ctx, "(function() end)()").Output)
+}
+
+func TestEvaluate_GlobalsAreSetInTheVM(t *testing.T) {
+	ctx := setupVM(t)
+
+	evaluate(t, ctx, "function double(n) return n * 2 end")
+	assert.Equal(t, []string{"42"}, evaluate(t, ctx, "double(21)").Output)
+	assert.Equal(t, lua.LTFunction, ctx.ScriptVM.GetGlobal("double").Type())
+	assert.Equal(t, []string{"true"}, evaluate(t, ctx, "type(jjui) == 'table'").Output)
+}
+
+func TestEvaluate_CapturesPrint(t *testing.T) {
+	ctx := setupVM(t)
+
+	result := evaluate(t, ctx, "print('a', 1)\nprint('b\\nc')\nreturn 'done'")
+	assert.Equal(t, []string{"a\t1", "b", "c", `"done"`}, result.Output)
+	// what was printed is not shown again
+	assert.Equal(t, []string{"1"}, evaluate(t, ctx, "1").Output)
+}
+
+func TestEvaluate_ReportsIncompleteInput(t *testing.T) {
+	ctx := setupVM(t)
+
+	for _, src := range []string{"function f()", "if true then", "t = {", "x = 1 +"} {
+		_, _, err := Evaluate(ctx, src)
+		assert.ErrorIs(t, err, ErrIncompleteInput, src)
+	}
+	assert.Equal(t, []string{"3"}, evaluate(t, ctx, "function f()\n  return 3\nend\nreturn f()").Output)
+
+	_, _, err := Evaluate(ctx, "if then")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrIncompleteInput)
+}
+
+func TestEvaluate_ReportsRuntimeErrors(t *testing.T) {
+	ctx := setupVM(t)
+
+	result := evaluate(t, ctx, "print('before')\nerror('boom')")
+	assert.Equal(t, []string{"before"}, result.Output)
+	require.Error(t, result.Err)
+	assert.Contains(t, result.Err.Error(), "console:2: boom")
+}
+
+func TestInspect_FormatsTables(t *testing.T) {
+	ctx := setupVM(t)
+
+	result := evaluate(t, ctx, `{ "a", 2, name = "x", ["with space"] = true, [10] = {}, nested = { deep = { 1 } } }`)
+	assert.Equal(t, []string{
+		"{",
+		`  "a",`,
+		"  2,",
+		"  [10] = {},",
+		"  name = \"x\",",
+		"  nested = {",
+		"    deep = {",
+		"      1,",
+		"    },",
+		"  },",
+		`  ["with space"] = true,`,
+		"}",
+	}, result.Output)
+}
+
+func TestInspect_StopsAtCyclesAndDepth(t *testing.T) {
+	ctx := setupVM(t)
+
+	result := evaluate(t, ctx, "local t = {}\nt.self = t\nreturn t")
+	assert.Equal(t, []string{"{", "  self = <cycle>,", "}"}, result.Output)
+
+	require.NoError(t, ctx.ScriptVM.DoString("deep = {{{{{{}}}}}}"))
+	assert.Contains(t, Inspect(ctx.ScriptVM.GetGlobal("deep")), "{...}")
+}
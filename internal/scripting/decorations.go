@@ -0,0 +1,166 @@
+package scripting
+
+import (
+	"fmt"
+	"maps"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	uicontext "github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/intents"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const decoratorRegistryName = "__jjui_decorators"
+
+// DecorationsLoadedMsg carries the details of the loaded revisions, which
+// the decorators are called with in the main loop.
+type DecorationsLoadedMsg struct {
+	revisions []jj.RevisionDetails
+	all       bool
+	err       error
+}
+
+func registerDecorationAPI(L *lua.LState, revisionsTable *lua.LTable) {
+	revisionsTable.RawSetString("decorate", L.NewFunction(func(L *lua.LState) int {
+		fn := L.CheckFunction(1)
+		registry, ok := L.GetGlobal(decoratorRegistryName).(*lua.LTable)
+		if !ok {
+			registry = L.NewTable()
+			L.SetGlobal(decoratorRegistryName, registry)
+		}
+		registry.Append(fn)
+		return 0
+	}))
+}
+
+func decoratorsOf(ctx *uicontext.MainContext) *lua.LTable {
+	if ctx == nil || ctx.ScriptVM == nil {
+		return nil
+	}
+	registry, ok := ctx.ScriptVM.GetGlobal(decoratorRegistryName).(*lua.LTable)
+	if !ok || registry.Len() == 0 {
+		return nil
+	}
+	return registry
+}
+
+// LoadDecorations loads the details of the revisions in the log for the
+// decorators, away from the main loop. Unless all revisions are to be
+// decorated again, only the revisions without decorations are loaded. The
+// decorations of the previous load are shown until the new ones are applied.
+func LoadDecorations(ctx *uicontext.MainContext, all bool) tea.Cmd {
+	if decoratorsOf(ctx) == nil {
+		return nil
+	}
+	var commits []*jj.Commit
+	for _, commit := range ctx.LoadedRevisions {
+		if _, decorated := ctx.Decorations[commit.CommitId]; all || !decorated {
+			commits = append(commits, commit)
+		}
+	}
+	if len(commits) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		revisions, err := loadRevisions(ctx, commits)
+		return DecorationsLoadedMsg{revisions: revisions, all: all, err: err}
+	}
+}
+
+// ApplyDecorations calls the decorators for each loaded revision and keeps
+// their badges in the context by commit id, so that they are computed once
+// per commit until the next refresh. A decorator that fails leaves the rest
+// of the revisions undecorated.
+func ApplyDecorations(ctx *uicontext.MainContext, msg DecorationsLoadedMsg) tea.Cmd {
+	if msg.err != nil {
+		return intents.Invoke(intents.AddMessage{Text: msg.err.Error(), Err: msg.err})
+	}
+	decorators := decoratorsOf(ctx)
+	if decorators == nil {
+		ctx.Decorations = nil
+		return nil
+	}
+	L := ctx.ScriptVM
+	decorations := make(map[string][]common.Badge, len(ctx.LoadedRevisions))
+	if !msg.all {
+		maps.Copy(decorations, ctx.Decorations)
+	}
+	for _, details := range msg.revisions {
+		revision := revisionToLua(L, details)
+		var badges []common.Badge
+		for i := 1; i <= decorators.Len(); i++ {
+			fn, ok := decorators.RawGetInt(i).(*lua.LFunction)
+			if !ok {
+				continue
+			}
+			if err := callWithLimits(L, lua.P{Fn: fn, NRet: 1, Protect: true}, revision); err != nil {
+				ctx.Decorations = decorations
+				return intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err})
+			}
+			result := L.Get(-1)
+			L.Pop(1)
+			decorated, err := badgesFromLua(result)
+			if err != nil {
+				ctx.Decorations = decorations
+				return intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err})
+			}
+			badges = append(badges, decorated...)
+		}
+		// revisions without badges are kept too, to be skipped by the next load
+		decorations[details.CommitId] = badges
+	}
+	ctx.Decorations = decorations
+	return nil
+}
+
+// badgesFromLua reads what a decorator returned: nil, a badge, or a list of
+// badges. A badge is either its text or a table with the text and the fg, bg,
+// bold, italic, underline, strikethrough and reverse keys of a theme color.
+func badgesFromLua(value lua.LValue) ([]common.Badge, error) {
+	switch value := value.(type) {
+	case *lua.LNilType:
+		return nil, nil
+	case lua.LString:
+		return []common.Badge{common.NewBadge(value.String(), config.Color{})}, nil
+	case *lua.LTable:
+		if value.RawGetString("text") != lua.LNil {
+			badge, err := badgeFromTable(value)
+			if err != nil {
+				return nil, err
+			}
+			return []common.Badge{badge}, nil
+		}
+		var badges []common.Badge
+		for i := 1; i <= value.Len(); i++ {
+			switch item := value.RawGetInt(i).(type) {
+			case lua.LString:
+				badges = append(badges, common.NewBadge(item.String(), config.Color{}))
+			case *lua.LTable:
+				badge, err := badgeFromTable(item)
+				if err != nil {
+					return nil, err
+				}
+				badges = append(badges, badge)
+			default:
+				return nil, fmt.Errorf("decorate: badge %d: expected string or table, got %s", i, item.Type().String())
+			}
+		}
+		return badges, nil
+	}
+	return nil, fmt.Errorf("decorate: expected string or table, got %s", value.Type().String())
+}
+
+func badgeFromTable(tbl *lua.LTable) (common.Badge, error) {
+	text, ok := tbl.RawGetString("text").(lua.LString)
+	if !ok {
+		return common.Badge{}, fmt.Errorf("decorate: badge text must be a string")
+	}
+	var color config.Color
+	if err := fromLuaTable(tbl, &color); err != nil {
+		return common.Badge{}, fmt.Errorf("decorate: %w", err)
+	}
+	return common.NewBadge(text.String(), color), nil
+}
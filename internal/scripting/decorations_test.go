@@ -0,0 +1,67 @@
+package scripting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/test"
+)
+
+func TestDecorations_AreCachedUntilRefresh(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.RevisionDetailsList("aaaa|bbbb")).SetOutput([]byte(revisionDetails))
+	defer commandRunner.Verify()
+	ctx := setupRevisionsVM(t, commandRunner)
+	require.NoError(t, ctx.ScriptVM.DoString(`
+		calls = 0
+		jjui.revisions.decorate(function(rev)
+			calls = calls + 1
+			if rev.working_copy then
+				return { "wip", { text = "CI ✓", fg = "green", bold = true } }
+			end
+		end)
+		jjui.revisions.decorate(function(rev)
+			if rev.immutable then
+				return "stale"
+			end
+		end)
+	`))
+
+	msg := LoadDecorations(ctx, false)().(DecorationsLoadedMsg)
+	assert.Nil(t, ApplyDecorations(ctx, msg))
+	require.Len(t, ctx.Decorations["aaaa"], 2)
+	assert.Equal(t, "wip", ctx.Decorations["aaaa"][0].Text)
+	assert.Equal(t, "CI ✓", ctx.Decorations["aaaa"][1].Text)
+	assert.True(t, ctx.Decorations["aaaa"][1].Style.GetBold())
+	require.Len(t, ctx.Decorations["bbbb"], 1)
+	assert.Equal(t, "stale", ctx.Decorations["bbbb"][0].Text)
+
+	assert.Nil(t, LoadDecorations(ctx, false), "decorated revisions are not loaded again")
+
+	msg = LoadDecorations(ctx, true)().(DecorationsLoadedMsg)
+	ApplyDecorations(ctx, msg)
+	assert.Equal(t, "4", ctx.ScriptVM.GetGlobal("calls").String())
+}
+
+func TestDecorations_ReportInvalidBadges(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.RevisionDetailsList("aaaa|bbbb")).SetOutput([]byte(revisionDetails))
+	defer commandRunner.Verify()
+	ctx := setupRevisionsVM(t, commandRunner)
+	require.NoError(t, ctx.ScriptVM.DoString(`jjui.revisions.decorate(function(rev) return 42 end)`))
+
+	cmd := ApplyDecorations(ctx, LoadDecorations(ctx, false)().(DecorationsLoadedMsg))
+	require.NotNil(t, cmd)
+	flash, ok := cmd().(intents.AddMessage)
+	require.True(t, ok)
+	assert.Contains(t, flash.Text, "decorate: expected string or table, got number")
+}
+
+func TestDecorations_NotLoadedWithoutDecorators(t *testing.T) {
+	ctx := setupRevisionsVM(t, test.NewTestCommandRunner(t))
+	assert.Nil(t, LoadDecorations(ctx, true))
+}
@@ -0,0 +1,345 @@
+package scripting
+
+import (
+	stdcontext "context"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/idursun/jjui/internal/ui/common"
+	uicontext "github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/intents"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const (
+	hookRegistryName   = "__jjui_hooks"
+	hookDispatcherName = "__jjui_hook_dispatcher"
+)
+
+// The events scripts can hook into with jjui.on(event, fn).
+const (
+	HookSelectionChanged = "selection_changed"
+	HookRefreshCompleted = "refresh_completed"
+	HookRevsetChanged    = "revset_changed"
+	HookBeforeCommand    = "before_command"
+	HookAfterCommand     = "after_command"
+)
+
+var hookEvents = []string{
+	HookSelectionChanged,
+	HookRefreshCompleted,
+	HookRevsetChanged,
+	HookBeforeCommand,
+	HookAfterCommand,
+}
+
+// hookDispatcher calls the hooks of an event one after the other in a single
+// coroutine, so that hooks can wait for the user or for commands like any
+// other script. A before_command hook returns false, and optionally a reason,
+// to stop the command or a table of arguments to run instead.
+const hookDispatcher = `
+local hooks, event, payload = ...
+for _, hook in ipairs(hooks) do
+  local result, reason = hook(payload)
+  if event == "before_command" then
+    if result == false then
+      return false, reason
+    end
+    if type(result) == "table" then
+      payload.args = result
+      payload.command = "jj " .. table.concat(result, " ")
+    end
+  end
+end
+return payload.args
+`
+
+// BeforeCommandMsg holds a command back until the before_command hooks have
+// let it through.
+type BeforeCommandMsg struct {
+	Args        []string
+	Interactive bool
+	run         func(args []string) tea.Cmd
+	// stopped, if set, is told why the hooks didn't let the command run.
+	stopped func(err error) tea.Cmd
+}
+
+// hookedCommandRunner sends the commands run for the user through the
+// before_command hooks. Commands started elsewhere, like `:` exec, the push
+// preview and jjui.jj, go through BeforeCommand. The commands jjui runs to
+// show the repository don't go through them.
+type hookedCommandRunner struct {
+	uicontext.CommandRunner
+	ctx *uicontext.MainContext
+}
+
+var _ uicontext.CommandHook = (*hookedCommandRunner)(nil)
+
+func (h *hookedCommandRunner) RunCommand(args []string, continuations ...tea.Cmd) tea.Cmd {
+	return h.BeforeCommand(args, false, func(args []string) tea.Cmd {
+		return h.CommandRunner.RunCommand(args, continuations...)
+	}, nil)
+}
+
+func (h *hookedCommandRunner) RunCommandWithInput(args []string, input string, continuations ...tea.Cmd) tea.Cmd {
+	return h.BeforeCommand(args, false, func(args []string) tea.Cmd {
+		return h.CommandRunner.RunCommandWithInput(args, input, continuations...)
+	}, nil)
+}
+
+func (h *hookedCommandRunner) RunInteractiveCommand(args []string, continuation tea.Cmd) tea.Cmd {
+	return h.BeforeCommand(args, true, func(args []string) tea.Cmd {
+		return h.CommandRunner.RunInteractiveCommand(args, continuation)
+	}, nil)
+}
+
+func (h *hookedCommandRunner) BeforeCommand(args []string, interactive bool, run func([]string) tea.Cmd, stopped func(error) tea.Cmd) tea.Cmd {
+	if !HasHooks(h.ctx, HookBeforeCommand) {
+		return run(args)
+	}
+	// the hooks are run by the main loop, since this may be called from a
+	// running script
+	msg := BeforeCommandMsg{Args: slices.Clone(args), Interactive: interactive, run: run, stopped: stopped}
+	return func() tea.Msg { return msg }
+}
+
+// unhookedKey marks the context of the before_command hooks, whose own
+// commands don't go through the hooks again.
+type unhookedKey struct{}
+
+// skipHooks keeps the commands the script of the runner starts out of the
+// before_command hooks.
+func (r *Runner) skipHooks() {
+	r.thread.SetContext(stdcontext.WithValue(r.thread.Context(), unhookedKey{}, true))
+}
+
+func unhooked(L *lua.LState) bool {
+	ctx := L.Context()
+	return ctx != nil && ctx.Value(unhookedKey{}) != nil
+}
+
+// commandRunner is the runner of the commands a script starts, which skips
+// the before_command hooks when the script is one of them.
+func commandRunner(L *lua.LState, ctx *uicontext.MainContext) uicontext.CommandRunner {
+	if hooked, ok := ctx.CommandRunner.(*hookedCommandRunner); ok && unhooked(L) {
+		return hooked.CommandRunner
+	}
+	return ctx.CommandRunner
+}
+
+// installCommandHooks makes the commands run for the user go through the
+// before_command hooks.
+func installCommandHooks(ctx *uicontext.MainContext) {
+	if _, ok := ctx.CommandRunner.(*hookedCommandRunner); ok || ctx.CommandRunner == nil {
+		return
+	}
+	ctx.CommandRunner = &hookedCommandRunner{CommandRunner: ctx.CommandRunner, ctx: ctx}
+}
+
+func ensureHookRegistry(L *lua.LState) *lua.LTable {
+	if existing, ok := L.GetGlobal(hookRegistryName).(*lua.LTable); ok {
+		return existing
+	}
+	tbl := L.NewTable()
+	L.SetGlobal(hookRegistryName, tbl)
+	return tbl
+}
+
+func registerHookAPI(L *lua.LState, root *lua.LTable) {
+	root.RawSetString("on", L.NewFunction(func(L *lua.LState) int {
+		event := L.CheckString(1)
+		fn := L.CheckFunction(2)
+		if !slices.Contains(hookEvents, event) {
+			L.ArgError(1, fmt.Sprintf("unknown event %q (expected one of: %s)", event, strings.Join(hookEvents, ", ")))
+			return 0
+		}
+		registry := ensureHookRegistry(L)
+		hooks, ok := registry.RawGetString(event).(*lua.LTable)
+		if !ok {
+			hooks = L.NewTable()
+			registry.RawSetString(event, hooks)
+		}
+		hooks.Append(fn)
+		return 0
+	}))
+}
+
+func hooksOf(ctx *uicontext.MainContext, event string) *lua.LTable {
+	if ctx == nil || ctx.ScriptVM == nil {
+		return nil
+	}
+	registry, ok := ctx.ScriptVM.GetGlobal(hookRegistryName).(*lua.LTable)
+	if !ok {
+		return nil
+	}
+	hooks, ok := registry.RawGetString(event).(*lua.LTable)
+	if !ok || hooks.Len() == 0 {
+		return nil
+	}
+	return hooks
+}
+
+// HasHooks reports whether any hook is registered for the event.
+func HasHooks(ctx *uicontext.MainContext, event string) bool {
+	return hooksOf(ctx, event) != nil
+}
+
+// runHooks calls the hooks of the event with the payload. The runner is
+// returned if the hooks wait for a message, and must be given messages until
+// it is done.
+func runHooks(ctx *uicontext.MainContext, event string, payload *lua.LTable, onDone func([]lua.LValue, error) tea.Cmd) (*Runner, tea.Cmd) {
+	hooks := hooksOf(ctx, event)
+	if hooks == nil {
+		if onDone != nil {
+			return nil, onDone(nil, nil)
+		}
+		return nil, nil
+	}
+	L := ctx.ScriptVM
+	dispatcher, ok := L.GetGlobal(hookDispatcherName).(*lua.LFunction)
+	if !ok {
+		fn, err := L.LoadString(hookDispatcher)
+		if err != nil {
+			return nil, intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err})
+		}
+		L.SetGlobal(hookDispatcherName, fn)
+		dispatcher = fn
+	}
+	r := newRunner(ctx, L, "", dispatcher, onDone, hooks, lua.LString(event), payload)
+	if event == HookBeforeCommand {
+		r.skipHooks()
+	}
+	cmd := r.start()
+	if r.Done() {
+		return nil, cmd
+	}
+	return r, cmd
+}
+
+// RunSelectionChangedHooks tells the selection_changed hooks what is
+// selected now.
+func RunSelectionChangedHooks(ctx *uicontext.MainContext, item common.SelectedItem) (*Runner, tea.Cmd) {
+	if !HasHooks(ctx, HookSelectionChanged) {
+		return nil, nil
+	}
+	payload := selectedItemToLua(ctx.ScriptVM, item)
+	return runHooks(ctx, HookSelectionChanged, payload, nil)
+}
+
+// RunRefreshCompletedHooks tells the refresh_completed hooks that the
+// revisions have been loaded.
+func RunRefreshCompletedHooks(ctx *uicontext.MainContext) (*Runner, tea.Cmd) {
+	if !HasHooks(ctx, HookRefreshCompleted) {
+		return nil, nil
+	}
+	payload := ctx.ScriptVM.NewTable()
+	payload.RawSetString("revset", lua.LString(ctx.CurrentRevset))
+	return runHooks(ctx, HookRefreshCompleted, payload, nil)
+}
+
+// RunRevsetChangedHooks tells the revset_changed hooks which revset is shown
+// instead of the previous one.
+func RunRevsetChangedHooks(ctx *uicontext.MainContext, previous string) (*Runner, tea.Cmd) {
+	if !HasHooks(ctx, HookRevsetChanged) {
+		return nil, nil
+	}
+	payload := ctx.ScriptVM.NewTable()
+	payload.RawSetString("revset", lua.LString(ctx.CurrentRevset))
+	payload.RawSetString("previous", lua.LString(previous))
+	return runHooks(ctx, HookRevsetChanged, payload, nil)
+}
+
+// RunBeforeCommandHooks asks the before_command hooks whether the command may
+// run, and with which arguments. A hook that fails stops the command too.
+func RunBeforeCommandHooks(ctx *uicontext.MainContext, msg BeforeCommandMsg) (*Runner, tea.Cmd) {
+	if !HasHooks(ctx, HookBeforeCommand) {
+		return nil, msg.run(msg.Args)
+	}
+	L := ctx.ScriptVM
+	payload := L.NewTable()
+	payload.RawSetString("args", stringsToLuaTable(L, msg.Args))
+	payload.RawSetString("command", lua.LString("jj "+strings.Join(msg.Args, " ")))
+	payload.RawSetString("interactive", lua.LBool(msg.Interactive))
+	stopped := func(err error) tea.Cmd {
+		if msg.stopped == nil {
+			return nil
+		}
+		return msg.stopped(err)
+	}
+	return runHooks(ctx, HookBeforeCommand, payload, func(values []lua.LValue, err error) tea.Cmd {
+		if err != nil {
+			// the failure of the hook has been reported already
+			return stopped(err)
+		}
+		if len(values) > 0 && values[0] == lua.LFalse {
+			text := fmt.Sprintf("jj %s was stopped by a before_command hook", strings.Join(msg.Args, " "))
+			if len(values) > 1 && values[1] != lua.LNil {
+				text += ": " + values[1].String()
+			}
+			err := fmt.Errorf("%s", text)
+			return tea.Batch(intents.Invoke(intents.AddMessage{Text: text, Err: err}), stopped(err))
+		}
+		args := msg.Args
+		if len(values) > 0 {
+			if tbl, ok := values[0].(*lua.LTable); ok {
+				args = stringSliceFromTable(tbl)
+			}
+		}
+		return msg.run(args)
+	})
+}
+
+// RunAfterCommandHooks tells the after_command hooks how a command went.
+// Args are nil when they aren't known.
+func RunAfterCommandHooks(ctx *uicontext.MainContext, command string, args []string, msg common.CommandCompletedMsg) (*Runner, tea.Cmd) {
+	if !HasHooks(ctx, HookAfterCommand) {
+		return nil, nil
+	}
+	L := ctx.ScriptVM
+	payload := L.NewTable()
+	payload.RawSetString("command", lua.LString(command))
+	if args != nil {
+		payload.RawSetString("args", stringsToLuaTable(L, args))
+	}
+	payload.RawSetString("success", lua.LBool(msg.Err == nil))
+	payload.RawSetString("exit_code", lua.LNumber(msg.ExitCode))
+	payload.RawSetString("output", lua.LString(ansi.Strip(msg.Output)))
+	if msg.Err != nil {
+		payload.RawSetString("error", lua.LString(ansi.Strip(msg.Err.Error())))
+	}
+	return runHooks(ctx, HookAfterCommand, payload, nil)
+}
+
+// selectedItemToLua describes the item with its kind and ids.
+func selectedItemToLua(L *lua.LState, item common.SelectedItem) *lua.LTable {
+	payload := L.NewTable()
+	switch item := item.(type) {
+	case common.SelectedRevision:
+		payload.RawSetString("kind", lua.LString("revision"))
+		payload.RawSetString("change_id", lua.LString(item.ChangeId))
+		payload.RawSetString("commit_id", lua.LString(item.CommitId))
+	case common.SelectedFile:
+		payload.RawSetString("kind", lua.LString("file"))
+		payload.RawSetString("change_id", lua.LString(item.ChangeId))
+		payload.RawSetString("commit_id", lua.LString(item.CommitId))
+		payload.RawSetString("file", lua.LString(item.File))
+	case common.SelectedCommit:
+		payload.RawSetString("kind", lua.LString("commit"))
+		payload.RawSetString("commit_id", lua.LString(item.CommitId))
+	case common.SelectedOperation:
+		payload.RawSetString("kind", lua.LString("operation"))
+		payload.RawSetString("operation_id", lua.LString(item.OperationId))
+	}
+	return payload
+}
+
+func stringsToLuaTable(L *lua.LState, values []string) *lua.LTable {
+	tbl := L.NewTable()
+	for _, value := range values {
+		tbl.Append(lua.LString(value))
+	}
+	return tbl
+}
@@ -0,0 +1,193 @@
+package scripting
+
+import (
+	"errors"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/idursun/jjui/internal/ui/common"
+	uicontext "github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/test"
+)
+
+func setupHookedVM(t *testing.T, commandRunner *test.CommandRunner, script string) *uicontext.MainContext {
+	t.Helper()
+	ctx := setupVM(t)
+	ctx.CommandRunner = commandRunner
+	installCommandHooks(ctx)
+	require.NoError(t, ctx.ScriptVM.DoString(script))
+	return ctx
+}
+
+func TestOn_RejectsUnknownEvents(t *testing.T) {
+	ctx := setupVM(t)
+	err := ctx.ScriptVM.DoString(`jjui.on("unknown", function() end)`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown event "unknown"`)
+	assert.False(t, HasHooks(ctx, "unknown"))
+}
+
+func TestBeforeCommand_StopsCommand(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	defer commandRunner.Verify()
+	ctx := setupHookedVM(t, commandRunner, `
+		jjui.on("before_command", function(e)
+			if e.args[1] == "git" and e.args[2] == "push" then
+				return false, "the revision is WIP"
+			end
+		end)
+	`)
+
+	msg, ok := ctx.RunCommand([]string{"git", "push"})().(BeforeCommandMsg)
+	require.True(t, ok)
+	runner, cmd := RunBeforeCommandHooks(ctx, msg)
+	assert.Nil(t, runner)
+	require.NotNil(t, cmd)
+	flash, ok := cmd().(intents.AddMessage)
+	require.True(t, ok)
+	assert.Equal(t, "jj git push was stopped by a before_command hook: the revision is WIP", flash.Text)
+	assert.Error(t, flash.Err)
+}
+
+func TestBeforeCommand_RewritesArguments(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect([]string{"git", "push", "--dry-run"})
+	defer commandRunner.Verify()
+	ctx := setupHookedVM(t, commandRunner, `
+		jjui.on("before_command", function(e)
+			table.insert(e.args, "--dry-run")
+			return e.args
+		end)
+		jjui.on("before_command", function(e)
+			seen = e.command
+		end)
+	`)
+
+	msg := ctx.RunCommand([]string{"git", "push"})().(BeforeCommandMsg)
+	_, cmd := RunBeforeCommandHooks(ctx, msg)
+	require.NotNil(t, cmd)
+	assert.IsType(t, common.CommandCompletedMsg{}, cmd())
+	assert.Equal(t, "jj git push --dry-run", ctx.ScriptVM.GetGlobal("seen").String())
+}
+
+func TestBeforeCommand_WithoutHooksRunsCommand(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect([]string{"new"})
+	defer commandRunner.Verify()
+	ctx := setupHookedVM(t, commandRunner, ``)
+
+	assert.IsType(t, common.CommandCompletedMsg{}, ctx.RunCommand([]string{"new"})())
+}
+
+func TestAfterCommand_GetsResult(t *testing.T) {
+	ctx := setupHookedVM(t, test.NewTestCommandRunner(t), `
+		jjui.on("after_command", function(e)
+			command, success, exit_code, output, err = e.command, e.success, e.exit_code, e.output, e.error
+		end)
+	`)
+
+	msg := common.CommandCompletedMsg{Output: "\x1b[1mrejected\x1b[0m", Err: errors.New("push failed"), ExitCode: 1}
+	runner, _ := RunAfterCommandHooks(ctx, "jj git push", []string{"git", "push"}, msg)
+	assert.Nil(t, runner)
+	L := ctx.ScriptVM
+	assert.Equal(t, "jj git push", L.GetGlobal("command").String())
+	assert.Equal(t, "false", L.GetGlobal("success").String())
+	assert.Equal(t, "1", L.GetGlobal("exit_code").String())
+	assert.Equal(t, "rejected", L.GetGlobal("output").String())
+	assert.Equal(t, "push failed", L.GetGlobal("err").String())
+}
+
+func TestHooks_CanWaitForMessages(t *testing.T) {
+	ctx := setupHookedVM(t, test.NewTestCommandRunner(t), `
+		jjui.on("selection_changed", function(e)
+			wait_close()
+			selected = e.change_id
+		end)
+	`)
+
+	runner, _ := RunSelectionChangedHooks(ctx, common.SelectedRevision{ChangeId: "abc", CommitId: "123"})
+	require.NotNil(t, runner)
+	assert.False(t, runner.Done())
+
+	runner.HandleMsg(common.CloseViewMsg{})
+	assert.True(t, runner.Done())
+	assert.Equal(t, "abc", ctx.ScriptVM.GetGlobal("selected").String())
+}
+
+func TestRevsetChanged_GetsBothRevsets(t *testing.T) {
+	ctx := setupHookedVM(t, test.NewTestCommandRunner(t), `
+		jjui.on("revset_changed", function(e)
+			revset, previous = e.revset, e.previous
+		end)
+	`)
+	ctx.CurrentRevset = "mine()"
+
+	RunRevsetChangedHooks(ctx, "all()")
+	assert.Equal(t, "mine()", ctx.ScriptVM.GetGlobal("revset").String())
+	assert.Equal(t, "all()", ctx.ScriptVM.GetGlobal("previous").String())
+}
+
+// hookLoop runs the before_command hooks and feeds every runner the messages
+// like the main loop does.
+type hookLoop struct {
+	ctx     *uicontext.MainContext
+	runners []*Runner
+}
+
+func (l *hookLoop) Update(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(BeforeCommandMsg); ok {
+		runner, cmd := RunBeforeCommandHooks(l.ctx, msg)
+		if runner != nil {
+			l.runners = append(l.runners, runner)
+		}
+		return cmd
+	}
+	var cmds []tea.Cmd
+	for _, runner := range l.runners {
+		cmds = append(cmds, runner.HandleMsg(msg))
+	}
+	return tea.Batch(cmds...)
+}
+
+func TestBeforeCommand_StopsScriptCommands(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	defer commandRunner.Verify()
+	ctx := setupHookedVM(t, commandRunner, `
+		jjui.on("before_command", function(e)
+			return false, "frozen"
+		end)
+	`)
+
+	runner, cmd, err := RunScript(ctx, `out, err = jj_wait("new")`)
+	require.NoError(t, err)
+	test.SimulateModel(&hookLoop{ctx: ctx, runners: []*Runner{runner}}, cmd)
+	assert.True(t, runner.Done())
+	assert.Equal(t, "nil", ctx.ScriptVM.GetGlobal("out").String())
+	assert.Equal(t, "jj new was stopped by a before_command hook: frozen", ctx.ScriptVM.GetGlobal("err").String())
+}
+
+func TestBeforeCommand_HooksDontHookTheirOwnCommands(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect([]string{"bookmark", "list"}).SetOutput([]byte("main"))
+	commandRunner.Expect([]string{"new"}).SetOutput([]byte("created"))
+	defer commandRunner.Verify()
+	ctx := setupHookedVM(t, commandRunner, `
+		jjui.on("before_command", function(e)
+			calls = (calls or 0) + 1
+			bookmarks = jj_wait("bookmark", "list")
+		end)
+	`)
+
+	runner, cmd, err := RunScript(ctx, `out = jj_wait("new")`)
+	require.NoError(t, err)
+	test.SimulateModel(&hookLoop{ctx: ctx, runners: []*Runner{runner}}, cmd)
+	assert.True(t, runner.Done())
+	assert.Equal(t, "1", ctx.ScriptVM.GetGlobal("calls").String())
+	assert.Equal(t, "main", ctx.ScriptVM.GetGlobal("bookmarks").String())
+	assert.Equal(t, "created", ctx.ScriptVM.GetGlobal("out").String())
+}
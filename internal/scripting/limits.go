@@ -0,0 +1,193 @@
+package scripting
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"runtime/metrics"
+	"strings"
+	"time"
+
+	"github.com/idursun/jjui/internal/config"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// The limits of scripts, so that a runaway script can't take jjui down with
+// it. Lua runs in the main loop, so a script that never gives control back
+// freezes the UI until it is stopped.
+const (
+	// scriptMemoryLimit is how much a script may grow the heap by while it
+	// runs. It is approximate as the heap also holds what the rest of jjui
+	// allocates in the meantime.
+	scriptMemoryLimit = 256 * 1024 * 1024
+	// maxScriptOutputSize is the largest command output handed to a script;
+	// a command that writes more is killed.
+	maxScriptOutputSize = 4 * 1024 * 1024
+	// watchInterval is how often the memory of a running script is checked.
+	watchInterval = 10 * time.Millisecond
+)
+
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// limitError is why a script was stopped.
+type limitError struct {
+	reason string
+}
+
+func (e *limitError) Error() string {
+	return e.reason
+}
+
+var errScriptAborted = &limitError{reason: "script aborted"}
+
+func scriptTimeout() time.Duration {
+	return time.Duration(max(0, config.Current.Timeouts.Script)) * time.Second
+}
+
+func heapBytes() uint64 {
+	sample := []metrics.Sample{{Name: heapMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// watchdog cancels what runs under it once it has run for longer than the
+// script timeout, or has grown the heap by more than scriptMemoryLimit.
+type watchdog struct {
+	done   chan struct{}
+	exited chan struct{}
+	paused chan bool
+}
+
+type watchdogKey struct{}
+
+// watchdogSlot holds the watchdog of what runs under a context, which a
+// Runner replaces every time it is resumed.
+type watchdogSlot struct {
+	current *watchdog
+}
+
+// watch starts a watchdog for what runs under cancel. It has to be stopped
+// once the script gives control back.
+func watch(cancel stdcontext.CancelCauseFunc) *watchdog {
+	w := &watchdog{done: make(chan struct{}), exited: make(chan struct{}), paused: make(chan bool)}
+	timeout := scriptTimeout()
+	go func() {
+		defer close(w.exited)
+		var timer *time.Timer
+		var deadline <-chan time.Time
+		if timeout > 0 {
+			timer = time.NewTimer(timeout)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+		remaining, since := timeout, time.Now()
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		baseline := heapBytes()
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-deadline:
+				cancel(&limitError{reason: fmt.Sprintf("script ran for more than %s", timeout)})
+				return
+			case paused := <-w.paused:
+				if timer == nil {
+					continue
+				}
+				if paused {
+					timer.Stop()
+					remaining -= time.Since(since)
+				} else {
+					since = time.Now()
+					timer.Reset(remaining)
+				}
+			case <-ticker.C:
+				if heapBytes() > baseline+scriptMemoryLimit {
+					cancel(&limitError{reason: fmt.Sprintf("script used more than %d MiB of memory", scriptMemoryLimit/1024/1024)})
+					return
+				}
+			}
+		}
+	}()
+	return w
+}
+
+func (w *watchdog) stop() {
+	close(w.done)
+}
+
+// pause stops the clock of the script timeout until resume is called. The
+// memory is still watched.
+func (w *watchdog) pause() {
+	select {
+	case w.paused <- true:
+	case <-w.exited:
+	}
+}
+
+func (w *watchdog) resume() {
+	select {
+	case w.paused <- false:
+	case <-w.exited:
+	}
+}
+
+// pauseWatch pauses the watchdog of the call L runs under, if there is one,
+// for the time a script waits for a command, which has a timeout of its own.
+// The returned function resumes it.
+func pauseWatch(L *lua.LState) (resume func()) {
+	ctx := L.Context()
+	if ctx == nil {
+		return func() {}
+	}
+	slot, ok := ctx.Value(watchdogKey{}).(*watchdogSlot)
+	if !ok || slot.current == nil {
+		return func() {}
+	}
+	w := slot.current
+	w.pause()
+	return w.resume
+}
+
+// limitedError replaces the error Lua raises when ctx is cancelled with why
+// it was cancelled, keeping the position of the script it was raised at.
+func limitedError(ctx stdcontext.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	var limit *limitError
+	if !errors.As(stdcontext.Cause(ctx), &limit) {
+		return err
+	}
+	var apiErr *lua.ApiError
+	if !errors.As(err, &apiErr) {
+		return limit
+	}
+	msg := apiErr.Object.String()
+	if !strings.Contains(msg, ctx.Err().Error()) {
+		return err
+	}
+	return errors.New(strings.Replace(msg, ctx.Err().Error(), limit.Error(), 1))
+}
+
+// callWithLimits is L.CallByParam for calls made outside a Runner, which
+// stops the call when it runs into the limits of scripts.
+func callWithLimits(L *lua.LState, p lua.P, args ...lua.LValue) error {
+	ctx, cancel := stdcontext.WithCancelCause(stdcontext.Background())
+	defer cancel(nil)
+	previous := L.RemoveContext()
+	w := watch(cancel)
+	L.SetContext(stdcontext.WithValue(ctx, watchdogKey{}, &watchdogSlot{current: w}))
+	err := L.CallByParam(p, args...)
+	w.stop()
+	if previous != nil {
+		L.SetContext(previous)
+	} else {
+		L.RemoveContext()
+	}
+	return limitedError(ctx, err)
+}
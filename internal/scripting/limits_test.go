@@ -0,0 +1,158 @@
+package scripting
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withScriptTimeout(t *testing.T, seconds int) {
+	t.Helper()
+	previous := config.Current.Timeouts.Script
+	config.Current.Timeouts.Script = seconds
+	t.Cleanup(func() {
+		config.Current.Timeouts.Script = previous
+	})
+}
+
+func flashedErrors(cmd tea.Cmd) []string {
+	var errs []string
+	test.SimulateModel(ignoreMsgs{}, cmd, func(msg tea.Msg) {
+		if flash, ok := msg.(intents.AddMessage); ok && flash.Err != nil {
+			errs = append(errs, flash.Text)
+		}
+	})
+	return errs
+}
+
+func TestRunAction_StopsScriptThatRunsTooLong(t *testing.T) {
+	withScriptTimeout(t, 1)
+	ctx := setupVM(t)
+
+	runner, cmd, err := RunAction(ctx, "spin", "local n = 0\nwhile true do n = n + 1 end")
+	require.NoError(t, err)
+	assert.True(t, runner.Done())
+	assert.Equal(t, []string{"action spin: lua:2: script ran for more than 1s"}, flashedErrors(cmd))
+
+	// the VM can still run scripts afterwards
+	runner, cmd, err = RunAction(ctx, "after", `done = true`)
+	require.NoError(t, err)
+	assert.True(t, runner.Done())
+	assert.Empty(t, flashedErrors(cmd))
+	assert.Equal(t, "true", ctx.ScriptVM.GetGlobal("done").String())
+}
+
+func TestRunSetup_StopsSetupThatRunsTooLong(t *testing.T) {
+	withScriptTimeout(t, 1)
+	ctx := setupVM(t)
+	cfg := *config.Current
+
+	err := RunSetup(ctx, &cfg, "function setup(config)\n  while true do end\nend")
+	require.Error(t, err)
+	assert.Equal(t, "config.lua: setup(): config.lua:2: script ran for more than 1s", err.Error())
+}
+
+func TestRunAction_ReportsActionAndLine(t *testing.T) {
+	ctx := setupVM(t)
+	cfg := *config.Current
+	require.NoError(t, RunSetup(ctx, &cfg, `
+function setup(config)
+  config.action("explode", function()
+    error("boom")
+  end)
+end
+`))
+	action, ok := findActionByName(cfg.Actions, "explode")
+	require.True(t, ok)
+
+	_, cmd, err := RunAction(ctx, action.Name, action.Lua)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"action explode: config.lua:4: boom"}, flashedErrors(cmd))
+
+	_, _, err = RunAction(ctx, "broken", "if then")
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "action broken: lua line:1"), err.Error())
+}
+
+func TestRunner_AbortEndsWaitingScript(t *testing.T) {
+	ctx := setupVM(t)
+	runner, _, err := RunAction(ctx, "waiting", `
+		wait_refresh()
+		done = true
+	`)
+	require.NoError(t, err)
+	require.False(t, runner.Done())
+
+	assert.Equal(t, []string{"action waiting: script aborted"}, flashedErrors(runner.Abort()))
+	assert.True(t, runner.Done())
+	assert.Nil(t, runner.Abort())
+	assert.NotEqual(t, "true", ctx.ScriptVM.GetGlobal("done").String())
+}
+
+func TestJj_RefusesOutputOverTheLimit(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect([]string{"log"}).SetOutput([]byte(strings.Repeat("x", maxScriptOutputSize+1)))
+	defer commandRunner.Verify()
+
+	ctx := setupVM(t)
+	ctx.CommandRunner = commandRunner
+	runner, cmd, err := RunScript(ctx, `out, err = jj("log")`)
+	require.NoError(t, err)
+	test.SimulateModel(runnerModel{runner}, cmd)
+	assert.True(t, runner.Done())
+	assert.Equal(t, "nil", ctx.ScriptVM.GetGlobal("out").String())
+	assert.Contains(t, ctx.ScriptVM.GetGlobal("err").String(), "more than the limit")
+}
+
+// slowCommandRunner takes its time to run the commands of scripts.
+type slowCommandRunner struct {
+	*test.CommandRunner
+	delay time.Duration
+}
+
+func (r slowCommandRunner) RunCommandImmediateWithLimit(args []string, class string, limit int) ([]byte, error) {
+	time.Sleep(r.delay)
+	return r.CommandRunner.RunCommandImmediateWithLimit(args, class, limit)
+}
+
+func (r slowCommandRunner) RunCommandImmediateIfIdle(args []string, class string, limit int) ([]byte, error) {
+	time.Sleep(r.delay)
+	return r.CommandRunner.RunCommandImmediateIfIdle(args, class, limit)
+}
+
+func TestRunSetup_DoesntCountCommandsAgainstTheTimeout(t *testing.T) {
+	withScriptTimeout(t, 1)
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect([]string{"log"}).SetOutput([]byte("ok"))
+	defer commandRunner.Verify()
+
+	ctx := setupVM(t)
+	ctx.CommandRunner = slowCommandRunner{CommandRunner: commandRunner, delay: 1500 * time.Millisecond}
+	cfg := *config.Current
+
+	require.NoError(t, RunSetup(ctx, &cfg, `function setup(config) out = jj("log") end`))
+	assert.Equal(t, "ok", ctx.ScriptVM.GetGlobal("out").String())
+}
+
+func TestRunAction_DoesntCountCommandsAgainstTheTimeout(t *testing.T) {
+	withScriptTimeout(t, 1)
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect([]string{"log"}).SetOutput([]byte("ok"))
+	defer commandRunner.Verify()
+
+	ctx := setupVM(t)
+	ctx.CommandRunner = slowCommandRunner{CommandRunner: commandRunner, delay: 1500 * time.Millisecond}
+
+	runner, cmd, err := RunAction(ctx, "slow", `out = jj("log")`)
+	require.NoError(t, err)
+	assert.True(t, runner.Done())
+	assert.Empty(t, flashedErrors(cmd))
+	assert.Equal(t, "ok", ctx.ScriptVM.GetGlobal("out").String())
+}
@@ -4,9 +4,12 @@ import (
 	stdcontext "context"
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/ui/actionmeta"
 	"github.com/idursun/jjui/internal/ui/choose"
 	"github.com/idursun/jjui/internal/ui/common"
@@ -21,49 +24,133 @@ import (
 type step struct {
 	cmd     tea.Cmd
 	matcher func(tea.Msg) (bool, []lua.LValue)
+	// values are returned to the script when a step without a matcher
+	// resumes it.
+	values []lua.LValue
 }
 
 type Runner struct {
-	ctx        *uicontext.MainContext
-	main       *lua.LState
-	thread     *lua.LState
-	cancel     stdcontext.CancelFunc
+	ctx    *uicontext.MainContext
+	main   *lua.LState
+	thread *lua.LState
+	// name is the action the script runs for, which errors are reported
+	// with.
+	name       string
+	limits     stdcontext.Context
+	cancel     stdcontext.CancelCauseFunc
+	watched    *watchdogSlot
 	fn         *lua.LFunction
 	started    bool
 	await      func(tea.Msg) (bool, []lua.LValue)
 	resumeArgs []lua.LValue
 	done       bool
+	// quiet leaves reporting errors to onDone instead of flashing them.
+	quiet bool
+	// onDone is given what the script returned, or the error it failed with,
+	// once it is done.
+	onDone func(values []lua.LValue, err error) tea.Cmd
 }
 
 func RunScript(ctx *uicontext.MainContext, src string) (*Runner, tea.Cmd, error) {
+	return RunAction(ctx, "", src)
+}
+
+// RunAction runs the Lua of the named action. Errors of the script are
+// reported with the name of the action.
+func RunAction(ctx *uicontext.MainContext, name string, src string) (*Runner, tea.Cmd, error) {
 	L, err := vmFromContext(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	r := &Runner{ctx: ctx, main: L}
-
-	fn, err := L.LoadString(src)
+	fn, err := L.Load(strings.NewReader(src), "lua")
 	if err != nil {
+		if name != "" {
+			return nil, nil, fmt.Errorf("action %s: %w", name, err)
+		}
 		return nil, nil, fmt.Errorf("lua: %w", err)
 	}
-	r.fn = fn
-	r.thread, r.cancel = L.NewThread()
+	r, cmd := startNamedRunner(ctx, L, name, fn, nil)
+	return r, cmd, nil
+}
 
+// startRunner runs fn with args in a coroutine of its own until it finishes
+// or waits for a message.
+func startRunner(ctx *uicontext.MainContext, L *lua.LState, fn *lua.LFunction, onDone func([]lua.LValue, error) tea.Cmd, args ...lua.LValue) (*Runner, tea.Cmd) {
+	return startNamedRunner(ctx, L, "", fn, onDone, args...)
+}
+
+func startNamedRunner(ctx *uicontext.MainContext, L *lua.LState, name string, fn *lua.LFunction, onDone func([]lua.LValue, error) tea.Cmd, args ...lua.LValue) (*Runner, tea.Cmd) {
+	r := newRunner(ctx, L, name, fn, onDone, args...)
+	return r, r.start()
+}
+
+// runnerEntryName holds the function scripts are started with. It calls the
+// script in a position that isn't a tail call, since gopher-lua can't resume
+// a coroutine whose first function returned a call that yielded, e.g.
+// `return jj("log")`.
+const runnerEntryName = "__jjui_runner_entry"
+
+const runnerEntry = `
+local function pack(...)
+  return select("#", ...), {...}
+end
+return function(fn, ...)
+  local n, results = pack(fn(...))
+  return unpack(results, 1, n)
+end
+`
+
+func runnerEntryOf(L *lua.LState) (*lua.LFunction, error) {
+	if entry, ok := L.GetGlobal(runnerEntryName).(*lua.LFunction); ok {
+		return entry, nil
+	}
+	if err := L.DoString(runnerEntry); err != nil {
+		return nil, err
+	}
+	entry := L.Get(-1).(*lua.LFunction)
+	L.Pop(1)
+	L.SetGlobal(runnerEntryName, entry)
+	return entry, nil
+}
+
+func newRunner(ctx *uicontext.MainContext, L *lua.LState, name string, fn *lua.LFunction, onDone func([]lua.LValue, error) tea.Cmd, args ...lua.LValue) *Runner {
+	if entry, err := runnerEntryOf(L); err == nil {
+		args = append([]lua.LValue{fn}, args...)
+		fn = entry
+	}
+	r := &Runner{ctx: ctx, main: L, name: name, fn: fn, resumeArgs: args, onDone: onDone}
+	r.thread, _ = L.NewThread()
+	// the coroutines the script starts share its limits
+	r.limits, r.cancel = stdcontext.WithCancelCause(stdcontext.Background())
+	r.watched = &watchdogSlot{}
+	r.thread.SetContext(stdcontext.WithValue(r.limits, watchdogKey{}, r.watched))
+	return r
+}
+
+func (r *Runner) start() tea.Cmd {
 	cmd := r.resume()
 	if r.done {
 		r.close()
 	}
-	return r, cmd, nil
+	return cmd
 }
 
 func (r *Runner) close() {
 	if r.cancel != nil {
-		r.cancel()
+		r.cancel(nil)
 		r.cancel = nil
 	}
 }
 
+// describe adds the action the script runs for to its error.
+func (r *Runner) describe(err error) error {
+	if r.name == "" {
+		return err
+	}
+	return fmt.Errorf("action %s: %w", r.name, err)
+}
+
 func (r *Runner) resume() tea.Cmd {
 	if r.done {
 		return nil
@@ -76,11 +163,19 @@ func (r *Runner) resume() tea.Cmd {
 		}
 		args := r.resumeArgs
 		r.resumeArgs = nil
+		r.watched.current = watch(r.cancel)
 		state, err, values := r.main.Resume(r.thread, fn, args...)
+		r.watched.current.stop()
 		r.started = true
 		if err != nil {
+			err = r.describe(limitedError(r.limits, err))
 			r.done = true
-			cmds = append(cmds, intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err}))
+			if !r.quiet {
+				cmds = append(cmds, intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err}))
+			}
+			if r.onDone != nil {
+				cmds = append(cmds, r.onDone(nil, err))
+			}
 			break
 		}
 		for _, v := range values {
@@ -96,11 +191,15 @@ func (r *Runner) resume() tea.Cmd {
 					if st.cmd != nil {
 						cmds = append(cmds, st.cmd)
 					}
+					r.resumeArgs = st.values
 				}
 			}
 		}
 		if state == lua.ResumeOK {
 			r.done = true
+			if r.onDone != nil {
+				cmds = append(cmds, r.onDone(values, nil))
+			}
 			break
 		}
 		// continue to resume to collect subsequent steps until an await or completion
@@ -132,29 +231,38 @@ func (r *Runner) HandleMsg(msg tea.Msg) tea.Cmd {
 	return cmd
 }
 
+// Stop ends the script wherever it waits, without reporting it.
+func (r *Runner) Stop() {
+	r.done = true
+	r.await = nil
+	r.close()
+}
+
+// Abort ends the script wherever it waits, as if it had failed there.
+func (r *Runner) Abort() tea.Cmd {
+	if r.Done() {
+		return nil
+	}
+	r.Stop()
+	err := r.describe(errScriptAborted)
+	var cmds []tea.Cmd
+	if !r.quiet {
+		cmds = append(cmds, intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err}))
+	}
+	if r.onDone != nil {
+		cmds = append(cmds, r.onDone(nil, err))
+	}
+	return tea.Batch(cmds...)
+}
+
 func (r *Runner) Done() bool {
 	return r.done && r.await == nil
 }
 
 func registerAPI(L *lua.LState, ctx *uicontext.MainContext) {
 	revisionsTable := L.NewTable()
-	revisionsTable.RawSetString("current", L.NewFunction(func(L *lua.LState) int {
-		if rev, ok := ctx.SelectedItem.(uicontext.SelectedRevision); ok {
-			L.Push(lua.LString(rev.ChangeId))
-			return 1
-		}
-		return 0
-	}))
-	revisionsTable.RawSetString("checked", L.NewFunction(func(L *lua.LState) int {
-		tbl := L.NewTable()
-		for _, item := range ctx.CheckedItems {
-			if rev, ok := item.(uicontext.SelectedRevision); ok {
-				tbl.Append(lua.LString(rev.ChangeId))
-			}
-		}
-		L.Push(tbl)
-		return 1
-	}))
+	registerRevisionAPI(L, ctx, revisionsTable)
+	registerDecorationAPI(L, revisionsTable)
 	revisionsTable.RawSetString("refresh", L.NewFunction(func(L *lua.LState) int {
 		payload := payloadFromTop(L)
 		intent := intents.Refresh{
@@ -277,23 +385,62 @@ func registerAPI(L *lua.LState, ctx *uicontext.MainContext) {
 
 	jjAsyncFn := L.NewFunction(func(L *lua.LState) int {
 		args := argsFromLua(L)
-		return yieldStep(L, step{cmd: ctx.RunCommand(args)})
+		return yieldStep(L, step{cmd: commandRunner(L, ctx).RunCommand(args)})
 	})
 	jjInteractiveFn := L.NewFunction(func(L *lua.LState) int {
 		args := argsFromLua(L)
-		return yieldStep(L, step{cmd: ctx.RunInteractiveCommand(args, nil)})
+		return yieldStep(L, step{cmd: commandRunner(L, ctx).RunInteractiveCommand(args, nil)})
 	})
 	jjFn := L.NewFunction(func(L *lua.LState) int {
+		options := optionsFromLua(L)
 		args := argsFromLua(L)
-		out, err := ctx.RunCommandImmediate(args)
-		if err != nil {
-			L.Push(lua.LNil)
-			L.Push(lua.LString(err.Error()))
-			return 2
+		pager := boolVal(options, "pager")
+		// the command runs in place, so jj works anywhere, e.g. in pcall or
+		// in a table.sort comparator
+		resume := pauseWatch(L)
+		result := runJJ(ctx, args, pager, false)
+		resume()
+		if result.err == nil && pager && L.Parent != nil {
+			// the pager shows the colours, the script gets the text
+			return yieldStep(L, step{cmd: showPager(args, result), values: result.values()})
+		}
+		return pushJJResult(L, result)
+	})
+	// jj_wait runs the command off the main loop and waits for it, where
+	// the before_command hooks see it. Like the other calls that wait, it
+	// can't be made from pcall or from callbacks like table.sort's.
+	jjWaitFn := L.NewFunction(func(L *lua.LState) int {
+		options := optionsFromLua(L)
+		args := argsFromLua(L)
+		pager := boolVal(options, "pager")
+		id := lastJJCallID.Add(1)
+		run := func(args []string) tea.Cmd {
+			return func() tea.Msg {
+				result := runJJ(ctx, args, pager, true)
+				result.id = id
+				if result.err == nil && pager {
+					return tea.BatchMsg{showPager(args, result), func() tea.Msg { return result }}
+				}
+				return result
+			}
 		}
-		L.Push(lua.LString(out))
-		L.Push(lua.LNil)
-		return 2
+		stopped := func(err error) tea.Cmd {
+			return func() tea.Msg { return jjResultMsg{id: id, err: err} }
+		}
+		var cmd tea.Cmd
+		if unhooked(L) {
+			cmd = run(args)
+		} else {
+			// scripts run commands for the user, which the hooks get to see
+			cmd = ctx.BeforeCommand(args, false, run, stopped)
+		}
+		return yieldStep(L, step{cmd: cmd, matcher: func(msg tea.Msg) (bool, []lua.LValue) {
+			result, ok := msg.(jjResultMsg)
+			if !ok || result.id != id {
+				return false, nil
+			}
+			return true, result.values()
+		}})
 	})
 	flashFn := L.NewFunction(func(L *lua.LState) int {
 		intent := intents.AddMessage{}
@@ -410,6 +557,7 @@ func registerAPI(L *lua.LState, ctx *uicontext.MainContext) {
 	root.RawSetString("jj_async", jjAsyncFn)
 	root.RawSetString("jj_interactive", jjInteractiveFn)
 	root.RawSetString("jj", jjFn)
+	root.RawSetString("jj_wait", jjWaitFn)
 	root.RawSetString("flash", flashFn)
 	root.RawSetString("copy_to_clipboard", copyToClipboardFn)
 	root.RawSetString("exec_shell", execShellFn)
@@ -418,9 +566,13 @@ func registerAPI(L *lua.LState, ctx *uicontext.MainContext) {
 	root.RawSetString("input", inputFn)
 	root.RawSetString("wait_close", waitCloseFn)
 	root.RawSetString("wait_refresh", waitRefreshFn)
+	registerHookAPI(L, root)
+	registerTimerAPI(L, root)
+	registerStoreAPI(L, ctx, root)
 	builtinRoot := L.NewTable()
 	root.RawSetString("builtin", builtinRoot)
 	registerGeneratedActionAPI(L, root, false)
+	registerPreviewAPI(L, root)
 	registerGeneratedActionAPI(L, builtinRoot, true)
 	L.SetGlobal("jjui", root)
 
@@ -437,6 +589,7 @@ func registerAPI(L *lua.LState, ctx *uicontext.MainContext) {
 	L.SetGlobal("jj_async", jjAsyncFn)
 	L.SetGlobal("jj_interactive", jjInteractiveFn)
 	L.SetGlobal("jj", jjFn)
+	L.SetGlobal("jj_wait", jjWaitFn)
 	L.SetGlobal("flash", flashFn)
 	L.SetGlobal("copy_to_clipboard", copyToClipboardFn)
 	L.SetGlobal("exec_shell", execShellFn)
@@ -575,16 +728,41 @@ func argsFromLua(L *lua.LState) []string {
 	var out []string
 	top := L.GetTop()
 	for i := 1; i <= top; i++ {
+		if _, ok := L.Get(i).(*lua.LTable); ok {
+			out = append(out, luaString(L.Get(i)))
+			continue
+		}
 		out = append(out, L.CheckString(i))
 	}
 	return out
 }
 
+// optionsFromLua takes the table of options that follows the arguments of a
+// call, e.g. jj({"log"}, {pager = true}), off the stack.
+func optionsFromLua(L *lua.LState) map[string]any {
+	top := L.GetTop()
+	if top < 2 {
+		return nil
+	}
+	tbl, ok := L.Get(top).(*lua.LTable)
+	if !ok {
+		return nil
+	}
+	L.Remove(top)
+	return luaTableToMap(tbl)
+}
+
 func stringSliceFromTable(tbl *lua.LTable) []string {
 	var out []string
 	tbl.ForEach(func(_, value lua.LValue) {
-		if s, ok := value.(lua.LString); ok {
-			out = append(out, s.String())
+		switch value := value.(type) {
+		case lua.LString:
+			out = append(out, value.String())
+		case *lua.LTable:
+			// revisions stand for their change ids
+			if changeId, ok := value.RawGetString("change_id").(lua.LString); ok {
+				out = append(out, changeId.String())
+			}
 		}
 	})
 	return out
@@ -635,6 +813,63 @@ func luaValueToGo(value lua.LValue) any {
 	}
 }
 
+// jjResultMsg is the outcome of a jjui.jj or jjui.jj_wait call.
+type jjResultMsg struct {
+	id  int64
+	out []byte
+	err error
+	// pager strips the colours the pager was given from the output.
+	pager bool
+}
+
+var lastJJCallID atomic.Int64
+
+// runJJ runs a jjui.jj or jjui.jj_wait call. Scripts may run anything,
+// including commands that write to the repository, which wait for their turn
+// in the queue. jjui.jj runs on the UI loop, which can't wait, so it fails
+// while the queue is busy instead.
+func runJJ(ctx *uicontext.MainContext, args []string, pager bool, wait bool) jjResultMsg {
+	if pager {
+		args = append([]string{"--color", "always"}, args...)
+	}
+	run := ctx.RunCommandImmediateIfIdle
+	if wait {
+		run = ctx.RunCommandImmediateWithLimit
+	}
+	out, err := run(args, config.CommandClassMutation, maxScriptOutputSize)
+	return jjResultMsg{out: out, err: err, pager: pager}
+}
+
+// values are what jjui.jj returns: the output, or nil and the error.
+func (r jjResultMsg) values() []lua.LValue {
+	if r.err != nil {
+		text := r.err.Error()
+		if r.pager {
+			text = ansi.Strip(text)
+		}
+		return []lua.LValue{lua.LNil, lua.LString(text)}
+	}
+	out := string(r.out)
+	if r.pager {
+		out = ansi.Strip(out)
+	}
+	return []lua.LValue{lua.LString(out), lua.LNil}
+}
+
+// showPager shows the output of a call made with the pager option.
+func showPager(args []string, result jjResultMsg) tea.Cmd {
+	return func() tea.Msg {
+		return common.ShowPagerMsg{Title: "jj " + strings.Join(args, " "), Content: string(result.out)}
+	}
+}
+
+func pushJJResult(L *lua.LState, result jjResultMsg) int {
+	for _, value := range result.values() {
+		L.Push(value)
+	}
+	return 2
+}
+
 func yieldStep(L *lua.LState, st step) int {
 	ud := L.NewUserData()
 	ud.Value = st
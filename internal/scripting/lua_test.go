@@ -3,6 +3,8 @@ package scripting
 import (
 	"testing"
 
+	tea "charm.land/bubbletea/v2"
+
 	lua "github.com/yuin/gopher-lua"
 
 	"github.com/stretchr/testify/assert"
@@ -10,6 +12,7 @@ import (
 
 	"github.com/idursun/jjui/internal/ui/common"
 	uicontext "github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/test"
 )
 
 func strPtr(v string) *string {
@@ -420,3 +423,89 @@ func TestRunner_WaitRefreshResumesOnUpdateRevisions(t *testing.T) {
 		})
 	}
 }
+
+// runnerModel feeds a runner the messages of the commands it started.
+type runnerModel struct {
+	*Runner
+}
+
+func (m runnerModel) Update(msg tea.Msg) tea.Cmd {
+	return m.HandleMsg(msg)
+}
+
+func TestJj_PagerOptionShowsOutputAndReturnsText(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect([]string{"--color", "always", "log", "-r", "@"}).SetOutput([]byte("\x1b[1mchange\x1b[0m"))
+	defer commandRunner.Verify()
+
+	ctx := setupVM(t)
+	ctx.CommandRunner = commandRunner
+	runner, cmd, err := RunScript(ctx, `out, err = jj({"log", "-r", "@"}, {pager = true})`)
+	require.NoError(t, err)
+	require.NotNil(t, cmd)
+
+	var pager []tea.Msg
+	test.SimulateModel(runnerModel{runner}, cmd, func(msg tea.Msg) {
+		if _, ok := msg.(common.ShowPagerMsg); ok {
+			pager = append(pager, msg)
+		}
+	})
+	assert.True(t, runner.Done())
+	assert.Equal(t, []tea.Msg{common.ShowPagerMsg{Title: "jj log -r @", Content: "\x1b[1mchange\x1b[0m"}}, pager)
+	assert.Equal(t, "change", ctx.ScriptVM.GetGlobal("out").String())
+	assert.Equal(t, lua.LNil, ctx.ScriptVM.GetGlobal("err"))
+}
+
+func TestJj_RunsInPlaceInsidePcallAndCallbacks(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect([]string{"log", "-r", "a"}).SetOutput([]byte("2"))
+	commandRunner.Expect([]string{"log", "-r", "b"}).SetOutput([]byte("1"))
+	commandRunner.Expect([]string{"log", "-r", "@"}).SetOutput([]byte("change"))
+	defer commandRunner.Verify()
+
+	ctx := setupVM(t)
+	ctx.CommandRunner = commandRunner
+	runner, cmd, err := RunScript(ctx, `
+		local ok, value = pcall(function() return jj("log", "-r", "@") end)
+		out = tostring(ok) .. " " .. value
+		local order = {}
+		local revs = {"a", "b"}
+		table.sort(revs, function(x, y)
+			order[x] = order[x] or jj("log", "-r", x)
+			order[y] = order[y] or jj("log", "-r", y)
+			return order[x] < order[y]
+		end)
+		sorted = table.concat(revs, ",")
+	`)
+	require.NoError(t, err)
+	test.SimulateModel(runnerModel{runner}, cmd)
+	assert.True(t, runner.Done())
+	assert.Equal(t, "true change", ctx.ScriptVM.GetGlobal("out").String())
+	assert.Equal(t, "b,a", ctx.ScriptVM.GetGlobal("sorted").String())
+}
+
+func TestJjWait_WaitsForTheCommand(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect([]string{"log"}).SetOutput([]byte("change"))
+	defer commandRunner.Verify()
+
+	ctx := setupVM(t)
+	ctx.CommandRunner = commandRunner
+	runner, cmd, err := RunScript(ctx, `out, err = jj_wait("log")`)
+	require.NoError(t, err)
+	assert.False(t, runner.Done(), "the script waits for the command")
+	test.SimulateModel(runnerModel{runner}, cmd)
+	assert.True(t, runner.Done())
+	assert.Equal(t, "change", ctx.ScriptVM.GetGlobal("out").String())
+}
+
+func TestJj_RunsInPlaceOutsideRunners(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect([]string{"log"}).SetOutput([]byte("change"))
+	defer commandRunner.Verify()
+
+	ctx := setupVM(t)
+	ctx.CommandRunner = commandRunner
+	require.NoError(t, ctx.ScriptVM.DoString(`out, err = jj("log")`))
+	assert.Equal(t, "change", ctx.ScriptVM.GetGlobal("out").String())
+}
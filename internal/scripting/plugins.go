@@ -0,0 +1,324 @@
+package scripting
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/BurntSushi/toml"
+	"github.com/idursun/jjui/internal/config"
+	uicontext "github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/intents"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// APIVersion is the version of the Lua API plugins are written against. It
+// is raised when the API changes in a way that breaks plugins.
+const APIVersion = 1
+
+const (
+	pluginManifestName = "plugin.toml"
+	pluginRegistryName = "__jjui_plugins"
+	defaultPluginEntry = "init.lua"
+)
+
+// PluginManifest describes a plugin in the plugin.toml of its directory.
+type PluginManifest struct {
+	Name        string `toml:"name"`
+	Version     string `toml:"version"`
+	Description string `toml:"description"`
+	// APIVersion is the version of the Lua API the plugin requires.
+	APIVersion int `toml:"api_version"`
+	// Entry is the script, relative to the directory of the plugin, that is
+	// run to load the plugin.
+	Entry string `toml:"entry"`
+}
+
+// Plugin is a plugin found in the plugins directory, and how loading it went.
+type Plugin struct {
+	PluginManifest
+	Dir     string
+	Enabled bool
+	Loaded  bool
+	Err     error
+}
+
+// pluginState is what is remembered about plugins between sessions.
+type pluginState struct {
+	Disabled []string `json:"disabled"`
+}
+
+func pluginStatePath() string {
+	return filepath.Join(config.GetStateDir(), "plugins.json")
+}
+
+func loadPluginState() pluginState {
+	var state pluginState
+	if data, err := os.ReadFile(pluginStatePath()); err == nil {
+		_ = json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+// DiscoverPlugins finds the plugins in the directories of dir that have a
+// manifest. A plugin whose manifest can't be read is returned with the error.
+func DiscoverPlugins(dir string) []*Plugin {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	state := loadPluginState()
+	var plugins []*Plugin
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		pluginDir := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(filepath.Join(pluginDir, pluginManifestName))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		plugin := &Plugin{Dir: pluginDir}
+		if err == nil {
+			_, err = toml.Decode(string(data), &plugin.PluginManifest)
+		}
+		if plugin.Name == "" {
+			plugin.Name = entry.Name()
+		}
+		if plugin.Entry == "" {
+			plugin.Entry = defaultPluginEntry
+		}
+		plugin.Enabled = !slices.Contains(state.Disabled, plugin.Name)
+		switch {
+		case err != nil:
+			plugin.Err = fmt.Errorf("%s: %w", pluginManifestName, err)
+		case plugin.APIVersion == 0:
+			plugin.Err = fmt.Errorf("%s: api_version is required", pluginManifestName)
+		case plugin.APIVersion > APIVersion:
+			plugin.Err = fmt.Errorf("requires API version %d, but jjui provides %d", plugin.APIVersion, APIVersion)
+		case slices.ContainsFunc(plugins, func(p *Plugin) bool { return p.Name == plugin.Name }):
+			plugin.Err = fmt.Errorf("another plugin is named %q", plugin.Name)
+		}
+		plugins = append(plugins, plugin)
+	}
+	return plugins
+}
+
+// LoadPlugins loads the enabled plugins in dir one by one. A plugin that
+// fails to load leaves the config as it was, and the rest are loaded anyway;
+// its error is kept with it for the plugin manager.
+func LoadPlugins(ctx *uicontext.MainContext, current *config.Config, dir string) []*Plugin {
+	L, err := vmFromContext(ctx)
+	if err != nil {
+		return nil
+	}
+	plugins := DiscoverPlugins(dir)
+	for _, plugin := range plugins {
+		if plugin.Err != nil || !plugin.Enabled {
+			continue
+		}
+		if err := loadPlugin(L, ctx, current, plugin); err != nil {
+			plugin.Err = err
+			continue
+		}
+		plugin.Loaded = true
+	}
+	ud := L.NewUserData()
+	ud.Value = plugins
+	L.SetGlobal(pluginRegistryName, ud)
+	return plugins
+}
+
+// pluginRegistries are the globals the API keeps what scripts register in.
+var pluginRegistries = []string{
+	hookRegistryName,
+	previewProviderRegistryName,
+	decoratorRegistryName,
+	actionRegistryName,
+	actionCounterName,
+}
+
+// vmSnapshot is what loading a plugin may change in the VM, so that a plugin
+// that fails to load leaves nothing of itself behind.
+type vmSnapshot struct {
+	registries  map[string]lua.LValue
+	packagePath lua.LValue
+	loaded      map[lua.LValue]bool
+	lastTimer   int
+}
+
+func snapshotVM(L *lua.LState, ctx *uicontext.MainContext) *vmSnapshot {
+	s := &vmSnapshot{registries: make(map[string]lua.LValue), loaded: make(map[lua.LValue]bool)}
+	for _, name := range pluginRegistries {
+		s.registries[name] = copyLuaValue(L, L.GetGlobal(name))
+	}
+	if pkg, ok := L.GetGlobal("package").(*lua.LTable); ok {
+		s.packagePath = pkg.RawGetString("path")
+		if loaded, ok := pkg.RawGetString("loaded").(*lua.LTable); ok {
+			loaded.ForEach(func(key, _ lua.LValue) { s.loaded[key] = true })
+		}
+	}
+	if timers := schedulerOf(ctx); timers != nil {
+		s.lastTimer = timers.nextId
+	}
+	return s
+}
+
+// restore puts the registries back as they were and drops the modules and
+// timers added since.
+func (s *vmSnapshot) restore(L *lua.LState, ctx *uicontext.MainContext) {
+	for name, value := range s.registries {
+		L.SetGlobal(name, value)
+	}
+	if pkg, ok := L.GetGlobal("package").(*lua.LTable); ok {
+		pkg.RawSetString("path", s.packagePath)
+		if loaded, ok := pkg.RawGetString("loaded").(*lua.LTable); ok {
+			var added []lua.LValue
+			loaded.ForEach(func(key, _ lua.LValue) {
+				if !s.loaded[key] {
+					added = append(added, key)
+				}
+			})
+			for _, key := range added {
+				loaded.RawSet(key, lua.LNil)
+			}
+		}
+	}
+	if timers := schedulerOf(ctx); timers != nil {
+		for id := range timers.tasks {
+			if id > s.lastTimer {
+				timers.cancel(id)
+			}
+		}
+	}
+}
+
+// copyLuaValue copies tables and the tables in them; other values are shared.
+func copyLuaValue(L *lua.LState, value lua.LValue) lua.LValue {
+	tbl, ok := value.(*lua.LTable)
+	if !ok {
+		return value
+	}
+	copied := L.NewTable()
+	tbl.ForEach(func(key, value lua.LValue) {
+		copied.RawSet(key, copyLuaValue(L, value))
+	})
+	return copied
+}
+
+// loadPlugin runs the entry script of the plugin in an environment of its
+// own, so that its globals don't leak into other scripts, and calls the
+// setup function it returns in a table or defines. What a plugin that fails
+// registered is dropped, and the config is left as it was.
+func loadPlugin(L *lua.LState, ctx *uicontext.MainContext, current *config.Config, plugin *Plugin) (err error) {
+	snapshot := snapshotVM(L, ctx)
+	defer func() {
+		if err != nil {
+			snapshot.restore(L, ctx)
+		}
+	}()
+	source, err := os.ReadFile(filepath.Join(plugin.Dir, plugin.Entry))
+	if err != nil {
+		return err
+	}
+	// errors are reported at the plugin/entry.lua:line they are raised at
+	fn, err := L.Load(bytes.NewReader(source), plugin.Name+"/"+plugin.Entry)
+	if err != nil {
+		return err
+	}
+	// the modules of the plugin can be required by their name
+	if err := prependConfigModulePaths(L, plugin.Dir); err != nil {
+		return err
+	}
+	env := L.NewTable()
+	mt := L.NewTable()
+	mt.RawSetString("__index", L.Get(lua.GlobalsIndex))
+	L.SetMetatable(env, mt)
+	L.SetFEnv(fn, env)
+
+	if err := callWithLimits(L, lua.P{Fn: fn, NRet: 1, Protect: true}); err != nil {
+		return err
+	}
+	module := L.Get(-1)
+	L.Pop(1)
+
+	setupFn := env.RawGetString("setup")
+	if tbl, ok := module.(*lua.LTable); ok {
+		setupFn = tbl.RawGetString("setup")
+	}
+	if setupFn == lua.LNil {
+		return nil
+	}
+	setup, ok := setupFn.(*lua.LFunction)
+	if !ok {
+		return fmt.Errorf("%s: setup is not a function", plugin.Entry)
+	}
+
+	configTable := newConfigTable(L, ctx, current)
+	if err := callWithLimits(L, lua.P{Fn: setup, NRet: 0, Protect: true}, configTable); err != nil {
+		return fmt.Errorf("setup(): %w", err)
+	}
+	// the changes are checked on a copy, so that a plugin with invalid
+	// bindings doesn't break the config for everyone else
+	next := current.Clone()
+	if err := fromLuaTable(configTable, next); err != nil {
+		return fmt.Errorf("setup(): %w", err)
+	}
+	if err := next.ValidateBindingsAndActions(); err != nil {
+		return fmt.Errorf("setup(): %w", err)
+	}
+	*current = *next
+	return nil
+}
+
+// Plugins returns the plugins found when the VM was set up.
+func Plugins(ctx *uicontext.MainContext) []*Plugin {
+	if ctx == nil || ctx.ScriptVM == nil {
+		return nil
+	}
+	ud, ok := ctx.ScriptVM.GetGlobal(pluginRegistryName).(*lua.LUserData)
+	if !ok {
+		return nil
+	}
+	plugins, _ := ud.Value.([]*Plugin)
+	return plugins
+}
+
+// ReportPluginErrors flashes an error for each plugin that failed to load.
+func ReportPluginErrors(ctx *uicontext.MainContext) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, plugin := range Plugins(ctx) {
+		if plugin.Err == nil {
+			continue
+		}
+		err := fmt.Errorf("plugin %s: %w", plugin.Name, plugin.Err)
+		cmds = append(cmds, intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err}))
+	}
+	return tea.Batch(cmds...)
+}
+
+// SetPluginEnabled enables or disables the plugin from the next start on.
+func SetPluginEnabled(plugin *Plugin, enabled bool) error {
+	state := loadPluginState()
+	state.Disabled = slices.DeleteFunc(state.Disabled, func(name string) bool {
+		return name == plugin.Name
+	})
+	if !enabled {
+		state.Disabled = append(state.Disabled, plugin.Name)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(pluginStatePath(), data); err != nil {
+		return err
+	}
+	plugin.Enabled = enabled
+	return nil
+}
@@ -0,0 +1,221 @@
+package scripting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lua "github.com/yuin/gopher-lua"
+)
+
+func writePlugin(t *testing.T, dir string, name string, manifest string, files map[string]string) {
+	t.Helper()
+	pluginDir := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(pluginDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, "plugin.toml"), []byte(manifest), 0o644))
+	for file, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(pluginDir, file), []byte(content), 0o644))
+	}
+}
+
+func TestLoadPlugins_RunsSetupInItsOwnEnvironment(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	writePlugin(t, dir, "greeter", `
+name = "greeter"
+version = "1.0.0"
+description = "Says hello"
+api_version = 1
+`, map[string]string{
+		"init.lua": `
+local util = require("util")
+greeting = util.greeting
+
+local M = {}
+function M.setup(config)
+  config.action("greet", function()
+    jjui.flash(greeting)
+  end, { key = "G", scope = "revisions" })
+end
+return M
+`,
+		"util.lua": `return { greeting = "hello" }`,
+	})
+	// the entry script is set in the manifest, and setup can be a global
+	writePlugin(t, dir, "other", `
+api_version = 1
+entry = "main.lua"
+`, map[string]string{
+		"main.lua": `
+function setup(config)
+  config.ui.colors["plugin"] = { fg = "red" }
+end
+`,
+	})
+
+	ctx := setupVM(t)
+	cfg := *config.Current
+	plugins := LoadPlugins(ctx, &cfg, dir)
+
+	require.Len(t, plugins, 2)
+	for _, plugin := range plugins {
+		assert.NoError(t, plugin.Err, plugin.Name)
+		assert.True(t, plugin.Loaded, plugin.Name)
+	}
+	assert.Equal(t, "greeter", plugins[0].Name)
+	assert.Equal(t, "1.0.0", plugins[0].Version)
+	assert.Equal(t, "other", plugins[1].Name)
+	assert.Equal(t, plugins, Plugins(ctx))
+
+	_, ok := findActionByName(cfg.Actions, "greet")
+	assert.True(t, ok)
+	assert.Contains(t, cfg.UI.Colors, "plugin")
+	assert.NotContains(t, config.Current.UI.Colors, "plugin")
+
+	// globals of plugins don't leak into the other scripts
+	assert.Equal(t, lua.LNil, ctx.ScriptVM.GetGlobal("greeting"))
+	assert.Equal(t, lua.LNil, ctx.ScriptVM.GetGlobal("setup"))
+}
+
+func TestLoadPlugins_FailingPluginDoesNotStopOthers(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	writePlugin(t, dir, "a_broken", `api_version = 1`, map[string]string{
+		"init.lua": `error("boom")`,
+	})
+	writePlugin(t, dir, "b_invalid", `api_version = 1`, map[string]string{
+		"init.lua": `
+return {
+  setup = function(config)
+    config.ui.colors["invalid"] = { fg = "red" }
+    config.bind({ action = "missing", key = "x" })
+  end,
+}
+`,
+	})
+	writePlugin(t, dir, "c_working", `api_version = 1`, map[string]string{
+		"init.lua": `
+return {
+  setup = function(config)
+    config.ui.colors["working"] = { fg = "green" }
+  end,
+}
+`,
+	})
+
+	ctx := setupVM(t)
+	cfg := *config.Current
+	plugins := LoadPlugins(ctx, &cfg, dir)
+
+	require.Len(t, plugins, 3)
+	require.Error(t, plugins[0].Err)
+	assert.Contains(t, plugins[0].Err.Error(), "boom")
+	assert.False(t, plugins[0].Loaded)
+	require.Error(t, plugins[1].Err)
+	assert.False(t, plugins[1].Loaded)
+	assert.NoError(t, plugins[2].Err)
+	assert.True(t, plugins[2].Loaded)
+
+	assert.NotContains(t, cfg.UI.Colors, "invalid")
+	assert.Contains(t, cfg.UI.Colors, "working")
+	assert.NoError(t, cfg.ValidateBindingsAndActions())
+}
+
+func TestLoadPlugins_FailingPluginLeavesNothingBehind(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	writePlugin(t, dir, "leaky", `api_version = 1`, map[string]string{
+		"helper.lua": `return {}`,
+		"init.lua": `
+require("helper")
+jjui.on("refresh_completed", function() end)
+jjui.preview.register("revision", function() return "leaked" end)
+jjui.revisions.decorate(function() end)
+jjui.set_timeout(function() end, 60000)
+return {
+  setup = function(config)
+    config.action("leaked", function() end)
+    error("setup failed")
+  end,
+}
+`,
+	})
+
+	ctx := setupVM(t)
+	L := ctx.ScriptVM
+	path := L.GetField(L.GetGlobal("package"), "path").String()
+	cfg := *config.Current
+	plugins := LoadPlugins(ctx, &cfg, dir)
+
+	require.Len(t, plugins, 1)
+	require.Error(t, plugins[0].Err)
+	assert.False(t, HasHooks(ctx, HookRefreshCompleted))
+	assert.False(t, HasPreviewProvider(ctx, common.SelectedRevision{ChangeId: "abc"}))
+	assert.Nil(t, decoratorsOf(ctx))
+	assert.Empty(t, schedulerOf(ctx).tasks)
+	assert.Equal(t, path, L.GetField(L.GetGlobal("package"), "path").String())
+	assert.Equal(t, lua.LNil, L.GetField(L.GetField(L.GetGlobal("package"), "loaded"), "helper"))
+	registry, _ := L.GetGlobal(actionRegistryName).(*lua.LTable)
+	if registry != nil {
+		assert.Equal(t, lua.LNil, registry.RawGetString("action_1"))
+	}
+}
+
+func TestDiscoverPlugins_ChecksManifests(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	writePlugin(t, dir, "future", `api_version = 99`, nil)
+	writePlugin(t, dir, "unversioned", `name = "unversioned"`, nil)
+	writePlugin(t, dir, "malformed", `name = `, nil)
+	writePlugin(t, dir, "first", `name = "same"
+api_version = 1`, nil)
+	writePlugin(t, dir, "second", `name = "same"
+api_version = 1`, nil)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "no_manifest"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "loose.lua"), []byte(`return {}`), 0o644))
+
+	plugins := DiscoverPlugins(dir)
+
+	errs := map[string]string{}
+	for _, plugin := range plugins {
+		if plugin.Err != nil {
+			errs[filepath.Base(plugin.Dir)] = plugin.Err.Error()
+		}
+	}
+	assert.Len(t, plugins, 5)
+	assert.Len(t, errs, 4)
+	assert.Contains(t, errs["future"], "requires API version 99")
+	assert.Contains(t, errs["unversioned"], "api_version is required")
+	assert.Contains(t, errs["malformed"], "plugin.toml")
+	assert.Contains(t, errs["second"], `another plugin is named "same"`)
+	assert.NotContains(t, errs, "first")
+}
+
+func TestSetPluginEnabled_PersistsAcrossStarts(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	writePlugin(t, dir, "toggled", `api_version = 1`, map[string]string{
+		"init.lua": `return { setup = function(config) config.ui.colors["toggled"] = { fg = "red" } end }`,
+	})
+
+	plugins := DiscoverPlugins(dir)
+	require.Len(t, plugins, 1)
+	assert.True(t, plugins[0].Enabled)
+	require.NoError(t, SetPluginEnabled(plugins[0], false))
+	assert.False(t, plugins[0].Enabled)
+
+	ctx := setupVM(t)
+	cfg := *config.Current
+	plugins = LoadPlugins(ctx, &cfg, dir)
+	require.Len(t, plugins, 1)
+	assert.False(t, plugins[0].Enabled)
+	assert.False(t, plugins[0].Loaded)
+	assert.NotContains(t, cfg.UI.Colors, "toggled")
+
+	require.NoError(t, SetPluginEnabled(plugins[0], true))
+	assert.True(t, DiscoverPlugins(dir)[0].Enabled)
+}
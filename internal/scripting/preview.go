@@ -0,0 +1,139 @@
+package scripting
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/ui/common"
+	uicontext "github.com/idursun/jjui/internal/ui/context"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const previewProviderRegistryName = "__jjui_preview_providers"
+
+// The kinds of items preview providers are registered for with
+// jjui.preview.register(kind, fn).
+var previewKinds = []string{"revision", "file", "commit", "operation"}
+
+// PreviewResultMsg carries what a preview provider gave for an item.
+type PreviewResultMsg struct {
+	Item common.SelectedItem
+	// Handled is false when the provider returned nil, so that the configured
+	// preview command is run instead.
+	Handled bool
+	Content string
+	// Args is the jj command the provider delegated to, which is run for the
+	// content instead.
+	Args []string
+}
+
+func registerPreviewAPI(L *lua.LState, root *lua.LTable) {
+	previewTable := ensureOwnerTable(L, root, "preview")
+	previewTable.RawSetString("register", L.NewFunction(func(L *lua.LState) int {
+		kind := L.CheckString(1)
+		fn := L.CheckFunction(2)
+		if !slices.Contains(previewKinds, kind) {
+			L.ArgError(1, fmt.Sprintf("unknown kind %q (expected one of: %s)", kind, strings.Join(previewKinds, ", ")))
+			return 0
+		}
+		registry, ok := L.GetGlobal(previewProviderRegistryName).(*lua.LTable)
+		if !ok {
+			registry = L.NewTable()
+			L.SetGlobal(previewProviderRegistryName, registry)
+		}
+		// a later provider replaces the earlier one of the same kind
+		registry.RawSetString(kind, fn)
+		return 0
+	}))
+}
+
+func previewKindOf(item common.SelectedItem) string {
+	switch item.(type) {
+	case common.SelectedRevision:
+		return "revision"
+	case common.SelectedFile:
+		return "file"
+	case common.SelectedCommit:
+		return "commit"
+	case common.SelectedOperation:
+		return "operation"
+	}
+	return ""
+}
+
+func previewProviderOf(ctx *uicontext.MainContext, item common.SelectedItem) *lua.LFunction {
+	if ctx == nil || ctx.ScriptVM == nil {
+		return nil
+	}
+	registry, ok := ctx.ScriptVM.GetGlobal(previewProviderRegistryName).(*lua.LTable)
+	if !ok {
+		return nil
+	}
+	fn, _ := registry.RawGetString(previewKindOf(item)).(*lua.LFunction)
+	return fn
+}
+
+// HasPreviewProvider reports whether a preview provider is registered for
+// the kind of the item.
+func HasPreviewProvider(ctx *uicontext.MainContext, item common.SelectedItem) bool {
+	return previewProviderOf(ctx, item) != nil
+}
+
+// RunPreviewProvider starts the preview provider of the item, which answers
+// with a PreviewResultMsg. Providers run like other scripts: the jj commands
+// they run don't hold up the main loop, and the runner returned while the
+// provider waits for one has to be given messages until it is done.
+func RunPreviewProvider(ctx *uicontext.MainContext, item common.SelectedItem, width int, height int) (*Runner, tea.Cmd) {
+	fn := previewProviderOf(ctx, item)
+	if fn == nil {
+		return nil, func() tea.Msg { return PreviewResultMsg{Item: item} }
+	}
+	L := ctx.ScriptVM
+	payload := selectedItemToLua(L, item)
+	payload.RawSetString("revset", lua.LString(ctx.CurrentRevset))
+	payload.RawSetString("width", lua.LNumber(width))
+	payload.RawSetString("height", lua.LNumber(height))
+	r := newRunner(ctx, L, "", fn, func(values []lua.LValue, err error) tea.Cmd {
+		result := previewResult(item, values, err)
+		return func() tea.Msg { return result }
+	}, payload)
+	// the preview shows the repository, its commands aren't the user's
+	r.skipHooks()
+	cmd := r.start()
+	if r.Done() {
+		return nil, cmd
+	}
+	return r, cmd
+}
+
+// previewResult reads what a provider returned: a string of content, a table
+// with either content or the command to run, or nil to leave the preview to
+// the configured command.
+func previewResult(item common.SelectedItem, values []lua.LValue, err error) PreviewResultMsg {
+	result := PreviewResultMsg{Item: item, Handled: true}
+	if err != nil {
+		result.Content = err.Error()
+		return result
+	}
+	if len(values) == 0 {
+		result.Handled = false
+		return result
+	}
+	switch value := values[0].(type) {
+	case lua.LString:
+		result.Content = value.String()
+	case *lua.LTable:
+		if command, ok := value.RawGetString("command").(*lua.LTable); ok {
+			result.Args = stringSliceFromTable(command)
+		} else {
+			result.Content = luaString(value.RawGetString("content"))
+		}
+	case *lua.LNilType:
+		result.Handled = false
+	default:
+		result.Content = lua.LVAsString(value)
+	}
+	return result
+}
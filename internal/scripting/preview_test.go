@@ -0,0 +1,80 @@
+package scripting
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/test"
+)
+
+type ignoreMsgs struct{}
+
+func (ignoreMsgs) Update(tea.Msg) tea.Cmd { return nil }
+
+func TestPreviewRegister_RejectsUnknownKinds(t *testing.T) {
+	ctx := setupVM(t)
+	err := ctx.ScriptVM.DoString(`jjui.preview.register("bookmark", function() end)`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown kind "bookmark"`)
+}
+
+func TestRunPreviewProvider_OnlyForRegisteredKinds(t *testing.T) {
+	ctx := setupVM(t)
+	require.NoError(t, ctx.ScriptVM.DoString(`jjui.preview.register("operation", function(item) return item.operation_id end)`))
+
+	assert.False(t, HasPreviewProvider(ctx, common.SelectedRevision{ChangeId: "abc"}))
+	assert.True(t, HasPreviewProvider(ctx, common.SelectedOperation{OperationId: "0a1b"}))
+
+	runner, cmd := RunPreviewProvider(ctx, common.SelectedOperation{OperationId: "0a1b"}, 80, 20)
+	assert.Nil(t, runner)
+	result, ok := cmd().(PreviewResultMsg)
+	require.True(t, ok)
+	assert.True(t, result.Handled)
+	assert.Equal(t, "0a1b", result.Content)
+}
+
+func TestRunPreviewProvider_ShowsErrors(t *testing.T) {
+	ctx := setupVM(t)
+	require.NoError(t, ctx.ScriptVM.DoString(`jjui.preview.register("commit", function(item) error("no cache") end)`))
+
+	_, cmd := RunPreviewProvider(ctx, common.SelectedCommit{CommitId: "123"}, 80, 20)
+	require.NotNil(t, cmd)
+	var result PreviewResultMsg
+	test.SimulateModel(ignoreMsgs{}, cmd, func(msg tea.Msg) {
+		if r, ok := msg.(PreviewResultMsg); ok {
+			result = r
+		}
+	})
+	assert.True(t, result.Handled)
+	assert.Contains(t, result.Content, "no cache")
+}
+
+func TestRunPreviewProvider_WaitsForCommands(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect([]string{"log", "-r", "abc"}).SetOutput([]byte("described"))
+	defer commandRunner.Verify()
+	ctx := setupVM(t)
+	ctx.CommandRunner = commandRunner
+	installCommandHooks(ctx)
+	require.NoError(t, ctx.ScriptVM.DoString(`
+		jjui.on("before_command", function() return false end)
+		jjui.preview.register("revision", function(item)
+			return jj_wait("log", "-r", item.change_id)
+		end)
+	`))
+
+	runner, cmd := RunPreviewProvider(ctx, common.SelectedRevision{ChangeId: "abc"}, 80, 20)
+	require.NotNil(t, runner, "the provider waits for the command off the main loop")
+	var result PreviewResultMsg
+	test.SimulateModel(runnerModel{runner}, cmd, func(msg tea.Msg) {
+		if r, ok := msg.(PreviewResultMsg); ok {
+			result = r
+		}
+	})
+	assert.True(t, runner.Done())
+	assert.Equal(t, "described", result.Content, "the preview commands skip the hooks")
+}
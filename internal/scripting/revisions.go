@@ -0,0 +1,184 @@
+package scripting
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/idursun/jjui/internal/jj"
+	uicontext "github.com/idursun/jjui/internal/ui/context"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const revisionMetatableName = "jjui.revision"
+
+// loadRevisions loads the details of the commits with a single jj call and
+// returns them in the same order. The ids of the commits are kept, so that
+// they are the ones the log shows.
+func loadRevisions(ctx *uicontext.MainContext, commits []*jj.Commit) ([]jj.RevisionDetails, error) {
+	if len(commits) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		// commit ids are never ambiguous, unlike the ids of divergent changes
+		id := commit.CommitId
+		if id == "" {
+			id = commit.ChangeId
+		}
+		ids = append(ids, id)
+	}
+	output, err := ctx.RunCommandImmediate(jj.RevisionDetailsList(strings.Join(ids, "|")))
+	if err != nil {
+		return nil, err
+	}
+	loaded := jj.ParseRevisionDetails(string(output))
+	revisions := make([]jj.RevisionDetails, 0, len(commits))
+	for _, commit := range commits {
+		for _, details := range loaded {
+			if matchesCommit(details, commit) {
+				details.ChangeId = commit.ChangeId
+				details.CommitId = commit.CommitId
+				revisions = append(revisions, details)
+				break
+			}
+		}
+	}
+	return revisions, nil
+}
+
+func matchesCommit(details jj.RevisionDetails, commit *jj.Commit) bool {
+	if commit.CommitId != "" {
+		return strings.HasPrefix(details.FullCommitId, commit.CommitId)
+	}
+	return commit.ChangeId != "" && (strings.HasPrefix(details.ChangeId, commit.ChangeId) || strings.HasPrefix(commit.ChangeId, details.ChangeId))
+}
+
+// loadRevision loads the details of the revision the id resolves to.
+func loadRevision(ctx *uicontext.MainContext, id string) (*jj.RevisionDetails, error) {
+	output, err := ctx.RunCommandImmediate(jj.RevisionDetailsList(id))
+	if err != nil {
+		return nil, err
+	}
+	loaded := jj.ParseRevisionDetails(string(output))
+	if len(loaded) == 0 {
+		return nil, fmt.Errorf("revision %q not found", id)
+	}
+	return &loaded[0], nil
+}
+
+// revisionMetatable makes revision tables read as their change ids, so that
+// they can be passed to jj and joined with strings.
+func revisionMetatable(L *lua.LState) *lua.LTable {
+	mt := L.NewTypeMetatable(revisionMetatableName)
+	if mt.RawGetString("__tostring") != lua.LNil {
+		return mt
+	}
+	mt.RawSetString("__tostring", L.NewFunction(func(L *lua.LState) int {
+		L.Push(L.CheckTable(1).RawGetString("change_id"))
+		return 1
+	}))
+	mt.RawSetString("__concat", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LString(luaString(L.Get(1)) + luaString(L.Get(2))))
+		return 1
+	}))
+	return mt
+}
+
+func revisionToLua(L *lua.LState, details jj.RevisionDetails) *lua.LTable {
+	tbl := L.NewTable()
+	tbl.RawSetString("change_id", lua.LString(details.ChangeId))
+	tbl.RawSetString("commit_id", lua.LString(details.CommitId))
+	tbl.RawSetString("description", lua.LString(details.Description))
+	author := L.NewTable()
+	author.RawSetString("name", lua.LString(details.AuthorName))
+	author.RawSetString("email", lua.LString(details.AuthorEmail))
+	tbl.RawSetString("author", author)
+	tbl.RawSetString("timestamp", lua.LString(details.Timestamp))
+	tbl.RawSetString("bookmarks", stringsToLuaTable(L, details.Bookmarks))
+	tbl.RawSetString("parents", stringsToLuaTable(L, details.Parents))
+	tbl.RawSetString("empty", lua.LBool(details.Empty))
+	tbl.RawSetString("conflict", lua.LBool(details.Conflict))
+	tbl.RawSetString("immutable", lua.LBool(details.Immutable))
+	tbl.RawSetString("working_copy", lua.LBool(details.WorkingCopy))
+	L.SetMetatable(tbl, revisionMetatable(L))
+	return tbl
+}
+
+// pushRevisions pushes the revisions as a list, or nil and the error.
+func pushRevisions(L *lua.LState, ctx *uicontext.MainContext, commits []*jj.Commit) int {
+	revisions, err := loadRevisions(ctx, commits)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	tbl := L.NewTable()
+	for _, details := range revisions {
+		tbl.Append(revisionToLua(L, details))
+	}
+	L.Push(tbl)
+	return 1
+}
+
+// pushRevision pushes the revision, or nil and the error.
+func pushRevision(L *lua.LState, ctx *uicontext.MainContext, commit *jj.Commit) int {
+	revisions, err := loadRevisions(ctx, []*jj.Commit{commit})
+	if err == nil && len(revisions) == 0 {
+		err = fmt.Errorf("revision %q not found", commit.GetChangeId())
+	}
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	L.Push(revisionToLua(L, revisions[0]))
+	return 1
+}
+
+// luaString is the value as a string; revisions are their change ids.
+func luaString(value lua.LValue) string {
+	if tbl, ok := value.(*lua.LTable); ok {
+		if changeId, ok := tbl.RawGetString("change_id").(lua.LString); ok {
+			return changeId.String()
+		}
+	}
+	return lua.LVAsString(value)
+}
+
+func registerRevisionAPI(L *lua.LState, ctx *uicontext.MainContext, revisionsTable *lua.LTable) {
+	revisionsTable.RawSetString("current", L.NewFunction(func(L *lua.LState) int {
+		rev, ok := ctx.SelectedItem.(uicontext.SelectedRevision)
+		if !ok {
+			return 0
+		}
+		return pushRevision(L, ctx, &jj.Commit{ChangeId: rev.ChangeId, CommitId: rev.CommitId})
+	}))
+	revisionsTable.RawSetString("checked", L.NewFunction(func(L *lua.LState) int {
+		var commits []*jj.Commit
+		for _, item := range ctx.CheckedItems {
+			if rev, ok := item.(uicontext.SelectedRevision); ok {
+				commits = append(commits, &jj.Commit{ChangeId: rev.ChangeId, CommitId: rev.CommitId})
+			}
+		}
+		return pushRevisions(L, ctx, commits)
+	}))
+	revisionsTable.RawSetString("rows", L.NewFunction(func(L *lua.LState) int {
+		return pushRevisions(L, ctx, ctx.LoadedRevisions)
+	}))
+	revisionsTable.RawSetString("get", L.NewFunction(func(L *lua.LState) int {
+		id := luaString(L.CheckAny(1))
+		for _, commit := range ctx.LoadedRevisions {
+			if commit.ChangeId == id || commit.CommitId == id {
+				return pushRevision(L, ctx, commit)
+			}
+		}
+		details, err := loadRevision(ctx, id)
+		if err != nil {
+			L.Push(lua.LNil)
+			L.Push(lua.LString(err.Error()))
+			return 2
+		}
+		L.Push(revisionToLua(L, *details))
+		return 1
+	}))
+}
@@ -0,0 +1,93 @@
+package scripting
+
+import (
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/idursun/jjui/internal/jj"
+	uicontext "github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/test"
+)
+
+const revisionDetails = "aaaa1111\tkkkkkkkk\taaaa1111\tJane\tjane@example.com\t2026-01-02T03:04:05Z\tmain\tllllllll\tfalse\tfalse\tfalse\ttrue\twip: something\n\x00" +
+	"bbbb2222\tllllllll\tbbbb2222\tJohn\tjohn@example.com\t2026-01-01T03:04:05Z\t\tzzzzzzzz\ttrue\tfalse\ttrue\tfalse\t\x00"
+
+func setupRevisionsVM(t *testing.T, commandRunner *test.CommandRunner) *uicontext.MainContext {
+	t.Helper()
+	ctx := setupVM(t)
+	ctx.CommandRunner = commandRunner
+	ctx.LoadedRevisions = []*jj.Commit{
+		{ChangeId: "kk", CommitId: "aaaa", IsWorkingCopy: true},
+		{ChangeId: "ll", CommitId: "bbbb"},
+	}
+	ctx.SelectedItem = uicontext.SelectedRevision{ChangeId: "kk", CommitId: "aaaa"}
+	return ctx
+}
+
+func TestRevisions_CurrentReturnsDetails(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.RevisionDetailsList("aaaa")).SetOutput([]byte(revisionDetails))
+	defer commandRunner.Verify()
+	ctx := setupRevisionsVM(t, commandRunner)
+
+	require.NoError(t, ctx.ScriptVM.DoString(`
+		local rev = jjui.revisions.current()
+		change_id, description, author = rev.change_id, rev.description, rev.author.email
+		bookmark, parent, working_copy = rev.bookmarks[1], rev.parents[1], rev.working_copy
+		as_string = tostring(rev) .. "/" .. ("-" .. rev)
+		args = {"new", rev}
+	`))
+	L := ctx.ScriptVM
+	assert.Equal(t, "kk", L.GetGlobal("change_id").String())
+	assert.Equal(t, "wip: something", L.GetGlobal("description").String())
+	assert.Equal(t, "jane@example.com", L.GetGlobal("author").String())
+	assert.Equal(t, "main", L.GetGlobal("bookmark").String())
+	assert.Equal(t, "llllllll", L.GetGlobal("parent").String())
+	assert.Equal(t, "true", L.GetGlobal("working_copy").String())
+	assert.Equal(t, "kk/-kk", L.GetGlobal("as_string").String())
+	assert.Equal(t, []string{"new", "kk"}, stringSliceFromTable(L.GetGlobal("args").(*lua.LTable)))
+}
+
+func TestRevisions_RowsAndChecked(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.RevisionDetailsList("aaaa|bbbb")).SetOutput([]byte(revisionDetails))
+	commandRunner.Expect(jj.RevisionDetailsList("bbbb")).SetOutput([]byte(revisionDetails))
+	defer commandRunner.Verify()
+	ctx := setupRevisionsVM(t, commandRunner)
+	ctx.CheckedItems = []uicontext.SelectedItem{uicontext.SelectedRevision{ChangeId: "ll", CommitId: "bbbb"}}
+
+	require.NoError(t, ctx.ScriptVM.DoString(`
+		rows = {}
+		for _, rev in ipairs(jjui.revisions.rows()) do
+			table.insert(rows, rev.change_id .. ":" .. tostring(rev.empty))
+		end
+		rows = table.concat(rows, ",")
+		checked = jjui.revisions.checked()[1].immutable
+	`))
+	assert.Equal(t, "kk:false,ll:true", ctx.ScriptVM.GetGlobal("rows").String())
+	assert.Equal(t, "true", ctx.ScriptVM.GetGlobal("checked").String())
+}
+
+func TestRevisions_GetLooksUpAnyRevision(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.RevisionDetailsList("bbbb")).SetOutput([]byte(revisionDetails))
+	commandRunner.Expect(jj.RevisionDetailsList("trunk()")).SetOutput([]byte(revisionDetails))
+	commandRunner.Expect(jj.RevisionDetailsList("missing")).SetError(assert.AnError)
+	defer commandRunner.Verify()
+	ctx := setupRevisionsVM(t, commandRunner)
+
+	require.NoError(t, ctx.ScriptVM.DoString(`
+		loaded = jjui.revisions.get("ll").author.name
+		trunk = jjui.revisions.get("trunk()").change_id
+		missing, err = jjui.revisions.get("missing")
+	`))
+	L := ctx.ScriptVM
+	assert.Equal(t, "John", L.GetGlobal("loaded").String())
+	assert.Equal(t, "kkkkkkkk", L.GetGlobal("trunk").String())
+	assert.Equal(t, "nil", L.GetGlobal("missing").String())
+	assert.NotEmpty(t, L.GetGlobal("err").String())
+}
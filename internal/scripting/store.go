@@ -0,0 +1,249 @@
+package scripting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/idursun/jjui/internal/config"
+	uicontext "github.com/idursun/jjui/internal/ui/context"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// The limits of what scripts can store, so that a runaway script can't fill
+// the disk or slow down every start.
+const (
+	maxStoreKeyLength = 256
+	maxStoreValueSize = 64 * 1024
+	maxStoreFileSize  = 1024 * 1024
+)
+
+// kvStore is a JSON file of values that outlive the session. Every change is
+// made to what is on disk, so that several jjui instances don't undo each
+// other's changes.
+type kvStore struct {
+	path   string
+	values map[string]any
+}
+
+func newRepoStore(location string) *kvStore {
+	name := config.GetRepoID(location) + ".json"
+	return &kvStore{path: filepath.Join(config.GetStateDir(), "store", "repos", name)}
+}
+
+func newGlobalStore() *kvStore {
+	return &kvStore{path: filepath.Join(config.GetStateDir(), "store", "global.json")}
+}
+
+func (s *kvStore) load() (map[string]any, error) {
+	values := map[string]any{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("store: %s: %w", s.path, err)
+	}
+	return values, nil
+}
+
+func (s *kvStore) get(key string) (any, error) {
+	if s.values == nil {
+		values, err := s.load()
+		if err != nil {
+			return nil, err
+		}
+		s.values = values
+	}
+	return s.values[key], nil
+}
+
+// update changes the value of the key, or deletes it when the value is nil.
+func (s *kvStore) update(key string, value any) error {
+	values, err := s.load()
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		if _, ok := values[key]; !ok {
+			s.values = values
+			return nil
+		}
+		delete(values, key)
+	} else {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("store: %w", err)
+		}
+		if len(encoded) > maxStoreValueSize {
+			return fmt.Errorf("store: value of %q is %d bytes, more than the limit of %d", key, len(encoded), maxStoreValueSize)
+		}
+		values[key] = value
+	}
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+	if len(data) > maxStoreFileSize {
+		return fmt.Errorf("store: %s would be %d bytes, more than the limit of %d", s.path, len(data), maxStoreFileSize)
+	}
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return err
+	}
+	s.values = values
+	return nil
+}
+
+func (s *kvStore) keys() ([]string, error) {
+	if _, err := s.get(""); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// writeFileAtomic replaces the file with the data, so that readers never see
+// a partly written file.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func goValueToLua(L *lua.LState, value any) lua.LValue {
+	switch value := value.(type) {
+	case bool:
+		return lua.LBool(value)
+	case float64:
+		return lua.LNumber(value)
+	case string:
+		return lua.LString(value)
+	case []any:
+		tbl := L.NewTable()
+		for _, item := range value {
+			tbl.Append(goValueToLua(L, item))
+		}
+		return tbl
+	case map[string]any:
+		tbl := L.NewTable()
+		for key, item := range value {
+			tbl.RawSetString(key, goValueToLua(L, item))
+		}
+		return tbl
+	}
+	return lua.LNil
+}
+
+func registerStoreAPI(L *lua.LState, ctx *uicontext.MainContext, root *lua.LTable) {
+	var repoStore, globalStore *kvStore
+	// storeArg is the store the options at pos ask for: the store of the
+	// repository unless {global = true} is given.
+	storeArg := func(L *lua.LState, pos int) *kvStore {
+		if boolVal(optionalLuaMapArg(L, pos), "global") {
+			if globalStore == nil {
+				globalStore = newGlobalStore()
+			}
+			return globalStore
+		}
+		if repoStore == nil {
+			repoStore = newRepoStore(ctx.Location)
+		}
+		return repoStore
+	}
+	checkKey := func(L *lua.LState) string {
+		key := L.CheckString(1)
+		if key == "" || len(key) > maxStoreKeyLength {
+			L.ArgError(1, fmt.Sprintf("key must be between 1 and %d bytes", maxStoreKeyLength))
+		}
+		return key
+	}
+	pushResult := func(L *lua.LState, err error) int {
+		if err != nil {
+			L.Push(lua.LNil)
+			L.Push(lua.LString(err.Error()))
+			return 2
+		}
+		L.Push(lua.LTrue)
+		return 1
+	}
+
+	storeTable := L.NewTable()
+	storeTable.RawSetString("get", L.NewFunction(func(L *lua.LState) int {
+		key := checkKey(L)
+		value, err := storeArg(L, 2).get(key)
+		if err != nil {
+			L.Push(lua.LNil)
+			L.Push(lua.LString(err.Error()))
+			return 2
+		}
+		L.Push(goValueToLua(L, value))
+		return 1
+	}))
+	storeTable.RawSetString("set", L.NewFunction(func(L *lua.LState) int {
+		key := checkKey(L)
+		value := L.Get(2)
+		switch value.Type() {
+		case lua.LTNil, lua.LTBool, lua.LTNumber, lua.LTString, lua.LTTable:
+		default:
+			L.ArgError(2, fmt.Sprintf("%s values can't be stored", value.Type().String()))
+			return 0
+		}
+		converted := luaValueToGo(value)
+		if list, ok := converted.([]any); ok && list == nil {
+			// keep empty tables, which would be stored as null otherwise
+			converted = []any{}
+		}
+		return pushResult(L, storeArg(L, 3).update(key, converted))
+	}))
+	storeTable.RawSetString("delete", L.NewFunction(func(L *lua.LState) int {
+		key := checkKey(L)
+		return pushResult(L, storeArg(L, 2).update(key, nil))
+	}))
+	storeTable.RawSetString("keys", L.NewFunction(func(L *lua.LState) int {
+		keys, err := storeArg(L, 1).keys()
+		if err != nil {
+			L.Push(lua.LNil)
+			L.Push(lua.LString(err.Error()))
+			return 2
+		}
+		L.Push(stringsToLuaTable(L, keys))
+		return 1
+	}))
+	root.RawSetString("store", storeTable)
+}
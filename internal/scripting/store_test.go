@@ -0,0 +1,81 @@
+package scripting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStoreVM(t *testing.T, location string) func(string) error {
+	t.Helper()
+	ctx := setupVM(t)
+	ctx.Location = location
+	return ctx.ScriptVM.DoString
+}
+
+func TestStore_PersistsAcrossVMs(t *testing.T) {
+	stateDir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", stateDir)
+
+	run := setupStoreVM(t, "/repo")
+	require.NoError(t, run(`
+		assert(jjui.store.set("reviewed", { kkkk = true, count = 2 }))
+		assert(jjui.store.set("last_revset", "mine()"))
+		assert(jjui.store.set("theme", "dark", { global = true }))
+		assert(jjui.store.delete("last_revset"))
+	`))
+
+	ctx := setupVM(t)
+	ctx.Location = "/repo"
+	require.NoError(t, ctx.ScriptVM.DoString(`
+		local reviewed = jjui.store.get("reviewed")
+		kkkk, count = reviewed.kkkk, reviewed.count
+		last_revset = jjui.store.get("last_revset")
+		theme = jjui.store.get("theme", { global = true })
+		repo_theme = jjui.store.get("theme")
+		keys = table.concat(jjui.store.keys(), ",")
+	`))
+	L := ctx.ScriptVM
+	assert.Equal(t, "true", L.GetGlobal("kkkk").String())
+	assert.Equal(t, "2", L.GetGlobal("count").String())
+	assert.Equal(t, "nil", L.GetGlobal("last_revset").String())
+	assert.Equal(t, "dark", L.GetGlobal("theme").String())
+	assert.Equal(t, "nil", L.GetGlobal("repo_theme").String())
+	assert.Equal(t, "reviewed", L.GetGlobal("keys").String())
+
+	entries, err := os.ReadDir(filepath.Join(stateDir, "jjui", "store", "repos"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary files are not left behind")
+}
+
+func TestStore_SeparatesRepositories(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	run := setupStoreVM(t, "/one")
+	require.NoError(t, run(`jjui.store.set("mark", "one")`))
+
+	ctx := setupVM(t)
+	ctx.Location = "/two"
+	require.NoError(t, ctx.ScriptVM.DoString(`mark = jjui.store.get("mark")`))
+	assert.Equal(t, "nil", ctx.ScriptVM.GetGlobal("mark").String())
+}
+
+func TestStore_EnforcesLimits(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	run := setupStoreVM(t, "/repo")
+
+	require.NoError(t, run(`ok, err = jjui.store.set("big", string.rep("x", 70000))`))
+	err := run(`assert(ok == nil and err:find("more than the limit"), err)`)
+	assert.NoError(t, err)
+
+	err = run(`jjui.store.set("", 1)`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "key must be between 1 and 256 bytes")
+
+	err = run(`jjui.store.set("fn", function() end)`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "function values can't be stored")
+}
@@ -0,0 +1,182 @@
+package scripting
+
+import (
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	uicontext "github.com/idursun/jjui/internal/ui/context"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const schedulerName = "__jjui_scheduler"
+
+// TimerFiredMsg tells the main loop that a timer, or a spawned task, is due.
+type TimerFiredMsg struct {
+	id int
+}
+
+type scheduledTask struct {
+	fn   *lua.LFunction
+	args []lua.LValue
+	// interval is zero for tasks that run once.
+	interval time.Duration
+	timer    *time.Timer
+	// runner is the run of the task that waits for a message, if any.
+	runner *Runner
+}
+
+// scheduler keeps the timers of a VM. Timers only tell the main loop which
+// task is due; the tasks themselves run in the main loop like any other
+// script.
+type scheduler struct {
+	nextId int
+	tasks  map[int]*scheduledTask
+	fired  chan int
+	done   chan struct{}
+}
+
+func newScheduler() *scheduler {
+	return &scheduler{
+		tasks: make(map[int]*scheduledTask),
+		fired: make(chan int),
+		done:  make(chan struct{}),
+	}
+}
+
+func (s *scheduler) schedule(task *scheduledTask, delay time.Duration) int {
+	s.nextId++
+	s.tasks[s.nextId] = task
+	s.arm(s.nextId, task, delay)
+	return s.nextId
+}
+
+func (s *scheduler) arm(id int, task *scheduledTask, delay time.Duration) {
+	task.timer = time.AfterFunc(delay, func() {
+		select {
+		case s.fired <- id:
+		case <-s.done:
+		}
+	})
+}
+
+func (s *scheduler) cancel(id int) bool {
+	task, ok := s.tasks[id]
+	if !ok {
+		return false
+	}
+	delete(s.tasks, id)
+	if task.timer != nil {
+		task.timer.Stop()
+	}
+	if task.runner != nil {
+		task.runner.Stop()
+	}
+	return true
+}
+
+func (s *scheduler) stop() {
+	for id := range s.tasks {
+		s.cancel(id)
+	}
+	close(s.done)
+}
+
+func schedulerOf(ctx *uicontext.MainContext) *scheduler {
+	if ctx == nil || ctx.ScriptVM == nil {
+		return nil
+	}
+	ud, ok := ctx.ScriptVM.GetGlobal(schedulerName).(*lua.LUserData)
+	if !ok {
+		return nil
+	}
+	s, _ := ud.Value.(*scheduler)
+	return s
+}
+
+func registerTimerAPI(L *lua.LState, root *lua.LTable) {
+	s := newScheduler()
+	ud := L.NewUserData()
+	ud.Value = s
+	L.SetGlobal(schedulerName, ud)
+
+	root.RawSetString("set_timeout", L.NewFunction(func(L *lua.LState) int {
+		fn := L.CheckFunction(1)
+		delay := time.Duration(L.OptInt(2, 0)) * time.Millisecond
+		L.Push(lua.LNumber(s.schedule(&scheduledTask{fn: fn}, delay)))
+		return 1
+	}))
+	root.RawSetString("set_interval", L.NewFunction(func(L *lua.LState) int {
+		fn := L.CheckFunction(1)
+		interval := time.Duration(L.CheckInt(2)) * time.Millisecond
+		if interval <= 0 {
+			L.ArgError(2, "interval must be greater than zero")
+			return 0
+		}
+		L.Push(lua.LNumber(s.schedule(&scheduledTask{fn: fn, interval: interval}, interval)))
+		return 1
+	}))
+	root.RawSetString("spawn", L.NewFunction(func(L *lua.LState) int {
+		fn := L.CheckFunction(1)
+		var args []lua.LValue
+		for i := 2; i <= L.GetTop(); i++ {
+			args = append(args, L.Get(i))
+		}
+		L.Push(lua.LNumber(s.schedule(&scheduledTask{fn: fn, args: args}, 0)))
+		return 1
+	}))
+	root.RawSetString("cancel", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LBool(s.cancel(L.CheckInt(1))))
+		return 1
+	}))
+}
+
+// WaitForTimers waits for the next timer of the VM to fire. It has to be
+// started again after each TimerFiredMsg.
+func WaitForTimers(ctx *uicontext.MainContext) tea.Cmd {
+	s := schedulerOf(ctx)
+	if s == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		select {
+		case id := <-s.fired:
+			return TimerFiredMsg{id: id}
+		case <-s.done:
+			return nil
+		}
+	}
+}
+
+// RunTimer runs the task of a timer that fired. The runner is returned if
+// the task waits for a message, and must be given messages until it is done.
+// An interval doesn't run again while its previous run is waiting.
+func RunTimer(ctx *uicontext.MainContext, msg TimerFiredMsg) (*Runner, tea.Cmd) {
+	s := schedulerOf(ctx)
+	if s == nil {
+		return nil, nil
+	}
+	task, ok := s.tasks[msg.id]
+	if !ok {
+		return nil, nil
+	}
+	if task.interval > 0 {
+		s.arm(msg.id, task, task.interval)
+		if task.runner != nil {
+			return nil, nil
+		}
+	} else {
+		task.timer = nil
+	}
+	r, cmd := startRunner(ctx, ctx.ScriptVM, task.fn, func([]lua.LValue, error) tea.Cmd {
+		task.runner = nil
+		if task.interval == 0 {
+			delete(s.tasks, msg.id)
+		}
+		return nil
+	}, task.args...)
+	if r.Done() {
+		return nil, cmd
+	}
+	task.runner = r
+	return r, cmd
+}
@@ -0,0 +1,91 @@
+package scripting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/idursun/jjui/internal/ui/common"
+)
+
+func TestSetTimeout_RunsOnceInMainLoop(t *testing.T) {
+	ctx := setupVM(t)
+	require.NoError(t, ctx.ScriptVM.DoString(`
+		calls = 0
+		id = jjui.set_timeout(function() calls = calls + 1 end, 1)
+	`))
+	assert.Equal(t, "0", ctx.ScriptVM.GetGlobal("calls").String())
+
+	msg, ok := WaitForTimers(ctx)().(TimerFiredMsg)
+	require.True(t, ok)
+	runner, _ := RunTimer(ctx, msg)
+	assert.Nil(t, runner)
+	assert.Equal(t, "1", ctx.ScriptVM.GetGlobal("calls").String())
+
+	require.NoError(t, ctx.ScriptVM.DoString(`canceled = jjui.cancel(id)`))
+	assert.Equal(t, "false", ctx.ScriptVM.GetGlobal("canceled").String())
+}
+
+func TestSetInterval_RepeatsUntilCanceled(t *testing.T) {
+	ctx := setupVM(t)
+	require.NoError(t, ctx.ScriptVM.DoString(`
+		calls = 0
+		id = jjui.set_interval(function()
+			calls = calls + 1
+			if calls == 2 then
+				jjui.cancel(id)
+			end
+		end, 1)
+	`))
+
+	for range 2 {
+		msg, ok := WaitForTimers(ctx)().(TimerFiredMsg)
+		require.True(t, ok)
+		RunTimer(ctx, msg)
+	}
+	assert.Equal(t, "2", ctx.ScriptVM.GetGlobal("calls").String())
+	assert.Empty(t, schedulerOf(ctx).tasks)
+}
+
+func TestSetInterval_RejectsZeroInterval(t *testing.T) {
+	ctx := setupVM(t)
+	err := ctx.ScriptVM.DoString(`jjui.set_interval(function() end, 0)`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "interval must be greater than zero")
+}
+
+func TestSpawn_RunsInBackgroundAndCanBeCanceled(t *testing.T) {
+	ctx := setupVM(t)
+	require.NoError(t, ctx.ScriptVM.DoString(`
+		id = jjui.spawn(function(name)
+			started = name
+			wait_close()
+			finished = true
+		end, "poller")
+	`))
+
+	msg, ok := WaitForTimers(ctx)().(TimerFiredMsg)
+	require.True(t, ok)
+	runner, _ := RunTimer(ctx, msg)
+	require.NotNil(t, runner)
+	assert.Equal(t, "poller", ctx.ScriptVM.GetGlobal("started").String())
+
+	require.NoError(t, ctx.ScriptVM.DoString(`canceled = jjui.cancel(id)`))
+	assert.Equal(t, "true", ctx.ScriptVM.GetGlobal("canceled").String())
+	assert.True(t, runner.Done())
+	runner.HandleMsg(common.CloseViewMsg{})
+	assert.Equal(t, "nil", ctx.ScriptVM.GetGlobal("finished").String())
+}
+
+func TestCloseVM_StopsTimers(t *testing.T) {
+	ctx := setupVM(t)
+	require.NoError(t, ctx.ScriptVM.DoString(`jjui.set_timeout(function() end, 60000)`))
+	wait := WaitForTimers(ctx)
+	s := schedulerOf(ctx)
+
+	CloseVM(ctx)
+	assert.Nil(t, wait())
+	assert.Empty(t, s.tasks)
+	assert.Nil(t, WaitForTimers(ctx))
+}
@@ -15,22 +15,51 @@ var builtInActionOwners = map[string][]string{
 	"bookmarks.bookmark_track":                   {"bookmarks"},
 	"bookmarks.bookmark_untrack":                 {"bookmarks"},
 	"bookmarks.cancel":                           {"bookmarks"},
+	"bookmarks.cleanup.cancel":                   {"bookmarks.cleanup"},
+	"bookmarks.cleanup.delete":                   {"bookmarks.cleanup"},
+	"bookmarks.cleanup.forget":                   {"bookmarks.cleanup"},
+	"bookmarks.cleanup.move_down":                {"bookmarks.cleanup"},
+	"bookmarks.cleanup.move_up":                  {"bookmarks.cleanup"},
+	"bookmarks.cleanup.select_all":               {"bookmarks.cleanup"},
+	"bookmarks.cleanup.toggle_select":            {"bookmarks.cleanup"},
+	"bookmarks.cleanup.untrack":                  {"bookmarks.cleanup"},
+	"bookmarks.create":                           {"bookmarks"},
 	"bookmarks.cycle_remotes":                    {"bookmarks"},
 	"bookmarks.cycle_remotes_back":               {"bookmarks"},
 	"bookmarks.filter":                           {"bookmarks"},
+	"bookmarks.filter_diverged":                  {"bookmarks"},
+	"bookmarks.filter_needs_pull":                {"bookmarks"},
+	"bookmarks.filter_needs_push":                {"bookmarks"},
 	"bookmarks.move_down":                        {"bookmarks"},
 	"bookmarks.move_up":                          {"bookmarks"},
 	"bookmarks.page_down":                        {"bookmarks"},
 	"bookmarks.page_up":                          {"bookmarks"},
 	"bookmarks.quit":                             {"bookmarks"},
+	"bookmarks.rename":                           {"bookmarks"},
+	"bookmarks.sort_by_sync":                     {"bookmarks"},
+	"bookmarks.toggle_select":                    {"bookmarks"},
+	"bookmarks.track_glob":                       {"bookmarks"},
 	"choose.apply":                               {"choose"},
 	"choose.cancel":                              {"choose"},
 	"choose.move_down":                           {"choose"},
 	"choose.move_up":                             {"choose"},
+	"command_history.cancel_selected":            {"command_history"},
 	"command_history.close":                      {"command_history"},
+	"command_history.copy_selected":              {"command_history"},
+	"command_history.cycle_filter":               {"command_history"},
 	"command_history.delete_selected":            {"command_history"},
+	"command_history.edit_selected":              {"command_history"},
 	"command_history.move_down":                  {"command_history"},
 	"command_history.move_up":                    {"command_history"},
+	"command_history.rerun_selected":             {"command_history"},
+	"command_history.toggle_output":              {"command_history"},
+	"console.clear":                              {"console"},
+	"console.close":                              {"console"},
+	"console.history_next":                       {"console"},
+	"console.history_prev":                       {"console"},
+	"console.page_down":                          {"console"},
+	"console.page_up":                            {"console"},
+	"console.submit":                             {"console"},
 	"diff.half_page_down":                        {"diff"},
 	"diff.half_page_up":                          {"diff"},
 	"diff.left":                                  {"diff"},
@@ -65,6 +94,14 @@ var builtInActionOwners = map[string][]string{
 	"git.page_up":                                {"git"},
 	"git.push":                                   {"git"},
 	"git.quit":                                   {"git"},
+	"git.remotes.add":                            {"git.remotes"},
+	"git.remotes.apply":                          {"git.remotes"},
+	"git.remotes.cancel":                         {"git.remotes"},
+	"git.remotes.move_down":                      {"git.remotes"},
+	"git.remotes.move_up":                        {"git.remotes"},
+	"git.remotes.remove":                         {"git.remotes"},
+	"git.remotes.rename":                         {"git.remotes"},
+	"git.remotes.set_url":                        {"git.remotes"},
 	"help.apply":                                 {"help"},
 	"help.cancel":                                {"help"},
 	"help.close":                                 {"help"},
@@ -77,6 +114,10 @@ var builtInActionOwners = map[string][]string{
 	"help.scroll_up":                             {"help"},
 	"input.apply":                                {"input"},
 	"input.cancel":                               {"input"},
+	"notifications.close":                        {"notifications"},
+	"notifications.cycle_filter":                 {"notifications"},
+	"notifications.move_down":                    {"notifications"},
+	"notifications.move_up":                      {"notifications"},
 	"oplog.close":                                {"oplog"},
 	"oplog.diff":                                 {"oplog"},
 	"oplog.move_down":                            {"oplog"},
@@ -89,8 +130,29 @@ var builtInActionOwners = map[string][]string{
 	"oplog.quit":                                 {"oplog"},
 	"oplog.restore":                              {"oplog"},
 	"oplog.revert":                               {"oplog"},
+	"pager.apply":                                {"pager"},
+	"pager.cancel":                               {"pager"},
+	"pager.copy":                                 {"pager"},
+	"pager.half_page_down":                       {"pager"},
+	"pager.half_page_up":                         {"pager"},
+	"pager.left":                                 {"pager"},
+	"pager.move_bottom":                          {"pager"},
+	"pager.move_top":                             {"pager"},
+	"pager.next_match":                           {"pager"},
+	"pager.page_down":                            {"pager"},
+	"pager.page_up":                              {"pager"},
+	"pager.prev_match":                           {"pager"},
+	"pager.right":                                {"pager"},
+	"pager.scroll_down":                          {"pager"},
+	"pager.scroll_up":                            {"pager"},
+	"pager.search":                               {"pager"},
+	"pager.toggle_wrap":                          {"pager"},
 	"password.apply":                             {"password"},
 	"password.cancel":                            {"password"},
+	"plugins.close":                              {"plugins"},
+	"plugins.move_down":                          {"plugins"},
+	"plugins.move_up":                            {"plugins"},
+	"plugins.toggle":                             {"plugins"},
 	"redo.apply":                                 {"redo"},
 	"redo.cancel":                                {"redo"},
 	"redo.next":                                  {"redo"},
@@ -233,20 +295,28 @@ var builtInActionOwners = map[string][]string{
 	"status.input.apply":                         {"status.input"},
 	"status.input.autocomplete":                  {"status.input"},
 	"status.input.cancel":                        {"status.input"},
+	"status.input.complete":                      {"status.input"},
+	"status.input.complete_back":                 {"status.input"},
 	"status.input.move_down":                     {"status.input"},
 	"status.input.move_up":                       {"status.input"},
 	"status.input.page_down":                     {"status.input"},
 	"status.input.page_up":                       {"status.input"},
 	"ui.cancel":                                  {"ui"},
+	"ui.cancel_command":                          {"ui"},
 	"ui.exec_jj":                                 {"ui"},
 	"ui.exec_shell":                              {"ui"},
 	"ui.expand_status":                           {"ui"},
 	"ui.file_search_toggle":                      {"ui"},
+	"ui.open_bookmark_cleanup":                   {"ui"},
 	"ui.open_bookmarks":                          {"ui"},
 	"ui.open_command_history":                    {"ui"},
+	"ui.open_console":                            {"ui"},
 	"ui.open_git":                                {"ui"},
+	"ui.open_git_remotes":                        {"ui"},
 	"ui.open_help":                               {"ui"},
+	"ui.open_notifications":                      {"ui"},
 	"ui.open_oplog":                              {"ui"},
+	"ui.open_plugins":                            {"ui"},
 	"ui.open_redo":                               {"ui"},
 	"ui.open_revset":                             {"ui"},
 	"ui.open_undo":                               {"ui"},
@@ -261,7 +331,10 @@ var builtInActionOwners = map[string][]string{
 	"ui.preview_toggle_bottom":                   {"ui"},
 	"ui.quick_search":                            {"ui"},
 	"ui.quit":                                    {"ui"},
+	"ui.rebase_onto_trunk":                       {"ui"},
+	"ui.recover_command":                         {"ui"},
 	"ui.suspend":                                 {"ui"},
+	"ui.toggle_terminal_focus":                   {"ui"},
 	"undo.apply":                                 {"undo"},
 	"undo.cancel":                                {"undo"},
 	"undo.next":                                  {"undo"},
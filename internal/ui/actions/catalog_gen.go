@@ -9,16 +9,22 @@ import (
 
 const (
 	OwnerBookmarks           = "bookmarks"
+	OwnerBookmarksCleanup    = "bookmarks.cleanup"
 	OwnerChoose              = "choose"
 	OwnerCommandHistory      = "command_history"
+	OwnerConsole             = "console"
 	OwnerDiff                = "diff"
 	OwnerFileSearch          = "file_search"
 	OwnerGit                 = "git"
+	OwnerGitRemotes          = "git.remotes"
 	OwnerHelp                = "help"
 	OwnerInput               = "input"
+	OwnerNotifications       = "notifications"
 	OwnerOplog               = "oplog"
 	OwnerOplogQuickSearch    = "oplog.quick_search"
+	OwnerPager               = "pager"
 	OwnerPassword            = "password"
+	OwnerPlugins             = "plugins"
 	OwnerRedo                = "redo"
 	OwnerRevisions           = "revisions"
 	OwnerAbandon             = "revisions.abandon"
@@ -44,7 +50,7 @@ const (
 
 func IsRevisionsOwner(owner string) bool {
 	switch owner {
-	case OwnerCommandHistory, OwnerHelp, OwnerOplogQuickSearch, OwnerRevisions, OwnerAbandon, OwnerAceJump, OwnerDetails, OwnerDetailsConfirmation, OwnerDuplicate, OwnerEvolog, OwnerInlineDescribe, OwnerQuickSearchInput, OwnerRebase, OwnerRevert, OwnerSetBookmark, OwnerSetParents, OwnerSquash, OwnerTargetPicker:
+	case OwnerCommandHistory, OwnerConsole, OwnerHelp, OwnerNotifications, OwnerOplogQuickSearch, OwnerPager, OwnerPlugins, OwnerRevisions, OwnerAbandon, OwnerAceJump, OwnerDetails, OwnerDetailsConfirmation, OwnerDuplicate, OwnerEvolog, OwnerInlineDescribe, OwnerQuickSearchInput, OwnerRebase, OwnerRevert, OwnerSetBookmark, OwnerSetParents, OwnerSquash, OwnerTargetPicker:
 		return true
 	default:
 		return false
@@ -69,12 +75,20 @@ func ResolveIntent(owner string, action keybindings.Action, args map[string]any)
 			return intents.BookmarksFilter{Kind: intents.BookmarksFilterUntrack}, true
 		case keybindings.Action("bookmarks.cancel"):
 			return intents.Cancel{}, true
+		case keybindings.Action("bookmarks.create"):
+			return intents.BookmarksCreate{}, true
 		case keybindings.Action("bookmarks.cycle_remotes"):
 			return intents.BookmarksCycleRemotes{Delta: 1}, true
 		case keybindings.Action("bookmarks.cycle_remotes_back"):
 			return intents.BookmarksCycleRemotes{Delta: -1}, true
 		case keybindings.Action("bookmarks.filter"):
 			return intents.BookmarksOpenFilter{}, true
+		case keybindings.Action("bookmarks.filter_diverged"):
+			return intents.BookmarksSyncFilter{Kind: intents.BookmarksSyncDiverged}, true
+		case keybindings.Action("bookmarks.filter_needs_pull"):
+			return intents.BookmarksSyncFilter{Kind: intents.BookmarksSyncNeedsPull}, true
+		case keybindings.Action("bookmarks.filter_needs_push"):
+			return intents.BookmarksSyncFilter{Kind: intents.BookmarksSyncNeedsPush}, true
 		case keybindings.Action("bookmarks.move_down"):
 			return intents.BookmarksNavigate{Delta: 1}, true
 		case keybindings.Action("bookmarks.move_up"):
@@ -85,6 +99,33 @@ func ResolveIntent(owner string, action keybindings.Action, args map[string]any)
 			return intents.BookmarksNavigate{Delta: -1, IsPage: true}, true
 		case keybindings.Action("bookmarks.quit"):
 			return intents.Quit{}, true
+		case keybindings.Action("bookmarks.rename"):
+			return intents.BookmarksRename{}, true
+		case keybindings.Action("bookmarks.sort_by_sync"):
+			return intents.BookmarksToggleSyncSort{}, true
+		case keybindings.Action("bookmarks.toggle_select"):
+			return intents.BookmarksToggleSelect{}, true
+		case keybindings.Action("bookmarks.track_glob"):
+			return intents.BookmarksTrackGlob{}, true
+		}
+	case OwnerBookmarksCleanup:
+		switch action {
+		case keybindings.Action("bookmarks.cleanup.cancel"):
+			return intents.Cancel{}, true
+		case keybindings.Action("bookmarks.cleanup.delete"):
+			return intents.BookmarksCleanup{Kind: intents.BookmarksFilterDelete}, true
+		case keybindings.Action("bookmarks.cleanup.forget"):
+			return intents.BookmarksCleanup{Kind: intents.BookmarksFilterForget}, true
+		case keybindings.Action("bookmarks.cleanup.move_down"):
+			return intents.BookmarksNavigate{Delta: 1}, true
+		case keybindings.Action("bookmarks.cleanup.move_up"):
+			return intents.BookmarksNavigate{Delta: -1}, true
+		case keybindings.Action("bookmarks.cleanup.select_all"):
+			return intents.BookmarksSelectAll{}, true
+		case keybindings.Action("bookmarks.cleanup.toggle_select"):
+			return intents.BookmarksToggleSelect{}, true
+		case keybindings.Action("bookmarks.cleanup.untrack"):
+			return intents.BookmarksCleanup{Kind: intents.BookmarksFilterUntrack}, true
 		}
 	case OwnerChoose:
 		switch action {
@@ -99,14 +140,43 @@ func ResolveIntent(owner string, action keybindings.Action, args map[string]any)
 		}
 	case OwnerCommandHistory:
 		switch action {
+		case keybindings.Action("command_history.cancel_selected"):
+			return intents.CommandHistoryCancelSelected{}, true
 		case keybindings.Action("command_history.close"):
 			return intents.CommandHistoryClose{}, true
+		case keybindings.Action("command_history.copy_selected"):
+			return intents.CommandHistoryCopySelected{}, true
+		case keybindings.Action("command_history.cycle_filter"):
+			return intents.CommandHistoryCycleFilter{}, true
 		case keybindings.Action("command_history.delete_selected"):
 			return intents.CommandHistoryDeleteSelected{}, true
+		case keybindings.Action("command_history.edit_selected"):
+			return intents.CommandHistoryEditSelected{}, true
 		case keybindings.Action("command_history.move_down"):
 			return intents.CommandHistoryNavigate{Delta: 1}, true
 		case keybindings.Action("command_history.move_up"):
 			return intents.CommandHistoryNavigate{Delta: -1}, true
+		case keybindings.Action("command_history.rerun_selected"):
+			return intents.CommandHistoryRerunSelected{}, true
+		case keybindings.Action("command_history.toggle_output"):
+			return intents.CommandHistoryToggleOutput{}, true
+		}
+	case OwnerConsole:
+		switch action {
+		case keybindings.Action("console.clear"):
+			return intents.ConsoleClear{}, true
+		case keybindings.Action("console.close"):
+			return intents.ConsoleClose{}, true
+		case keybindings.Action("console.history_next"):
+			return intents.ConsoleHistory{Delta: 1}, true
+		case keybindings.Action("console.history_prev"):
+			return intents.ConsoleHistory{Delta: -1}, true
+		case keybindings.Action("console.page_down"):
+			return intents.ConsoleScroll{Delta: 1}, true
+		case keybindings.Action("console.page_up"):
+			return intents.ConsoleScroll{Delta: -1}, true
+		case keybindings.Action("console.submit"):
+			return intents.ConsoleSubmit{}, true
 		}
 	case OwnerDiff:
 		switch action {
@@ -185,6 +255,25 @@ func ResolveIntent(owner string, action keybindings.Action, args map[string]any)
 		case keybindings.Action("git.quit"):
 			return intents.Quit{}, true
 		}
+	case OwnerGitRemotes:
+		switch action {
+		case keybindings.Action("git.remotes.add"):
+			return intents.GitRemoteAction{Kind: intents.GitRemoteAdd}, true
+		case keybindings.Action("git.remotes.apply"):
+			return intents.Apply{}, true
+		case keybindings.Action("git.remotes.cancel"):
+			return intents.Cancel{}, true
+		case keybindings.Action("git.remotes.move_down"):
+			return intents.GitNavigate{Delta: 1}, true
+		case keybindings.Action("git.remotes.move_up"):
+			return intents.GitNavigate{Delta: -1}, true
+		case keybindings.Action("git.remotes.remove"):
+			return intents.GitRemoteAction{Kind: intents.GitRemoteRemove}, true
+		case keybindings.Action("git.remotes.rename"):
+			return intents.GitRemoteAction{Kind: intents.GitRemoteRename}, true
+		case keybindings.Action("git.remotes.set_url"):
+			return intents.GitRemoteAction{Kind: intents.GitRemoteSetUrl}, true
+		}
 	case OwnerHelp:
 		switch action {
 		case keybindings.Action("help.apply"):
@@ -215,6 +304,17 @@ func ResolveIntent(owner string, action keybindings.Action, args map[string]any)
 		case keybindings.Action("input.cancel"):
 			return intents.Cancel{}, true
 		}
+	case OwnerNotifications:
+		switch action {
+		case keybindings.Action("notifications.close"):
+			return intents.NotificationsClose{}, true
+		case keybindings.Action("notifications.cycle_filter"):
+			return intents.NotificationsCycleFilter{}, true
+		case keybindings.Action("notifications.move_down"):
+			return intents.NotificationsNavigate{Delta: 1}, true
+		case keybindings.Action("notifications.move_up"):
+			return intents.NotificationsNavigate{Delta: -1}, true
+		}
 	case OwnerOplog:
 		switch action {
 		case keybindings.Action("oplog.close"):
@@ -245,6 +345,43 @@ func ResolveIntent(owner string, action keybindings.Action, args map[string]any)
 		case keybindings.Action("oplog.quick_search.quick_search_prev"):
 			return intents.QuickSearchCycle{Reverse: true}, true
 		}
+	case OwnerPager:
+		switch action {
+		case keybindings.Action("pager.apply"):
+			return intents.Apply{}, true
+		case keybindings.Action("pager.cancel"):
+			return intents.Cancel{}, true
+		case keybindings.Action("pager.copy"):
+			return intents.PagerCopy{}, true
+		case keybindings.Action("pager.half_page_down"):
+			return intents.DiffScroll{Kind: intents.DiffHalfPageDown}, true
+		case keybindings.Action("pager.half_page_up"):
+			return intents.DiffScroll{Kind: intents.DiffHalfPageUp}, true
+		case keybindings.Action("pager.left"):
+			return intents.DiffScrollHorizontal{Kind: intents.DiffScrollLeft}, true
+		case keybindings.Action("pager.move_bottom"):
+			return intents.DiffScroll{Kind: intents.DiffMoveBottom}, true
+		case keybindings.Action("pager.move_top"):
+			return intents.DiffScroll{Kind: intents.DiffMoveTop}, true
+		case keybindings.Action("pager.next_match"):
+			return intents.PagerNextMatch{Delta: 1}, true
+		case keybindings.Action("pager.page_down"):
+			return intents.DiffScroll{Kind: intents.DiffPageDown}, true
+		case keybindings.Action("pager.page_up"):
+			return intents.DiffScroll{Kind: intents.DiffPageUp}, true
+		case keybindings.Action("pager.prev_match"):
+			return intents.PagerNextMatch{Delta: -1}, true
+		case keybindings.Action("pager.right"):
+			return intents.DiffScrollHorizontal{Kind: intents.DiffScrollRight}, true
+		case keybindings.Action("pager.scroll_down"):
+			return intents.DiffScroll{Kind: intents.DiffScrollDown}, true
+		case keybindings.Action("pager.scroll_up"):
+			return intents.DiffScroll{Kind: intents.DiffScrollUp}, true
+		case keybindings.Action("pager.search"):
+			return intents.PagerSearch{}, true
+		case keybindings.Action("pager.toggle_wrap"):
+			return intents.DiffToggleWrap{}, true
+		}
 	case OwnerPassword:
 		switch action {
 		case keybindings.Action("password.apply"):
@@ -252,6 +389,17 @@ func ResolveIntent(owner string, action keybindings.Action, args map[string]any)
 		case keybindings.Action("password.cancel"):
 			return intents.Cancel{}, true
 		}
+	case OwnerPlugins:
+		switch action {
+		case keybindings.Action("plugins.close"):
+			return intents.PluginsClose{}, true
+		case keybindings.Action("plugins.move_down"):
+			return intents.PluginsNavigate{Delta: 1}, true
+		case keybindings.Action("plugins.move_up"):
+			return intents.PluginsNavigate{Delta: -1}, true
+		case keybindings.Action("plugins.toggle"):
+			return intents.PluginsToggle{}, true
+		}
 	case OwnerRedo:
 		switch action {
 		case keybindings.Action("redo.apply"):
@@ -589,6 +737,10 @@ func ResolveIntent(owner string, action keybindings.Action, args map[string]any)
 			return intents.SuggestCycle{}, true
 		case keybindings.Action("status.input.cancel"):
 			return intents.Cancel{}, true
+		case keybindings.Action("status.input.complete"):
+			return intents.CompletionCycle{}, true
+		case keybindings.Action("status.input.complete_back"):
+			return intents.CompletionCycle{Reverse: true}, true
 		case keybindings.Action("status.input.move_down"):
 			return intents.SuggestNavigate{Delta: -1}, true
 		case keybindings.Action("status.input.move_up"):
@@ -602,6 +754,8 @@ func ResolveIntent(owner string, action keybindings.Action, args map[string]any)
 		switch action {
 		case keybindings.Action("ui.cancel"):
 			return intents.Cancel{}, true
+		case keybindings.Action("ui.cancel_command"):
+			return intents.CancelCommand{}, true
 		case keybindings.Action("ui.exec_jj"):
 			return intents.ExecJJ{}, true
 		case keybindings.Action("ui.exec_shell"):
@@ -610,16 +764,26 @@ func ResolveIntent(owner string, action keybindings.Action, args map[string]any)
 			return intents.ExpandStatusToggle{}, true
 		case keybindings.Action("ui.file_search_toggle"):
 			return intents.FileSearchToggle{}, true
+		case keybindings.Action("ui.open_bookmark_cleanup"):
+			return intents.OpenBookmarkCleanup{}, true
 		case keybindings.Action("ui.open_bookmarks"):
 			return intents.OpenBookmarks{}, true
 		case keybindings.Action("ui.open_command_history"):
 			return intents.CommandHistoryToggle{}, true
+		case keybindings.Action("ui.open_console"):
+			return intents.OpenConsole{}, true
 		case keybindings.Action("ui.open_git"):
 			return intents.OpenGit{}, true
+		case keybindings.Action("ui.open_git_remotes"):
+			return intents.OpenGitRemotes{}, true
 		case keybindings.Action("ui.open_help"):
 			return intents.OpenHelp{}, true
+		case keybindings.Action("ui.open_notifications"):
+			return intents.OpenNotifications{}, true
 		case keybindings.Action("ui.open_oplog"):
 			return intents.OpLogOpen{}, true
+		case keybindings.Action("ui.open_plugins"):
+			return intents.OpenPlugins{}, true
 		case keybindings.Action("ui.open_redo"):
 			return intents.Redo{}, true
 		case keybindings.Action("ui.open_revset"):
@@ -646,8 +810,14 @@ func ResolveIntent(owner string, action keybindings.Action, args map[string]any)
 			return intents.QuickSearch{}, true
 		case keybindings.Action("ui.quit"):
 			return intents.Quit{}, true
+		case keybindings.Action("ui.rebase_onto_trunk"):
+			return intents.RebaseOntoTrunk{}, true
+		case keybindings.Action("ui.recover_command"):
+			return intents.RecoverCommand{}, true
 		case keybindings.Action("ui.suspend"):
 			return intents.Suspend{}, true
+		case keybindings.Action("ui.toggle_terminal_focus"):
+			return intents.TerminalToggleFocus{}, true
 		}
 	case OwnerUiPreview:
 		switch action {
@@ -2,6 +2,7 @@ package bookmarks
 
 import (
 	"fmt"
+	"maps"
 	"math"
 	"slices"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/actions"
 	"github.com/idursun/jjui/internal/ui/common"
@@ -20,6 +22,7 @@ import (
 
 type updateItemsMsg struct {
 	items []item
+	syncs map[string]jj.BookmarkSync
 }
 
 // SelectRemoteMsg is sent when a remote is clicked
@@ -85,6 +88,13 @@ type Model struct {
 	filterState         filterState
 	filterText          string
 	categoryFilter      string
+	syncFilter          intents.BookmarksSyncFilterKind
+	sortBySync          bool
+	syncs               map[string]jj.BookmarkSync
+	selected            map[string]bool
+	prompt              promptKind
+	promptInput         textinput.Model
+	promptTarget        string
 	ensureCursorVisible bool
 	menuStyles          menuStyles
 	remoteStyles        remoteStyles
@@ -92,11 +102,11 @@ type Model struct {
 }
 
 func (m *Model) IsFocused() bool {
-	return m.filterState == filterEditing
+	return m.filterState == filterEditing || m.prompt != promptNone
 }
 
 func (m *Model) IsEditing() bool {
-	return m.filterState == filterEditing
+	return m.filterState == filterEditing || m.prompt != promptNone
 }
 
 func (m *Model) StackedActionOwner() string {
@@ -116,6 +126,8 @@ const (
 
 type item struct {
 	name     string
+	bookmark string
+	remote   string
 	priority commandType
 	dist     int
 	args     []string
@@ -234,6 +246,7 @@ func (m *Model) loadMovables() tea.Msg {
 		}
 		elem := item{
 			name:     name,
+			bookmark: b.Name,
 			priority: moveCommand,
 			args:     jj.BookmarkMove(m.current.GetChangeId(), b.Name, extraFlags...),
 			dist:     m.distance(b.CommitId),
@@ -253,11 +266,13 @@ func (m *Model) loadAll() tea.Msg {
 		bookmarks := jj.ParseBookmarkListOutput(string(output))
 
 		items := make([]item, 0)
+		syncs := m.loadSyncs(bookmarks)
 		for _, b := range bookmarks {
 			distance := m.distance(b.CommitId)
 			if b.IsDeletable() {
 				items = append(items, item{
 					name:     fmt.Sprintf("delete '%s'", b.Name),
+					bookmark: b.Name,
 					priority: deleteCommand,
 					dist:     distance,
 					args:     jj.BookmarkDelete(b.Name),
@@ -266,6 +281,7 @@ func (m *Model) loadAll() tea.Msg {
 
 			items = append(items, item{
 				name:     fmt.Sprintf("forget '%s'", b.Name),
+				bookmark: b.Name,
 				priority: forgetCommand,
 				dist:     distance,
 				args:     jj.BookmarkForget(b.Name),
@@ -275,9 +291,11 @@ func (m *Model) loadAll() tea.Msg {
 			if b.IsTrackable() {
 				items = append(items, item{
 					name:     fmt.Sprintf("track '%s'", b.Name),
+					bookmark: b.Name,
 					priority: trackCommand,
 					dist:     distance,
-					args:     jj.BookmarkTrack(b.Name, m.defaultTrackRemote()),
+					remote:   m.defaultTrackRemote(),
+					args:     jj.BookmarkTrack(m.defaultTrackRemote(), b.Name),
 				})
 			}
 
@@ -286,24 +304,44 @@ func (m *Model) loadAll() tea.Msg {
 				if remote.Tracked {
 					items = append(items, item{
 						name:     fmt.Sprintf("untrack '%s'", nameWithRemote),
+						bookmark: b.Name,
 						priority: untrackCommand,
 						dist:     distance,
-						args:     jj.BookmarkUntrack(b.Name, remote.Remote),
+						remote:   remote.Remote,
+						args:     jj.BookmarkUntrack(remote.Remote, b.Name),
 					})
 				} else {
 					items = append(items, item{
 						name:     fmt.Sprintf("track '%s'", nameWithRemote),
+						bookmark: b.Name,
 						priority: trackCommand,
 						dist:     distance,
-						args:     jj.BookmarkTrack(b.Name, remote.Remote),
+						remote:   remote.Remote,
+						args:     jj.BookmarkTrack(remote.Remote, b.Name),
 					})
 				}
 			}
 		}
-		return updateItemsMsg{items: items}
+		return updateItemsMsg{items: items, syncs: syncs}
 	}
 }
 
+// loadSyncs counts how far each local bookmark is ahead of or behind its tracked remote.
+func (m *Model) loadSyncs(bookmarks []jj.Bookmark) map[string]jj.BookmarkSync {
+	tracking := slices.ContainsFunc(bookmarks, func(b jj.Bookmark) bool {
+		_, ok := b.TrackedRemote()
+		return ok
+	})
+	if !tracking {
+		return nil
+	}
+	output, err := m.context.RunCommandImmediate(jj.BookmarkSyncList())
+	if err != nil {
+		return nil
+	}
+	return jj.ParseBookmarkSyncOutput(bookmarks, string(output))
+}
+
 func (m *Model) Update(msg tea.Msg) tea.Cmd {
 	switch msg := msg.(type) {
 	case itemClickMsg:
@@ -330,6 +368,11 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 	case intents.Intent:
 		return m.handleIntent(msg)
 	case tea.KeyMsg, tea.PasteMsg:
+		if m.prompt != promptNone {
+			var cmd tea.Cmd
+			m.promptInput, cmd = m.promptInput.Update(msg)
+			return cmd
+		}
 		if m.filterState == filterEditing {
 			updated, cmd := m.filterInput.Update(msg)
 			filterChanged := m.filterInput.Value() != updated.Value()
@@ -347,6 +390,7 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			return cmd
 		}
 	case updateItemsMsg:
+		maps.Copy(m.syncs, msg.syncs)
 		m.allItems = append(m.allItems, msg.items...)
 		slices.SortFunc(m.allItems, itemSorter)
 		return m.updateMenuForRemote()
@@ -357,6 +401,9 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 func (m *Model) handleIntent(intent intents.Intent) tea.Cmd {
 	switch msg := intent.(type) {
 	case intents.Apply:
+		if m.prompt != promptNone {
+			return m.applyPrompt()
+		}
 		if m.filterState == filterEditing {
 			m.filterText = strings.TrimSpace(m.filterInput.Value())
 			if m.filterText == "" {
@@ -370,11 +417,28 @@ func (m *Model) handleIntent(intent intents.Intent) tea.Cmd {
 			m.applyFilters(true)
 			return nil
 		}
+		if len(m.selected) > 0 {
+			return m.runSelected()
+		}
 		selected, ok := m.selectedItem()
 		if !ok {
 			return nil
 		}
 		return m.context.RunCommand(selected.args, common.Refresh, common.CloseApplied)
+	case intents.BookmarksToggleSelect:
+		m.toggleSelected()
+		return nil
+	case intents.BookmarksRename:
+		selected, ok := m.selectedItem()
+		if !ok || selected.bookmark == "" {
+			return nil
+		}
+		m.promptTarget = selected.bookmark
+		return m.openPrompt(promptRename, fmt.Sprintf("Rename '%s' to: ", selected.bookmark), selected.bookmark)
+	case intents.BookmarksCreate:
+		return m.openPrompt(promptCreateName, "New bookmark: ", "")
+	case intents.BookmarksTrackGlob:
+		return m.openPrompt(promptTrackGlob, fmt.Sprintf("Track glob on %s: ", m.globTrackRemote()), "")
 	case intents.BookmarksFilter:
 		filter := string(msg.Kind)
 		if filter == "" {
@@ -384,6 +448,18 @@ func (m *Model) handleIntent(intent intents.Intent) tea.Cmd {
 			return m.executeDefaultForFilter(msg.Kind)
 		}
 		return m.filtered(filter)
+	case intents.BookmarksSyncFilter:
+		if m.syncFilter == msg.Kind {
+			m.syncFilter = ""
+		} else {
+			m.syncFilter = msg.Kind
+		}
+		m.applyFilters(true)
+		return nil
+	case intents.BookmarksToggleSyncSort:
+		m.sortBySync = !m.sortBySync
+		m.applyFilters(true)
+		return nil
 	case intents.BookmarksCycleRemotes:
 		return m.cycleRemotes(msg.Delta)
 	case intents.BookmarksOpenFilter:
@@ -400,6 +476,10 @@ func (m *Model) handleIntent(intent intents.Intent) tea.Cmd {
 		m.moveCursor(msg.Delta)
 		return nil
 	case intents.Cancel:
+		if m.prompt != promptNone {
+			m.closePrompt()
+			return nil
+		}
 		if m.filterState == filterEditing {
 			m.resetTextFilter()
 			return nil
@@ -408,6 +488,10 @@ func (m *Model) handleIntent(intent intents.Intent) tea.Cmd {
 			m.resetAllFilters()
 			return nil
 		}
+		if len(m.selected) > 0 {
+			clear(m.selected)
+			return nil
+		}
 		return common.Close
 	case intents.BookmarksApplyShortcut:
 		if m.categoryFilter == "" {
@@ -484,7 +568,10 @@ func (m *Model) ViewRect(dl *render.DisplayContext, box layout.Box) {
 
 	_, contentBox = contentBox.CutTop(1)
 	filterBox, contentBox := contentBox.CutTop(1)
-	if m.filterState == filterEditing {
+	if m.prompt != promptNone {
+		m.promptInput.SetWidth(max(contentBox.R.Dx()-2, 0))
+		dl.AddDraw(filterBox.R, m.promptInput.View(), render.ZMenuContent)
+	} else if m.filterState == filterEditing {
 		m.filterInput.SetWidth(max(contentBox.R.Dx()-2, 0))
 		dl.AddDraw(filterBox.R, m.filterInput.View(), render.ZMenuContent)
 	} else {
@@ -572,22 +659,30 @@ func NewModel(c *context.MainContext, current *jj.Commit, commitIds []string) *M
 		listRenderer:      render.NewListRenderer(itemScrollMsg{}),
 		title:             "Bookmark Operations",
 		allItems:          make([]item, 0),
+		syncs:             make(map[string]jj.BookmarkSync),
+		selected:          make(map[string]bool),
 	}
 	m.listRenderer.Z = render.ZMenuContent
 
-	m.filterInput = textinput.New()
-	m.filterInput.Prompt = "Filter: "
-	fis := m.filterInput.Styles()
-	fis.Focused.Prompt = m.menuStyles.matched.PaddingLeft(1)
-	fis.Focused.Text = m.menuStyles.text
-	fis.Blurred.Prompt = m.menuStyles.matched.PaddingLeft(1)
-	fis.Blurred.Text = m.menuStyles.text
-	m.filterInput.SetStyles(fis)
+	m.filterInput = m.newInput("Filter: ")
+	m.promptInput = m.newInput("")
 	m.applyFilters(true)
 
 	return m
 }
 
+func (m *Model) newInput(prompt string) textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = prompt
+	styles := ti.Styles()
+	styles.Focused.Prompt = m.menuStyles.matched.PaddingLeft(1)
+	styles.Focused.Text = m.menuStyles.text
+	styles.Blurred.Prompt = m.menuStyles.matched.PaddingLeft(1)
+	styles.Blurred.Text = m.menuStyles.text
+	ti.SetStyles(styles)
+	return ti
+}
+
 func createMenuStyles(prefix string) menuStyles {
 	if prefix != "" {
 		prefix += " "
@@ -638,7 +733,7 @@ func (m *Model) moveCursor(delta int) {
 }
 
 func (m *Model) hasActiveFilter() bool {
-	return m.categoryFilter != "" || m.currentFilterText() != ""
+	return m.categoryFilter != "" || m.syncFilter != "" || m.currentFilterText() != ""
 }
 
 func (m *Model) currentFilterText() string {
@@ -658,6 +753,7 @@ func (m *Model) resetTextFilter() {
 
 func (m *Model) resetAllFilters() {
 	m.categoryFilter = ""
+	m.syncFilter = ""
 	m.resetTextFilter()
 }
 
@@ -674,6 +770,16 @@ func (m *Model) applyFilters(resetCursor bool) {
 		items = filtered
 	}
 
+	if m.syncFilter != "" {
+		filtered := make([]item, 0, len(items))
+		for _, item := range items {
+			if m.syncFilterMatch(item, m.syncFilter) {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
 	filterText := m.currentFilterText()
 	if filterText != "" {
 		filtered := make([]item, 0, len(items))
@@ -685,6 +791,13 @@ func (m *Model) applyFilters(resetCursor bool) {
 		items = filtered
 	}
 
+	if m.sortBySync {
+		items = slices.Clone(items)
+		slices.SortStableFunc(items, func(a item, b item) int {
+			return m.syncRank(a) - m.syncRank(b)
+		})
+	}
+
 	m.filteredItems = items
 	if resetCursor || m.cursor >= len(m.filteredItems) {
 		m.cursor = 0
@@ -702,6 +815,47 @@ func (m *Model) categoryFilterMatch(item item, filter string) bool {
 	return true
 }
 
+func (m *Model) syncFilterMatch(item item, filter intents.BookmarksSyncFilterKind) bool {
+	sync, ok := m.syncs[item.bookmark]
+	if !ok {
+		return false
+	}
+	switch filter {
+	case intents.BookmarksSyncNeedsPush:
+		return sync.NeedsPush()
+	case intents.BookmarksSyncNeedsPull:
+		return sync.NeedsPull()
+	case intents.BookmarksSyncDiverged:
+		return sync.Diverged() || sync.Conflict
+	}
+	return true
+}
+
+// syncRank orders bookmarks that need attention first: conflicted or diverged,
+// then those needing a push, then a pull, then in sync and finally untracked ones.
+func (m *Model) syncRank(item item) int {
+	sync, ok := m.syncs[item.bookmark]
+	switch {
+	case !ok:
+		return 4
+	case sync.Conflict || sync.Diverged():
+		return 0
+	case sync.NeedsPush():
+		return 1
+	case sync.NeedsPull():
+		return 2
+	default:
+		return 3
+	}
+}
+
+func (m *Model) syncLabel(item item) string {
+	if sync, ok := m.syncs[item.bookmark]; ok {
+		return sync.String()
+	}
+	return ""
+}
+
 func (m *Model) textFilterMatch(item item, filter string) bool {
 	filter = strings.TrimSpace(filter)
 	if filter == "" {
@@ -760,6 +914,21 @@ func (m *Model) renderFilterView(dl *render.DisplayContext, box layout.Box) {
 		labelStyle.Render("remote"),
 	}
 
+	if m.syncFilter != "" {
+		parts = append(parts,
+			labelStyle.Render("that"),
+			valueStyle.Render(string(m.syncFilter)),
+		)
+	}
+
+	if m.sortBySync {
+		parts = append(parts, labelStyle.Render("sorted by"), valueStyle.Render("sync"))
+	}
+
+	if len(m.selected) > 0 {
+		parts = append(parts, labelStyle.Render("with"), valueStyle.Render(fmt.Sprintf("%d selected", len(m.selected))))
+	}
+
 	filterText := m.currentFilterText()
 	if filterText != "" {
 		parts = append(parts,
@@ -796,7 +965,7 @@ func (m *Model) renderList(dl *render.DisplayContext, listBox layout.Box) {
 			if index < 0 || index >= itemCount {
 				return
 			}
-			renderItem(dl, rect, listWidth, m.menuStyles, m.categoryFilter != "", m.cursor, index, items[index])
+			renderItem(dl, rect, listWidth, m.menuStyles, m.categoryFilter != "", m.cursor, index, items[index], m.selected[items[index].name], m.syncLabel(items[index]))
 		},
 		func(index int, _ tea.Mouse) tea.Msg { return itemClickMsg{Index: index} },
 	)
@@ -804,12 +973,15 @@ func (m *Model) renderList(dl *render.DisplayContext, listBox layout.Box) {
 	m.ensureCursorVisible = false
 }
 
-func renderItem(dl *render.DisplayContext, rect layout.Rectangle, width int, styles menuStyles, showShortcuts bool, cursor int, index int, item item) {
+func renderItem(dl *render.DisplayContext, rect layout.Rectangle, width int, styles menuStyles, showShortcuts bool, cursor int, index int, item item, selected bool, syncLabel string) {
 	var (
 		title string
 		desc  string
 	)
 	title = item.Title()
+	if selected {
+		title = "✓ " + title
+	}
 	desc = item.Description()
 	shortcut := ""
 	if showShortcuts {
@@ -819,13 +991,14 @@ func renderItem(dl *render.DisplayContext, rect layout.Rectangle, width int, sty
 		return
 	}
 
-	if len(title) > width {
-		title = title[:width-1] + "…"
+	titleWidth := width
+	if syncLabel != "" {
+		titleWidth = max(width-lipgloss.Width(syncLabel)-1, 1)
 	}
 
-	if len(desc) > width {
-		desc = desc[:width-1] + "…"
-	}
+	// the selection mark and bookmark names aren't ASCII, cut them by cells
+	title = ansi.Truncate(title, titleWidth, "…")
+	desc = ansi.Truncate(desc, width, "…")
 
 	titleStyle := styles.text
 	descStyle := styles.dimmed
@@ -843,6 +1016,14 @@ func renderItem(dl *render.DisplayContext, rect layout.Rectangle, width int, sty
 	} else {
 		titleLine = titleStyle.PaddingLeft(1).Render(title)
 	}
+	if syncLabel != "" {
+		syncStyle := styles.matched
+		if index == cursor {
+			syncStyle = syncStyle.Background(styles.selected.GetBackground())
+		}
+		syncWidth := max(width+1-lipgloss.Width(titleLine), 0)
+		titleLine = lipgloss.JoinHorizontal(0, titleLine, lipgloss.PlaceHorizontal(syncWidth, lipgloss.Right, syncStyle.Render(syncLabel), lipgloss.WithWhitespaceStyle(titleStyle)))
+	}
 	titleLine = lipgloss.PlaceHorizontal(width+2, 0, titleLine, lipgloss.WithWhitespaceStyle(titleStyle))
 
 	descStyle = descStyle.PaddingLeft(1).PaddingRight(1).Width(width + 2)
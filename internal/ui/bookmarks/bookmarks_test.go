@@ -4,8 +4,10 @@ import (
 	"slices"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/intents"
 	"github.com/idursun/jjui/internal/ui/layout"
@@ -111,3 +113,63 @@ func Test_FilterEditing_AcceptsPasteMsg(t *testing.T) {
 
 	assert.Equal(t, "track feature", op.filterInput.Value())
 }
+
+func Test_SyncFilterAndSort(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.GitRemoteList()).SetOutput([]byte("origin"))
+	commandRunner.Expect(jj.BookmarkListAll()).SetOutput([]byte(`ahead;.;false;false;false;a
+ahead;origin;true;false;false;b
+behind;.;false;false;false;c
+behind;origin;true;false;false;d
+diverged;.;false;false;false;e
+diverged;origin;true;false;false;f
+local;.;false;false;false;g`))
+	commandRunner.Expect(jj.BookmarkSyncList()).SetOutput([]byte(`ahead;origin;2;0
+behind;origin;0;1
+diverged;origin;1;1`))
+	commandRunner.Expect(jj.BookmarkListMovable("abc123")).SetOutput([]byte(""))
+	defer commandRunner.Verify()
+
+	commit := &jj.Commit{ChangeId: "abc123", CommitId: "commit123"}
+	op := NewModel(test.NewTestContext(commandRunner), commit, []string{"commit123"})
+	test.SimulateModel(op, op.Init())
+
+	bookmarkNames := func() []string {
+		var names []string
+		for _, i := range op.visibleItems() {
+			if !slices.Contains(names, i.bookmark) {
+				names = append(names, i.bookmark)
+			}
+		}
+		return names
+	}
+
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksSyncFilter{Kind: intents.BookmarksSyncNeedsPush}))
+	assert.Equal(t, []string{"ahead"}, bookmarkNames())
+
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksSyncFilter{Kind: intents.BookmarksSyncDiverged}))
+	assert.Equal(t, []string{"diverged"}, bookmarkNames())
+
+	// selecting the same filter again turns it off
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksSyncFilter{Kind: intents.BookmarksSyncDiverged}))
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksToggleSyncSort{}))
+	assert.Equal(t, []string{"diverged", "ahead", "behind", "local"}, bookmarkNames())
+
+	rendered := test.RenderImmediate(op, 100, 40)
+	assert.Contains(t, rendered, "↑1 ↓1 diverged")
+}
+
+func Test_RenderItem_TruncatesNonASCIITitlesByWidth(t *testing.T) {
+	dl := render.NewDisplayContext()
+	rect := layout.Rect(0, 0, 14, 3)
+	it := item{name: "move 'äöüß-bookmark'", args: []string{"bookmark", "move", "äöüß-bookmark"}}
+	renderItem(dl, rect, 12, menuStyles{}, false, 0, 1, it, true, "↑2")
+
+	rendered := dl.RenderToString(rect.Dx(), rect.Dy())
+	assert.True(t, utf8.ValidString(rendered))
+	lines := strings.Split(rendered, "\n")
+	assert.Equal(t, "✓ move '…", strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(lines[0]), "↑2")))
+	for _, line := range lines {
+		assert.LessOrEqual(t, ansi.StringWidth(line), rect.Dx())
+	}
+}
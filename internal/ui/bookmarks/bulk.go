@@ -0,0 +1,154 @@
+package bookmarks
+
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/intents"
+)
+
+type promptKind int
+
+const (
+	promptNone promptKind = iota
+	promptRename
+	promptCreateName
+	promptCreateRevision
+	promptTrackGlob
+)
+
+func (m *Model) openPrompt(kind promptKind, prompt string, value string) tea.Cmd {
+	m.prompt = kind
+	m.promptInput.Prompt = prompt
+	m.promptInput.SetValue(value)
+	m.promptInput.Focus()
+	m.promptInput.CursorEnd()
+	return textinput.Blink
+}
+
+func (m *Model) closePrompt() {
+	m.prompt = promptNone
+	m.promptTarget = ""
+	m.promptInput.SetValue("")
+	m.promptInput.Blur()
+}
+
+func (m *Model) applyPrompt() tea.Cmd {
+	value := strings.TrimSpace(m.promptInput.Value())
+	kind, target := m.prompt, m.promptTarget
+	// keep the prompt open so that a name rejected by the naming policy can be fixed
+	if (kind == promptRename && value != target || kind == promptCreateName) && value != "" {
+		if err := config.ValidateBookmarkName(config.Current, value); err != nil {
+			return intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err})
+		}
+	}
+	m.closePrompt()
+	if value == "" {
+		return nil
+	}
+
+	switch kind {
+	case promptRename:
+		if value == target {
+			return nil
+		}
+		return m.context.RunCommand(jj.BookmarkRename(target, value), common.Refresh, common.CloseApplied)
+	case promptCreateName:
+		// ask where the new bookmark should point, defaulting to the selected revision
+		m.promptTarget = value
+		return m.openPrompt(promptCreateRevision, "At revision: ", m.current.GetChangeId())
+	case promptCreateRevision:
+		return m.context.RunCommand(jj.BookmarkCreate(value, target), common.Refresh, common.CloseApplied)
+	case promptTrackGlob:
+		return m.context.RunCommand(jj.BookmarkTrack(m.globTrackRemote(), "glob:"+value), common.Refresh, common.CloseApplied)
+	}
+	return nil
+}
+
+// globTrackRemote is the selected remote, or the default one when local bookmarks are shown.
+func (m *Model) globTrackRemote() string {
+	if len(m.remoteNames) > 0 && m.selectedRemoteIdx < len(m.remoteNames) {
+		if remote := m.remoteNames[m.selectedRemoteIdx]; remote != "local" {
+			return remote
+		}
+	}
+	return m.defaultTrackRemote()
+}
+
+func (m *Model) toggleSelected() {
+	selected, ok := m.selectedItem()
+	if !ok || selected.priority == moveCommand {
+		return
+	}
+	if m.selected[selected.name] {
+		delete(m.selected, selected.name)
+	} else {
+		m.selected[selected.name] = true
+		// a bookmark is either deleted or forgotten, selecting one drops the other
+		if selected.priority == deleteCommand || selected.priority == forgetCommand {
+			for _, listItem := range m.allItems {
+				if listItem.bookmark == selected.bookmark && listItem.name != selected.name &&
+					(listItem.priority == deleteCommand || listItem.priority == forgetCommand) {
+					delete(m.selected, listItem.name)
+				}
+			}
+		}
+	}
+	m.moveCursor(1)
+}
+
+// runSelected runs the selected operations, combining operations of the same
+// kind (and remote) into a single jj invocation.
+func (m *Model) runSelected() tea.Cmd {
+	var selected []item
+	for _, listItem := range m.allItems {
+		if m.selected[listItem.name] {
+			selected = append(selected, listItem)
+		}
+	}
+	commands := bulkCommands(selected)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	next := tea.Batch(common.Refresh, common.CloseApplied)
+	for i := len(commands) - 1; i >= 0; i-- {
+		next = m.context.RunCommand(commands[i], next)
+	}
+	return next
+}
+
+func bulkCommands(items []item) []jj.CommandArgs {
+	type group struct {
+		priority commandType
+		remote   string
+	}
+	var order []group
+	names := make(map[group][]string)
+	for _, listItem := range items {
+		g := group{priority: listItem.priority, remote: listItem.remote}
+		if _, ok := names[g]; !ok {
+			order = append(order, g)
+		}
+		names[g] = append(names[g], listItem.bookmark)
+	}
+
+	var commands []jj.CommandArgs
+	for _, g := range order {
+		switch g.priority {
+		case deleteCommand:
+			commands = append(commands, jj.BookmarkDelete(names[g]...))
+		case forgetCommand:
+			commands = append(commands, jj.BookmarkForget(names[g]...))
+		case trackCommand:
+			commands = append(commands, jj.BookmarkTrack(g.remote, names[g]...))
+		case untrackCommand:
+			commands = append(commands, jj.BookmarkUntrack(g.remote, names[g]...))
+		}
+	}
+	return commands
+}
@@ -0,0 +1,150 @@
+package bookmarks
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const bulkBookmarkList = `alpha;.;false;false;false;a
+beta;.;false;false;false;b
+gamma;.;false;false;false;c`
+
+func newBulkModel(t *testing.T, commandRunner *test.CommandRunner) *Model {
+	commandRunner.Expect(jj.GitRemoteList()).SetOutput([]byte(""))
+	commandRunner.Expect(jj.BookmarkListAll()).SetOutput([]byte(bulkBookmarkList))
+	commandRunner.Expect(jj.BookmarkListMovable("abc123")).SetOutput([]byte(""))
+
+	commit := &jj.Commit{ChangeId: "abc123", CommitId: "commit123"}
+	op := NewModel(test.NewTestContext(commandRunner), commit, []string{"commit123"})
+	test.SimulateModel(op, op.Init())
+	return op
+}
+
+func typeText(op *Model, text string) {
+	test.SimulateModel(op, func() tea.Msg { return tea.PasteMsg{Content: text} })
+}
+
+func Test_BulkDelete_RunsSingleCommand(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	op := newBulkModel(t, commandRunner)
+	commandRunner.Expect(jj.BookmarkDelete("alpha", "gamma"))
+	defer commandRunner.Verify()
+
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksFilter{Kind: intents.BookmarksFilterDelete}))
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksToggleSelect{}))
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksNavigate{Delta: 1}))
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksToggleSelect{}))
+	assert.Len(t, op.selected, 2)
+
+	test.SimulateModel(op, intents.Invoke(intents.Apply{}))
+}
+
+func Test_BulkCommands_GroupsByKindAndRemote(t *testing.T) {
+	commands := bulkCommands([]item{
+		{bookmark: "a", priority: deleteCommand},
+		{bookmark: "b", priority: trackCommand, remote: "origin"},
+		{bookmark: "c", priority: trackCommand, remote: "upstream"},
+		{bookmark: "d", priority: deleteCommand},
+		{bookmark: "e", priority: trackCommand, remote: "origin"},
+	})
+	assert.Equal(t, []jj.CommandArgs{
+		jj.BookmarkDelete("a", "d"),
+		jj.BookmarkTrack("origin", "b", "e"),
+		jj.BookmarkTrack("upstream", "c"),
+	}, commands)
+}
+
+func Test_Rename(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	op := newBulkModel(t, commandRunner)
+	commandRunner.Expect(jj.BookmarkRename("alpha", "alpha-renamed"))
+	defer commandRunner.Verify()
+
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksRename{}))
+	assert.True(t, op.IsEditing())
+	assert.Equal(t, "alpha", op.promptInput.Value())
+	typeText(op, "-renamed")
+	test.SimulateModel(op, intents.Invoke(intents.Apply{}))
+	assert.False(t, op.IsEditing())
+}
+
+func Test_Create_AsksForNameAndRevision(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	op := newBulkModel(t, commandRunner)
+	commandRunner.Expect(jj.BookmarkCreate("abc123-", "feature"))
+	defer commandRunner.Verify()
+
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksCreate{}))
+	typeText(op, "feature")
+	test.SimulateModel(op, intents.Invoke(intents.Apply{}))
+	assert.Equal(t, promptCreateRevision, op.prompt)
+	assert.Equal(t, "abc123", op.promptInput.Value())
+	typeText(op, "-")
+	test.SimulateModel(op, intents.Invoke(intents.Apply{}))
+}
+
+func Test_CreateAndRename_EnforceNamingPolicy(t *testing.T) {
+	previous := config.Current.Bookmarks.NamePattern
+	defer func() { config.Current.Bookmarks.NamePattern = previous }()
+	config.Current.Bookmarks.NamePattern = `^feat/`
+
+	commandRunner := test.NewTestCommandRunner(t)
+	op := newBulkModel(t, commandRunner)
+	commandRunner.Expect(jj.BookmarkRename("alpha", "feat/alpha"))
+	defer commandRunner.Verify()
+
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksCreate{}))
+	typeText(op, "feature")
+	msg, ok := op.Update(intents.Apply{})().(intents.AddMessage)
+	assert.True(t, ok)
+	assert.Error(t, msg.Err)
+	assert.Equal(t, promptCreateName, op.prompt, "the prompt stays open to fix the name")
+	test.SimulateModel(op, intents.Invoke(intents.Cancel{}))
+
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksRename{}))
+	op.promptInput.SetValue("feat/alpha")
+	test.SimulateModel(op, intents.Invoke(intents.Apply{}))
+}
+
+func Test_TrackGlob(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	op := newBulkModel(t, commandRunner)
+	commandRunner.Expect(jj.BookmarkTrack("origin", "glob:feature/*"))
+	defer commandRunner.Verify()
+
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksTrackGlob{}))
+	typeText(op, "feature/*")
+	test.SimulateModel(op, intents.Invoke(intents.Apply{}))
+}
+
+func Test_CancelPrompt_KeepsViewOpen(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	op := newBulkModel(t, commandRunner)
+	defer commandRunner.Verify()
+
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksCreate{}))
+	cmd := op.Update(intents.Cancel{})
+	assert.Nil(t, cmd)
+	assert.False(t, op.IsEditing())
+}
+
+func Test_BulkSelect_DeleteAndForgetAreExclusive(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	op := newBulkModel(t, commandRunner)
+	commandRunner.Expect(jj.BookmarkForget("alpha"))
+	defer commandRunner.Verify()
+
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksFilter{Kind: intents.BookmarksFilterDelete}))
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksToggleSelect{}))
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksFilter{Kind: intents.BookmarksFilterForget}))
+	test.SimulateModel(op, intents.Invoke(intents.BookmarksToggleSelect{}))
+	assert.Len(t, op.selected, 1, "forgetting alpha replaces deleting it")
+
+	test.SimulateModel(op, intents.Invoke(intents.Apply{}))
+}
@@ -0,0 +1,282 @@
+package bookmarks
+
+import (
+	"fmt"
+	"slices"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/actions"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/internal/ui/layout"
+	"github.com/idursun/jjui/internal/ui/render"
+)
+
+type updateStaleMsg struct {
+	candidates []jj.StaleBookmark
+	err        error
+}
+
+var _ common.ImmediateModel = (*CleanupModel)(nil)
+
+// CleanupModel lists bookmarks that are likely no longer needed and lets the
+// user delete, forget or untrack a selection of them in one go.
+type CleanupModel struct {
+	context             *context.MainContext
+	candidates          []jj.StaleBookmark
+	selected            map[int]bool
+	cursor              int
+	loading             bool
+	err                 error
+	listRenderer        *render.ListRenderer
+	ensureCursorVisible bool
+	menuStyles          menuStyles
+}
+
+func NewCleanupModel(c *context.MainContext) *CleanupModel {
+	m := &CleanupModel{
+		context:      c,
+		selected:     make(map[int]bool),
+		loading:      true,
+		listRenderer: render.NewListRenderer(itemScrollMsg{}),
+		menuStyles:   createMenuStyles("bookmarks"),
+	}
+	m.listRenderer.Z = render.ZMenuContent
+	return m
+}
+
+func (m *CleanupModel) StackedActionOwner() string {
+	return actions.OwnerBookmarksCleanup
+}
+
+func (m *CleanupModel) Init() tea.Cmd {
+	return m.load
+}
+
+func (m *CleanupModel) load() tea.Msg {
+	output, err := m.context.RunCommandImmediate(jj.BookmarkListStale())
+	if err != nil {
+		return updateStaleMsg{err: err}
+	}
+	return updateStaleMsg{candidates: jj.ParseStaleBookmarkListOutput(string(output))}
+}
+
+func (m *CleanupModel) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case updateStaleMsg:
+		m.loading = false
+		m.err = msg.err
+		m.candidates = msg.candidates
+		m.cursor = 0
+		clear(m.selected)
+	case itemClickMsg:
+		if msg.Index >= 0 && msg.Index < len(m.candidates) {
+			m.cursor = msg.Index
+			m.toggle(msg.Index)
+		}
+	case itemScrollMsg:
+		if msg.Horizontal {
+			return nil
+		}
+		m.ensureCursorVisible = false
+		m.listRenderer.StartLine = max(m.listRenderer.StartLine+msg.Delta, 0)
+	case intents.Intent:
+		return m.handleIntent(msg)
+	}
+	return nil
+}
+
+func (m *CleanupModel) handleIntent(intent intents.Intent) tea.Cmd {
+	switch intent := intent.(type) {
+	case intents.BookmarksNavigate:
+		if len(m.candidates) == 0 {
+			return nil
+		}
+		m.cursor = min(max(m.cursor+intent.Delta, 0), len(m.candidates)-1)
+		m.ensureCursorVisible = true
+	case intents.BookmarksToggleSelect:
+		if m.cursor < len(m.candidates) {
+			m.toggle(m.cursor)
+			m.cursor = min(m.cursor+1, len(m.candidates)-1)
+			m.ensureCursorVisible = true
+		}
+	case intents.BookmarksSelectAll:
+		if len(m.selected) == len(m.candidates) {
+			clear(m.selected)
+			return nil
+		}
+		for i := range m.candidates {
+			m.selected[i] = true
+		}
+	case intents.BookmarksCleanup:
+		return m.run(intent.Kind)
+	case intents.Cancel:
+		return common.Close
+	}
+	return nil
+}
+
+func (m *CleanupModel) toggle(index int) {
+	if m.selected[index] {
+		delete(m.selected, index)
+	} else {
+		m.selected[index] = true
+	}
+}
+
+// targets returns the selected candidates, or the one under the cursor when nothing is selected.
+func (m *CleanupModel) targets() []jj.StaleBookmark {
+	var targets []jj.StaleBookmark
+	for i, candidate := range m.candidates {
+		if m.selected[i] {
+			targets = append(targets, candidate)
+		}
+	}
+	if len(targets) == 0 && m.cursor < len(m.candidates) {
+		targets = append(targets, m.candidates[m.cursor])
+	}
+	return targets
+}
+
+func (m *CleanupModel) run(kind intents.BookmarksFilterKind) tea.Cmd {
+	commands := cleanupCommands(kind, m.targets())
+	if len(commands) == 0 {
+		return intents.Invoke(intents.AddMessage{Text: fmt.Sprintf("nothing to %s in the selection", kind)})
+	}
+	next := tea.Batch(common.Refresh, common.CloseApplied)
+	for i := len(commands) - 1; i >= 0; i-- {
+		next = m.context.RunCommand(commands[i], next)
+	}
+	return next
+}
+
+// cleanupCommands builds a single jj command for delete and forget, and one
+// command per remote for untrack since jj only accepts one remote at a time.
+func cleanupCommands(kind intents.BookmarksFilterKind, targets []jj.StaleBookmark) []jj.CommandArgs {
+	var names []string
+	addName := func(name string) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	switch kind {
+	case intents.BookmarksFilterDelete:
+		for _, t := range targets {
+			if t.HasLocal {
+				addName(t.Name)
+			}
+		}
+		if len(names) > 0 {
+			return []jj.CommandArgs{jj.BookmarkDelete(names...)}
+		}
+	case intents.BookmarksFilterForget:
+		for _, t := range targets {
+			addName(t.Name)
+		}
+		if len(names) > 0 {
+			return []jj.CommandArgs{jj.BookmarkForget(names...)}
+		}
+	case intents.BookmarksFilterUntrack:
+		var remotes []string
+		byRemote := make(map[string][]string)
+		for _, t := range targets {
+			if t.Remote == "" || !t.HasLocal {
+				continue
+			}
+			if _, ok := byRemote[t.Remote]; !ok {
+				remotes = append(remotes, t.Remote)
+			}
+			byRemote[t.Remote] = append(byRemote[t.Remote], t.Name)
+		}
+		var commands []jj.CommandArgs
+		for _, remote := range remotes {
+			commands = append(commands, jj.BookmarkUntrack(remote, byRemote[remote]...))
+		}
+		return commands
+	}
+	return nil
+}
+
+func (m *CleanupModel) ViewRect(dl *render.DisplayContext, box layout.Box) {
+	pw, ph := box.R.Dx(), box.R.Dy()
+	contentWidth := max(min(pw, 80)-4, 0)
+	contentHeight := max(min(ph, 40)-4, 0)
+	frame := box.Center(contentWidth+2, contentHeight+2)
+	if frame.R.Dx() <= 0 || frame.R.Dy() <= 0 {
+		return
+	}
+
+	dl.AddBackdrop(box.R, render.ZMenuBorder-1)
+	contentBox := frame.Inset(1)
+	if contentBox.R.Dx() <= 0 || contentBox.R.Dy() <= 0 {
+		return
+	}
+	dl.AddFill(contentBox.R, ' ', m.menuStyles.text, render.ZMenuContent)
+
+	borderBase := lipgloss.NewStyle().Width(contentBox.R.Dx()).Height(contentBox.R.Dy()).Render("")
+	dl.AddDraw(frame.R, m.menuStyles.border.Render(borderBase), render.ZMenuBorder)
+
+	titleBox, contentBox := contentBox.CutTop(1)
+	dl.Text(titleBox.R.Min.X, titleBox.R.Min.Y, render.ZMenuContent).
+		Styled("Stale Bookmarks", m.menuStyles.title).
+		Done()
+
+	_, contentBox = contentBox.CutTop(1)
+	summaryBox, contentBox := contentBox.CutTop(1)
+	dl.Text(summaryBox.R.Min.X, summaryBox.R.Min.Y, render.ZMenuContent).
+		Styled(" "+m.summary(), m.menuStyles.dimmed).
+		Done()
+
+	_, listBox := contentBox.CutTop(1)
+	m.renderList(dl, listBox)
+}
+
+func (m *CleanupModel) summary() string {
+	switch {
+	case m.loading:
+		return "Looking for stale bookmarks…"
+	case m.err != nil:
+		return m.err.Error()
+	case len(m.candidates) == 0:
+		return "No stale bookmarks found"
+	}
+	return fmt.Sprintf("%d candidates, %d selected", len(m.candidates), len(m.selected))
+}
+
+func (m *CleanupModel) renderList(dl *render.DisplayContext, listBox layout.Box) {
+	itemCount := len(m.candidates)
+	if listBox.R.Dx() <= 0 || listBox.R.Dy() <= 0 || itemCount == 0 {
+		return
+	}
+	width := listBox.R.Dx()
+	const itemHeight = 2
+	m.listRenderer.StartLine = render.ClampStartLine(m.listRenderer.StartLine, listBox.R.Dy(), itemCount*itemHeight)
+	m.listRenderer.Render(
+		dl,
+		listBox,
+		itemCount,
+		m.cursor,
+		m.ensureCursorVisible,
+		func(_ int) int { return itemHeight },
+		func(dl *render.DisplayContext, index int, rect layout.Rectangle) {
+			candidate := m.candidates[index]
+			titleStyle, reasonStyle := m.menuStyles.text, m.menuStyles.dimmed
+			if index == m.cursor {
+				titleStyle, reasonStyle = m.menuStyles.selected, m.menuStyles.selected
+			}
+			check := "[ ]"
+			if m.selected[index] {
+				check = "[✓]"
+			}
+			title := titleStyle.PaddingLeft(1).Width(width).Render(check + " " + candidate.String())
+			reason := reasonStyle.PaddingLeft(5).Width(width).Render(candidate.Reason.String())
+			dl.AddDraw(rect, lipgloss.JoinVertical(lipgloss.Left, title, reason), render.ZMenuContent)
+		},
+		func(index int, _ tea.Mouse) tea.Msg { return itemClickMsg{Index: index} },
+	)
+	m.listRenderer.RegisterScroll(dl, listBox)
+	m.ensureCursorVisible = false
+}
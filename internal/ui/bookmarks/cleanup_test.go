@@ -0,0 +1,64 @@
+package bookmarks
+
+import (
+	"testing"
+
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const staleOutput = `merged;.;false;true;true;false;false
+abandoned;origin;false;true;false;false;true
+gone;.;true;true;false;false;false
+gone;origin;true;false;false;false;false`
+
+func newCleanupModel(t *testing.T, commandRunner *test.CommandRunner) *CleanupModel {
+	commandRunner.Expect(jj.BookmarkListStale()).SetOutput([]byte(staleOutput))
+	model := NewCleanupModel(test.NewTestContext(commandRunner))
+	test.SimulateModel(model, model.Init())
+	return model
+}
+
+func TestCleanup_ListsCandidatesWithReasons(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	model := newCleanupModel(t, commandRunner)
+	defer commandRunner.Verify()
+
+	rendered := test.RenderImmediate(model, 100, 40)
+	assert.Contains(t, rendered, "3 candidates, 0 selected")
+	assert.Contains(t, rendered, "merged into trunk()")
+	assert.Contains(t, rendered, "abandoned@origin")
+	assert.Contains(t, rendered, "deleted on remote")
+}
+
+func TestCleanup_ForgetSelectionInOneCommand(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	model := newCleanupModel(t, commandRunner)
+	commandRunner.Expect(jj.BookmarkForget("merged", "abandoned", "gone"))
+	defer commandRunner.Verify()
+
+	test.SimulateModel(model, intents.Invoke(intents.BookmarksSelectAll{}))
+	test.SimulateModel(model, intents.Invoke(intents.BookmarksCleanup{Kind: intents.BookmarksFilterForget}))
+}
+
+func TestCleanup_DeleteSkipsRemoteOnlyBookmarks(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	model := newCleanupModel(t, commandRunner)
+	commandRunner.Expect(jj.BookmarkDelete("merged", "gone"))
+	defer commandRunner.Verify()
+
+	test.SimulateModel(model, intents.Invoke(intents.BookmarksSelectAll{}))
+	test.SimulateModel(model, intents.Invoke(intents.BookmarksCleanup{Kind: intents.BookmarksFilterDelete}))
+}
+
+func TestCleanup_UntrackUsesCursorWithoutSelection(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	model := newCleanupModel(t, commandRunner)
+	commandRunner.Expect(jj.BookmarkUntrack("origin", "gone"))
+	defer commandRunner.Verify()
+
+	test.SimulateModel(model, intents.Invoke(intents.BookmarksNavigate{Delta: 2}))
+	test.SimulateModel(model, intents.Invoke(intents.BookmarksCleanup{Kind: intents.BookmarksFilterUntrack}))
+}
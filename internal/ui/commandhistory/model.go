@@ -1,10 +1,16 @@
 package commandhistory
 
 import (
+	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/atotto/clipboard"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/actions"
 	"github.com/idursun/jjui/internal/ui/common"
 	"github.com/idursun/jjui/internal/ui/context"
@@ -19,20 +25,73 @@ var _ common.StackedModel = (*Model)(nil)
 const historyWindowSize = 10
 const commandMarkWidth = 3
 
+// outputPreviewLines is how much output the selected entry shows in the list.
+const outputPreviewLines = 5
+
 type selectHistoryItemMsg struct {
 	index int
 }
 
+type historyFilter int
+
+const (
+	filterAll historyFilter = iota
+	filterFailed
+	filterSucceeded
+)
+
+func (f historyFilter) String() string {
+	switch f {
+	case filterFailed:
+		return "failed"
+	case filterSucceeded:
+		return "succeeded"
+	}
+	return "all"
+}
+
+func (f historyFilter) matches(entry flash.CommandHistoryEntry) bool {
+	switch f {
+	case filterFailed:
+		return entry.Err != nil
+	case filterSucceeded:
+		return entry.Err == nil
+	}
+	return true
+}
+
+// entryKey identifies the selected entry across reloads.
+type entryKey struct {
+	historyID uint64
+	earlier   int
+	runningID int
+}
+
+// row is a listed entry: a command of an earlier session, one of this
+// session or one that is still running.
+type row struct {
+	entry   flash.CommandHistoryEntry
+	running *context.RunningCommand
+	key     entryKey
+}
+
 type Model struct {
 	context       *context.MainContext
 	source        flash.CommandHistorySource
+	earlier       []flash.CommandHistoryEntry
 	items         []flash.CommandHistoryEntry
+	running       []context.RunningCommand
+	rows          []row
+	filter        historyFilter
+	showOutput    bool
+	outputOffset  int
 	selectedIndex int
 	windowStart   int
 	successStyle  lipgloss.Style
 	errorStyle    lipgloss.Style
 	textStyle     lipgloss.Style
 	matchedStyle  lipgloss.Style
+	dimmedStyle   lipgloss.Style
 }
 
 func New(context *context.MainContext, source flash.CommandHistorySource) *Model {
@@ -43,16 +102,91 @@ func New(context *context.MainContext, source flash.CommandHistorySource) *Model
 		errorStyle:   common.DefaultPalette.Get("flash error"),
 		textStyle:    common.DefaultPalette.Get("flash text"),
 		matchedStyle: common.DefaultPalette.Get("flash matched"),
+		dimmedStyle:  common.DefaultPalette.Get("flash dimmed"),
 	}
 	if source != nil {
-		m.items = source.CommandHistorySnapshot()
+		m.earlier = source.EarlierCommandHistory()
 	}
-	if len(m.items) > 0 {
-		m.selectedIndex = len(m.items) - 1
-		m.windowStart = max(0, len(m.items)-historyWindowSize)
+	m.reload()
+	m.selectNewest()
+	return m
+}
+
+func (m *Model) selectNewest() {
+	if count := m.count(); count > 0 {
+		m.selectedIndex = count - 1
+		m.windowStart = max(0, count-historyWindowSize)
 	}
 	m.clampViewport()
-	return m
+}
+
+// reload fetches the history and the running commands again. Earlier
+// sessions come first and running commands are listed last as the most
+// recent entries.
+func (m *Model) reload() {
+	if m.source != nil {
+		m.items = m.source.CommandHistorySnapshot()
+	}
+	if m.context != nil {
+		m.running = m.context.RunningCommands()
+	}
+	m.rebuildRows()
+}
+
+func (m *Model) rebuildRows() {
+	m.rows = m.rows[:0]
+	for i, entry := range m.earlier {
+		if m.filter.matches(entry) {
+			m.rows = append(m.rows, row{entry: entry, key: entryKey{earlier: i + 1}})
+		}
+	}
+	for _, entry := range m.items {
+		if m.filter.matches(entry) {
+			m.rows = append(m.rows, row{entry: entry, key: entryKey{historyID: entry.ID}})
+		}
+	}
+	if m.filter == filterAll {
+		for i := range m.running {
+			m.rows = append(m.rows, row{running: &m.running[i], key: entryKey{runningID: m.running[i].ID}})
+		}
+	}
+}
+
+// sync reloads the entries, keeping the selection on the same entry.
+func (m *Model) sync() {
+	key, selected := m.selectedKey()
+	m.reload()
+	if selected {
+		for i := range m.count() {
+			if k, _ := m.keyAt(i); k == key {
+				m.selectedIndex = i
+				break
+			}
+		}
+	}
+	m.clampViewport()
+}
+
+func (m *Model) count() int {
+	return len(m.rows)
+}
+
+func (m *Model) keyAt(index int) (entryKey, bool) {
+	if index < 0 || index >= m.count() {
+		return entryKey{}, false
+	}
+	return m.rows[index].key, true
+}
+
+func (m *Model) selectedKey() (entryKey, bool) {
+	return m.keyAt(m.selectedIndex)
+}
+
+func (m *Model) selectedRow() (row, bool) {
+	if m.selectedIndex < 0 || m.selectedIndex >= m.count() {
+		return row{}, false
+	}
+	return m.rows[m.selectedIndex], true
 }
 
 func (m *Model) Init() tea.Cmd {
@@ -66,24 +200,52 @@ func (m *Model) StackedActionOwner() string {
 func (m *Model) Update(msg tea.Msg) tea.Cmd {
 	switch msg := msg.(type) {
 	case intents.Intent:
+		m.sync()
 		switch intent := msg.(type) {
 		case intents.CommandHistoryNavigate:
-			if len(m.items) == 0 {
+			if m.showOutput {
+				m.outputOffset = max(0, m.outputOffset+intent.Delta)
+				return nil
+			}
+			if m.count() == 0 {
 				return nil
 			}
 			// History renders oldest->newest from bottom to top, so move selection
 			// opposite to delta to keep j moving visually down and k up.
-			m.selectedIndex = min(len(m.items)-1, max(0, m.selectedIndex-intent.Delta))
+			m.selectedIndex = min(m.count()-1, max(0, m.selectedIndex-intent.Delta))
 			m.clampViewport()
 			return nil
 		case intents.CommandHistoryDeleteSelected:
 			m.deleteSelected()
 			return nil
+		case intents.CommandHistoryCancelSelected:
+			return m.cancelSelected()
+		case intents.CommandHistoryRerunSelected:
+			return m.rerunSelected()
+		case intents.CommandHistoryCopySelected:
+			return m.copySelected()
+		case intents.CommandHistoryEditSelected:
+			return m.editSelected()
+		case intents.CommandHistoryToggleOutput:
+			if selected, ok := m.selectedRow(); ok && selected.running == nil {
+				m.showOutput = !m.showOutput
+				m.outputOffset = 0
+			}
+			return nil
+		case intents.CommandHistoryCycleFilter:
+			m.filter = (m.filter + 1) % 3
+			m.rebuildRows()
+			m.selectNewest()
+			return nil
 		case intents.CommandHistoryClose:
+			if m.showOutput {
+				m.showOutput = false
+				return nil
+			}
 			return common.Close
 		}
 	case selectHistoryItemMsg:
-		if msg.index < 0 || msg.index >= len(m.items) {
+		if msg.index < 0 || msg.index >= m.count() {
 			return nil
 		}
 		m.selectedIndex = msg.index
@@ -96,6 +258,7 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 }
 
 func (m *Model) ViewRect(dl *render.DisplayContext, box layout.Box) {
+	m.sync()
 	area := box.R
 	y := area.Max.Y - 1
 	maxWidth := area.Dx() - 4
@@ -103,8 +266,24 @@ func (m *Model) ViewRect(dl *render.DisplayContext, box layout.Box) {
 	rest, _ := box.CutBottom(1)
 	dl.AddDim(rest.R, render.ZOverlay)
 
+	if selected, ok := m.selectedRow(); ok && m.showOutput && selected.running == nil {
+		m.renderOutput(dl, rest, selected.entry)
+		return
+	}
+
+	if m.filter != filterAll {
+		label := m.dimmedStyle.Render("showing ") + m.matchedStyle.Render(m.filter.String())
+		w := lipgloss.Width(label)
+		dl.AddDraw(layout.Rect(area.Max.X-w-1, area.Min.Y, w, 1), label, render.ZOverlay)
+	}
+
 	for _, item := range m.window() {
-		content := m.renderEntry(item.entry, maxWidth, item.selected)
+		var content string
+		if item.running != nil {
+			content = m.renderRunning(*item.running, maxWidth, item.selected)
+		} else {
+			content = m.renderEntry(item.entry, maxWidth, item.selected)
+		}
 		w, h := lipgloss.Size(content)
 		y -= h
 		rect := layout.Rect(area.Max.X-w, y, w, h)
@@ -115,21 +294,23 @@ func (m *Model) ViewRect(dl *render.DisplayContext, box layout.Box) {
 
 type historyItem struct {
 	entry    flash.CommandHistoryEntry
+	running  *context.RunningCommand
 	index    int
 	selected bool
 }
 
 func (m *Model) window() []historyItem {
-	if len(m.items) == 0 {
+	if m.count() == 0 {
 		return nil
 	}
 	m.clampViewport()
 	start := m.windowStart
-	end := min(len(m.items), start+historyWindowSize)
+	end := min(m.count(), start+historyWindowSize)
 	items := make([]historyItem, 0, end-start)
 	for i := start; i < end; i++ {
 		items = append(items, historyItem{
-			entry:    m.items[i],
+			entry:    m.rows[i].entry,
+			running:  m.rows[i].running,
 			index:    i,
 			selected: i == m.selectedIndex,
 		})
@@ -138,13 +319,13 @@ func (m *Model) window() []historyItem {
 }
 
 func (m *Model) clampViewport() {
-	if len(m.items) == 0 {
+	if m.count() == 0 {
 		m.selectedIndex = 0
 		m.windowStart = 0
 		return
 	}
-	m.selectedIndex = min(len(m.items)-1, max(0, m.selectedIndex))
-	maxStart := max(0, len(m.items)-historyWindowSize)
+	m.selectedIndex = min(m.count()-1, max(0, m.selectedIndex))
+	maxStart := max(0, m.count()-historyWindowSize)
 	m.windowStart = min(m.selectedIndex, min(maxStart, max(0, m.windowStart)))
 	if m.selectedIndex >= m.windowStart+historyWindowSize {
 		m.windowStart = m.selectedIndex - historyWindowSize + 1
@@ -153,27 +334,109 @@ func (m *Model) clampViewport() {
 
 func (m *Model) deleteSelected() {
 	m.clampViewport()
-	if len(m.items) == 0 {
+	key, ok := m.selectedKey()
+	if !ok || key.historyID == 0 {
+		// running commands are canceled and earlier sessions are kept as a record
 		return
 	}
 
 	selected := m.selectedIndex
-	removed := m.items[selected]
-	m.items = append(m.items[:selected], m.items[selected+1:]...)
+	m.items = slices.DeleteFunc(m.items, func(entry flash.CommandHistoryEntry) bool {
+		return entry.ID == key.historyID
+	})
 	if m.source != nil {
-		m.source.DeleteCommandHistoryByID(removed.ID)
+		m.source.DeleteCommandHistoryByID(key.historyID)
 	}
+	m.rebuildRows()
 
-	if len(m.items) == 0 {
+	if m.count() == 0 {
 		m.selectedIndex = 0
 		m.windowStart = 0
 		return
 	}
 
-	m.selectedIndex = min(selected, len(m.items)-1)
+	m.selectedIndex = min(selected, m.count()-1)
 	m.clampViewport()
 }
 
+// cancelSelected kills the selected command if it is still running.
+func (m *Model) cancelSelected() tea.Cmd {
+	key, ok := m.selectedKey()
+	if !ok || key.runningID == 0 {
+		return nil
+	}
+	if err := m.context.CancelCommand(key.runningID); err != nil {
+		return intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err})
+	}
+	return nil
+}
+
+func (m *Model) rerunSelected() tea.Cmd {
+	selected, ok := m.selectedRow()
+	if !ok || selected.running != nil {
+		return nil
+	}
+	if len(selected.entry.Args) == 0 {
+		return intents.Invoke(intents.AddMessage{Text: "This command can't be rerun, edit it in the exec prompt instead"})
+	}
+	args := jj.Args(selected.entry.Args...)
+	if selected.entry.Interactive {
+		return m.context.RunInteractiveCommand(args, common.Refresh)
+	}
+	return m.context.RunCommand(args, common.Refresh)
+}
+
+func (m *Model) copySelected() tea.Cmd {
+	selected, ok := m.selectedRow()
+	if !ok {
+		return nil
+	}
+	command := selected.entry.Command
+	if selected.running != nil {
+		command = selected.running.Command
+	}
+	if err := clipboard.WriteAll(command); err != nil {
+		return intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err})
+	}
+	return intents.Invoke(intents.AddMessage{Text: "Copied to clipboard"})
+}
+
+// editSelected closes the history and puts the command into the exec prompt.
+func (m *Model) editSelected() tea.Cmd {
+	selected, ok := m.selectedRow()
+	if !ok || selected.running != nil {
+		return nil
+	}
+	if len(selected.entry.Args) == 0 {
+		return intents.Invoke(intents.AddMessage{Text: "This command reads its input, it can't be edited"})
+	}
+	line := jj.QuoteArgs(selected.entry.Args)
+	return tea.Sequence(common.Close, intents.Invoke(intents.ExecJJ{Line: line}))
+}
+
+func (m *Model) renderRunning(command context.RunningCommand, maxWidth int, selected bool) string {
+	elapsed := time.Since(command.Started).Truncate(time.Second)
+	mark := m.textStyle.Width(commandMarkWidth).Render("⋯ ")
+	parts := []string{mark + flash.ColorizeCommand(command.Command, m.textStyle, m.matchedStyle) + m.textStyle.Render(fmt.Sprintf(" %s", elapsed))}
+	if selected {
+		if key := config.GetBindingKeyHint(config.Current, "command_history.cancel_selected"); key != "" {
+			parts = append(parts, m.matchedStyle.Render(key)+m.textStyle.Render(" cancel"))
+		}
+	}
+
+	content := strings.Join(parts, "\n")
+	if render.BlockWidth(content) > maxWidth {
+		content = lipgloss.NewStyle().Width(maxWidth).Render(content)
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		PaddingLeft(1).
+		PaddingRight(1).
+		BorderForeground(m.textStyle.GetForeground()).
+		Render(content)
+}
+
 func (m *Model) renderEntry(entry flash.CommandHistoryEntry, maxWidth int, selected bool) string {
 	style := lipgloss.NewStyle()
 	if selected {
@@ -185,10 +448,20 @@ func (m *Model) renderEntry(entry flash.CommandHistoryEntry, maxWidth int, selec
 
 	parts := []string{m.renderCommandLine(entry.Command, entry.Err)}
 	if selected {
+		output, outputStyle := entry.Text, style
 		if entry.Err != nil {
-			parts = append(parts, m.errorStyle.Render(entry.Err.Error()))
-		} else if entry.Text != "" {
-			parts = append(parts, style.Render(entry.Text))
+			output, outputStyle = entry.Err.Error(), m.errorStyle
+		}
+		if output != "" {
+			lines := strings.Split(output, "\n")
+			if len(lines) > outputPreviewLines {
+				more := fmt.Sprintf("… %d more lines", len(lines)-outputPreviewLines)
+				if key := config.GetBindingKeyHint(config.Current, "command_history.toggle_output"); key != "" {
+					more += ", " + m.matchedStyle.Render(key) + m.dimmedStyle.Render(" full output")
+				}
+				lines = append(lines[:outputPreviewLines], m.dimmedStyle.Render(more))
+			}
+			parts = append(parts, outputStyle.Render(strings.Join(lines, "\n")))
 		}
 	}
 
@@ -1,10 +1,19 @@
 package commandhistory
 
 import (
+	"errors"
 	"fmt"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
 
 	"github.com/idursun/jjui/internal/ui/common"
+	appContext "github.com/idursun/jjui/internal/ui/context"
 	"github.com/idursun/jjui/internal/ui/flash"
 	"github.com/idursun/jjui/internal/ui/intents"
 	"github.com/idursun/jjui/internal/ui/layout"
@@ -123,3 +132,194 @@ func TestCommandHistory_CloseReturnsCloseViewMsg(t *testing.T) {
 	_, ok := cmd().(common.CloseViewMsg)
 	assert.True(t, ok)
 }
+
+func TestCommandHistory_ListsRunningCommandsAndCancelsSelected(t *testing.T) {
+	source := flash.New(test.NewTestContext(test.NewTestCommandRunner(t)))
+	source.AddWithCommand("output", "jj status", nil)
+
+	runner := test.NewTestCommandRunner(t)
+	runner.SetRunning(appContext.RunningCommand{ID: 7, Command: "jj git fetch", Started: time.Now()})
+	history := New(test.NewTestContext(runner), source)
+	assert.Equal(t, 1, history.selectedIndex, "newest entry is the running command")
+
+	dl := render.NewDisplayContext()
+	box := layout.NewBox(layout.Rect(0, 0, 60, 12))
+	history.ViewRect(dl, box)
+	rendered := dl.RenderToString(box.R.Dx(), box.R.Dy())
+	assert.Contains(t, rendered, "jj git fetch")
+	assert.Contains(t, rendered, "jj status")
+
+	history.Update(intents.CommandHistoryCancelSelected{})
+	assert.Equal(t, []int{7}, runner.Canceled())
+
+	history.Update(intents.CommandHistoryDeleteSelected{})
+	assert.Len(t, source.CommandHistorySnapshot(), 1, "running commands are not deleted")
+}
+
+func TestCommandHistory_FinishedCommandLeavesRunningList(t *testing.T) {
+	source := flash.New(test.NewTestContext(test.NewTestCommandRunner(t)))
+	runner := test.NewTestCommandRunner(t)
+	runner.SetRunning(appContext.RunningCommand{ID: 3, Command: "jj git push", Started: time.Now()})
+	history := New(test.NewTestContext(runner), source)
+
+	runner.SetRunning()
+	source.AddWithCommand("pushed", "jj git push", nil)
+	history.Update(intents.CommandHistoryCancelSelected{})
+
+	assert.Empty(t, runner.Canceled())
+	if assert.Len(t, history.items, 1) {
+		assert.Equal(t, "jj git push", history.items[0].Command)
+	}
+}
+
+// ignoreMsgs stands in for the UI when draining commands.
+type ignoreMsgs struct{}
+
+func (ignoreMsgs) Update(tea.Msg) tea.Cmd { return nil }
+
+func newPersistentContext(t *testing.T, runner *test.CommandRunner, location string) *appContext.MainContext {
+	ctx := test.NewTestContext(runner)
+	ctx.Histories = config.NewHistories()
+	ctx.Location = location
+	return ctx
+}
+
+func TestCommandHistory_ListsCommandsOfEarlierSessions(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("relies on XDG_CACHE_HOME to keep the history out of the user's cache")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	location := t.TempDir()
+
+	earlierCtx := newPersistentContext(t, test.NewTestCommandRunner(t), location)
+	earlier := flash.New(earlierCtx)
+	earlier.Update(common.CommandRunningMsg{ID: 1, Command: "jj new", Args: []string{"new"}, Interactive: true})
+	earlier.Update(common.CommandCompletedMsg{ID: 1, Output: "Working copy now at: abc"})
+	earlierCtx.Histories.Flush()
+
+	ctx := newPersistentContext(t, test.NewTestCommandRunner(t), location)
+	source := flash.New(ctx)
+	source.AddWithCommand("output", "jj status", nil)
+	history := New(ctx, source)
+
+	require.Len(t, history.rows, 2)
+	assert.Equal(t, "jj new", history.rows[0].entry.Command)
+	assert.Equal(t, []string{"new"}, history.rows[0].entry.Args)
+	assert.True(t, history.rows[0].entry.Interactive)
+	assert.Equal(t, "Working copy now at: abc", history.rows[0].entry.Text)
+	assert.Equal(t, "jj status", history.rows[1].entry.Command)
+
+	history.Update(intents.CommandHistoryNavigate{Delta: 1}) // select the earlier command
+	history.Update(intents.CommandHistoryDeleteSelected{})
+	assert.Len(t, history.rows, 2, "earlier sessions are kept")
+
+	other := flash.New(newPersistentContext(t, test.NewTestCommandRunner(t), t.TempDir()))
+	assert.Empty(t, other.EarlierCommandHistory(), "history is kept per repository")
+}
+
+func TestCommandHistory_DeletedCommandsAreNotPersisted(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("relies on XDG_CACHE_HOME to keep the history out of the user's cache")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	location := t.TempDir()
+
+	ctx := newPersistentContext(t, test.NewTestCommandRunner(t), location)
+	source := flash.New(ctx)
+	source.AddWithCommand("", "jj new", nil)
+	source.AddWithCommand("", "jj describe -m secret", nil)
+	history := New(ctx, source)
+	history.Update(intents.CommandHistoryDeleteSelected{})
+	ctx.Histories.Flush()
+
+	later := flash.New(newPersistentContext(t, test.NewTestCommandRunner(t), location))
+	earlier := later.EarlierCommandHistory()
+	require.Len(t, earlier, 1)
+	assert.Equal(t, "jj new", earlier[0].Command)
+}
+
+func TestCommandHistory_FilterByOutcome(t *testing.T) {
+	source := flash.New(test.NewTestContext(test.NewTestCommandRunner(t)))
+	source.AddWithCommand("ok", "jj new", nil)
+	source.AddWithCommand("", "jj abandon", errors.New("Error: Commit abc is immutable"))
+	history := New(test.NewTestContext(test.NewTestCommandRunner(t)), source)
+
+	history.Update(intents.CommandHistoryCycleFilter{})
+	require.Len(t, history.rows, 1)
+	assert.Equal(t, "jj abandon", history.rows[0].entry.Command)
+	assert.Contains(t, test.RenderImmediate(history, 60, 12), "showing failed")
+
+	history.Update(intents.CommandHistoryCycleFilter{})
+	require.Len(t, history.rows, 1)
+	assert.Equal(t, "jj new", history.rows[0].entry.Command)
+
+	history.Update(intents.CommandHistoryCycleFilter{})
+	assert.Len(t, history.rows, 2)
+}
+
+func TestCommandHistory_RerunSelected(t *testing.T) {
+	runner := test.NewTestCommandRunner(t)
+	runner.Expect(jj.Args("new", "main"))
+	defer runner.Verify()
+
+	source := flash.New(test.NewTestContext(runner))
+	source.Update(common.CommandRunningMsg{ID: 1, Command: "jj new main", Args: []string{"new", "main"}})
+	source.Update(common.CommandCompletedMsg{ID: 1})
+	history := New(test.NewTestContext(runner), source)
+
+	cmd := history.Update(intents.CommandHistoryRerunSelected{})
+	require.NotNil(t, cmd)
+	batch, ok := cmd().(tea.BatchMsg)
+	require.True(t, ok)
+	batch[0]()
+}
+
+func TestCommandHistory_EditSelectedOpensExecPrompt(t *testing.T) {
+	source := flash.New(test.NewTestContext(test.NewTestCommandRunner(t)))
+	source.Update(common.CommandRunningMsg{ID: 1, Command: "jj describe -m it's done -r main", Args: []string{"describe", "-m", "it's done", "-r", "main"}})
+	source.Update(common.CommandCompletedMsg{ID: 1})
+	history := New(test.NewTestContext(test.NewTestCommandRunner(t)), source)
+
+	var msgs []tea.Msg
+	test.SimulateModel(ignoreMsgs{}, history.Update(intents.CommandHistoryEditSelected{}), func(msg tea.Msg) {
+		msgs = append(msgs, msg)
+	})
+	assert.Contains(t, msgs, intents.ExecJJ{Line: `describe -m 'it'\''s done' -r main`})
+}
+
+func TestCommandHistory_EditSelectedRefusesCommandsReadingInput(t *testing.T) {
+	source := flash.New(test.NewTestContext(test.NewTestCommandRunner(t)))
+	source.AddWithCommand("", "jj describe --stdin", nil)
+	history := New(test.NewTestContext(test.NewTestCommandRunner(t)), source)
+
+	var msgs []tea.Msg
+	test.SimulateModel(ignoreMsgs{}, history.Update(intents.CommandHistoryEditSelected{}), func(msg tea.Msg) {
+		msgs = append(msgs, msg)
+	})
+	for _, msg := range msgs {
+		_, ok := msg.(intents.ExecJJ)
+		assert.False(t, ok)
+	}
+}
+
+func TestCommandHistory_ToggleOutputShowsFullOutput(t *testing.T) {
+	source := flash.New(test.NewTestContext(test.NewTestCommandRunner(t)))
+	var lines []string
+	for i := range 8 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	source.AddWithCommand(strings.Join(lines, "\n"), "jj git fetch", nil)
+	history := New(test.NewTestContext(test.NewTestCommandRunner(t)), source)
+
+	rendered := test.RenderImmediate(history, 80, 30)
+	assert.Contains(t, rendered, "line 4")
+	assert.NotContains(t, rendered, "line 7")
+	assert.Contains(t, rendered, "3 more lines")
+
+	history.Update(intents.CommandHistoryToggleOutput{})
+	rendered = test.RenderImmediate(history, 80, 30)
+	assert.Contains(t, rendered, "line 7")
+
+	history.Update(intents.CommandHistoryClose{})
+	assert.False(t, history.showOutput, "close leaves the output first")
+}
@@ -0,0 +1,43 @@
+package commandhistory
+
+import (
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/idursun/jjui/internal/ui/flash"
+	"github.com/idursun/jjui/internal/ui/layout"
+	"github.com/idursun/jjui/internal/ui/render"
+)
+
+// renderOutput shows the complete output of an entry in a panel filling the
+// box. Navigation scrolls the output while it is open.
+func (m *Model) renderOutput(dl *render.DisplayContext, box layout.Box, entry flash.CommandHistoryEntry) {
+	area := box.R
+	width := max(0, area.Dx()-4)
+	// the border and the header take three lines
+	height := max(1, area.Dy()-3)
+
+	header := m.renderCommandLine(entry.Command, entry.Err)
+	if !entry.Time.IsZero() {
+		header += m.dimmedStyle.Render("  " + entry.Time.Format("2006-01-02 15:04:05"))
+	}
+
+	output, style := entry.Text, m.textStyle
+	if entry.Err != nil {
+		output, style = entry.Err.Error(), m.errorStyle
+	}
+	lines := strings.Split(lipgloss.NewStyle().Width(width).Render(output), "\n")
+	m.outputOffset = min(m.outputOffset, max(0, len(lines)-height))
+	end := min(len(lines), m.outputOffset+height)
+	body := style.Render(strings.Join(lines[m.outputOffset:end], "\n"))
+
+	content := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(style.GetForeground()).
+		PaddingLeft(1).
+		PaddingRight(1).
+		Width(area.Dx()).
+		Height(area.Dy()).
+		Render(header + "\n" + body)
+	dl.AddDraw(area, content, render.ZOverlay)
+}
@@ -0,0 +1,17 @@
+package common
+
+import (
+	"charm.land/lipgloss/v2"
+	"github.com/idursun/jjui/internal/config"
+)
+
+// Badge is a short styled label drawn next to a revision in the log.
+type Badge struct {
+	Text  string
+	Style lipgloss.Style
+}
+
+// NewBadge styles the text with the color, as the colors of the theme are.
+func NewBadge(text string, color config.Color) Badge {
+	return Badge{Text: text, Style: createStyleFrom(color)}
+}
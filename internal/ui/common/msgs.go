@@ -1,6 +1,8 @@
 package common
 
 import (
+	"os/exec"
+
 	tea "charm.land/bubbletea/v2"
 	"github.com/idursun/jjui/internal/jj"
 )
@@ -26,11 +28,26 @@ type (
 	CommandRunningMsg struct {
 		ID      int
 		Command string
+		// Output streams the command's output lines while it runs and is
+		// closed when the command exits. It is nil for interactive commands.
+		Output <-chan string
+		// Args are the arguments the command was started with. They are nil
+		// when the command can't simply be run again, e.g. it reads stdin.
+		Args []string
+		// Interactive commands ran in a terminal and are run there again.
+		Interactive bool
+		// Background commands are shown while they run but leave no message
+		// or history entry behind, e.g. the log query loading the graph.
+		Background bool
 	}
 	CommandCompletedMsg struct {
 		ID     int
 		Output string
 		Err    error
+		// ExitCode is the exit status of the command, if it got to run.
+		ExitCode int
+		// Background is set for the completion of a background command.
+		Background bool
 	}
 	SelectionChangedMsg struct {
 		Item SelectedItem
@@ -51,10 +68,27 @@ type (
 		Title  string
 		Prompt string
 	}
+	// ShowPagerMsg shows Content, the output of a command, in a pager.
+	ShowPagerMsg struct {
+		Title   string
+		Content string
+	}
 	ExecProcessCompletedMsg struct {
 		Err error
 		Msg ExecMsg
 	}
+	// RunInTerminalMsg runs Cmd in the embedded terminal pane instead of
+	// suspending the UI. Started is called once Cmd runs, Done turns the exit
+	// of Cmd into a message.
+	RunInTerminalMsg struct {
+		Title   string
+		Cmd     *exec.Cmd
+		Started func()
+		Done    func(err error) tea.Msg
+		// Hold keeps the pane open after Cmd succeeds, so its output can be
+		// read. Failed commands always hold the pane.
+		Hold bool
+	}
 	FileSearchMsg struct {
 		Revset       string
 		PreviewShown bool
@@ -63,6 +97,9 @@ type (
 	}
 	ShowPreview     bool
 	RunLuaScriptMsg struct {
+		// Action is the name of the configured action the script belongs
+		// to, if any.
+		Action string
 		Script string
 	}
 	DispatchActionMsg struct {
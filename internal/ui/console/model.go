@@ -0,0 +1,270 @@
+package console
+
+import (
+	"errors"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/idursun/jjui/internal/scripting"
+	"github.com/idursun/jjui/internal/ui/actions"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/internal/ui/layout"
+	"github.com/idursun/jjui/internal/ui/render"
+)
+
+var (
+	_ common.StackedModel = (*Model)(nil)
+	_ common.Focusable    = (*Model)(nil)
+)
+
+const (
+	prompt             = "> "
+	continuationPrompt = ">> "
+)
+
+// WaitingMsg hands over the runner of input that waits for a message, which
+// has to be given messages until it is done.
+type WaitingMsg struct {
+	Runner *scripting.Runner
+}
+
+type lineKind int
+
+const (
+	lineInput lineKind = iota
+	lineOutput
+	lineError
+)
+
+type line struct {
+	kind lineKind
+	text string
+}
+
+// Model evaluates Lua against the VM of jjui, and keeps what was typed and
+// what it evaluated to for the rest of the session.
+type Model struct {
+	context *context.MainContext
+	input   textinput.Model
+	lines   []line
+	// pending is the start of a chunk that continues on the next line.
+	pending []string
+	history []string
+	// historyIndex is the entry of the history being shown, or
+	// len(history) for what is being typed.
+	historyIndex int
+	draft        string
+	// scroll is how many lines the transcript is scrolled up by.
+	scroll       int
+	pageHeight   int
+	running      bool
+	textStyle    lipgloss.Style
+	matchedStyle lipgloss.Style
+	dimmedStyle  lipgloss.Style
+	errorStyle   lipgloss.Style
+}
+
+func New(ctx *context.MainContext) *Model {
+	m := &Model{
+		context:      ctx,
+		textStyle:    common.DefaultPalette.Get("flash text"),
+		matchedStyle: common.DefaultPalette.Get("flash matched"),
+		dimmedStyle:  common.DefaultPalette.Get("flash dimmed"),
+		errorStyle:   common.DefaultPalette.Get("flash error"),
+	}
+	m.input = textinput.New()
+	m.input.Prompt = prompt
+	styles := m.input.Styles()
+	styles.Focused.Prompt = m.matchedStyle
+	styles.Blurred.Prompt = m.matchedStyle
+	m.input.SetStyles(styles)
+	return m
+}
+
+func (m *Model) IsFocused() bool {
+	return true
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.input.Focus()
+}
+
+func (m *Model) StackedActionOwner() string {
+	return actions.OwnerConsole
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case intents.Intent:
+		switch intent := msg.(type) {
+		case intents.ConsoleSubmit:
+			return m.submit()
+		case intents.ConsoleHistory:
+			m.navigateHistory(intent.Delta)
+			return nil
+		case intents.ConsoleScroll:
+			page := max(1, m.pageHeight-1)
+			m.scroll = max(0, m.scroll-intent.Delta*page)
+			return nil
+		case intents.ConsoleClear:
+			m.lines = nil
+			m.scroll = 0
+			return nil
+		case intents.ConsoleClose:
+			// leave the chunk being typed before leaving the console
+			if len(m.pending) > 0 {
+				m.pending = nil
+				m.input.Prompt = prompt
+				m.input.SetValue("")
+				return nil
+			}
+			return common.Close
+		}
+		return nil
+	case scripting.ConsoleResultMsg:
+		m.running = false
+		for _, text := range msg.Output {
+			m.lines = append(m.lines, line{kind: lineOutput, text: text})
+		}
+		if msg.Err != nil {
+			m.lines = append(m.lines, line{kind: lineError, text: msg.Err.Error()})
+		}
+		m.scroll = 0
+		return nil
+	case tea.KeyMsg, tea.PasteMsg:
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+// submit evaluates what has been typed, unless it is the start of a chunk
+// that continues on the next line.
+func (m *Model) submit() tea.Cmd {
+	value := m.input.Value()
+	if strings.TrimSpace(value) == "" && len(m.pending) == 0 {
+		return nil
+	}
+	if m.running {
+		err := errors.New("the previous input is still running")
+		return intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err})
+	}
+	if len(m.pending) > 0 {
+		m.lines = append(m.lines, line{kind: lineInput, text: continuationPrompt + value})
+	} else {
+		m.lines = append(m.lines, line{kind: lineInput, text: prompt + value})
+	}
+	m.input.SetValue("")
+	m.scroll = 0
+
+	source := strings.Join(append(m.pending, value), "\n")
+	runner, cmd, err := scripting.Evaluate(m.context, source)
+	if errors.Is(err, scripting.ErrIncompleteInput) {
+		m.pending = append(m.pending, value)
+		m.input.Prompt = continuationPrompt
+		return nil
+	}
+	m.pending = nil
+	m.input.Prompt = prompt
+	m.addHistory(source)
+	if err != nil {
+		m.lines = append(m.lines, line{kind: lineError, text: err.Error()})
+		return nil
+	}
+	m.running = true
+	if runner != nil {
+		return tea.Batch(cmd, func() tea.Msg { return WaitingMsg{Runner: runner} })
+	}
+	return cmd
+}
+
+func (m *Model) addHistory(source string) {
+	if n := len(m.history); n == 0 || m.history[n-1] != source {
+		m.history = append(m.history, source)
+	}
+	m.historyIndex = len(m.history)
+	m.draft = ""
+}
+
+// navigateHistory shows an earlier or later input in place of what is
+// being typed, which is kept to come back to.
+func (m *Model) navigateHistory(delta int) {
+	index := min(len(m.history), max(0, m.historyIndex+delta))
+	if index == m.historyIndex {
+		return
+	}
+	if m.historyIndex == len(m.history) {
+		m.draft = m.input.Value()
+	}
+	m.historyIndex = index
+	if index == len(m.history) {
+		m.input.SetValue(m.draft)
+	} else {
+		// chunks of several lines are recalled on one line, which Lua
+		// reads the same
+		m.input.SetValue(strings.ReplaceAll(m.history[index], "\n", " "))
+	}
+	m.input.CursorEnd()
+}
+
+func (m *Model) ViewRect(dl *render.DisplayContext, box layout.Box) {
+	rest, _ := box.CutBottom(1)
+	dl.AddDim(rest.R, render.ZOverlay)
+
+	area := rest.R
+	width := max(0, area.Dx()-4)
+	// the border, the header and the input take four lines
+	height := max(1, area.Dy()-4)
+	m.pageHeight = height
+
+	header := m.textStyle.Render("Lua console")
+	if m.running {
+		header += m.dimmedStyle.Render("  running…")
+	}
+
+	rendered := m.renderLines(width)
+	m.scroll = min(m.scroll, max(0, len(rendered)-height))
+	end := len(rendered) - m.scroll
+	start := max(0, end-height)
+	visible := rendered[start:end]
+	lines := make([]string, 0, height+1)
+	for range height - len(visible) {
+		lines = append(lines, "")
+	}
+	lines = append(lines, visible...)
+	m.input.SetWidth(max(1, width-len(m.input.Prompt)-1))
+	lines = append(lines, m.input.View())
+
+	content := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(m.textStyle.GetForeground()).
+		PaddingLeft(1).
+		PaddingRight(1).
+		Width(area.Dx()).
+		Height(area.Dy()).
+		Render(header + "\n" + strings.Join(lines, "\n"))
+	dl.AddDraw(area, content, render.ZOverlay)
+}
+
+// renderLines renders the transcript, wrapping the lines that are wider than
+// the console.
+func (m *Model) renderLines(width int) []string {
+	var rendered []string
+	wrap := lipgloss.NewStyle().Width(max(1, width))
+	for _, line := range m.lines {
+		style := m.textStyle
+		switch line.kind {
+		case lineInput:
+			style = m.dimmedStyle
+		case lineError:
+			style = m.errorStyle
+		}
+		rendered = append(rendered, strings.Split(wrap.Render(style.Render(line.text)), "\n")...)
+	}
+	return rendered
+}
@@ -0,0 +1,110 @@
+package console
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/scripting"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConsole(t *testing.T) *Model {
+	t.Helper()
+	ctx := &context.MainContext{}
+	require.NoError(t, scripting.InitVM(ctx))
+	t.Cleanup(func() {
+		scripting.CloseVM(ctx)
+	})
+	m := New(ctx)
+	m.Init()
+	return m
+}
+
+func submit(m *Model, input string) {
+	m.input.SetValue(input)
+	test.SimulateModel(m, m.Update(intents.ConsoleSubmit{}))
+}
+
+func TestConsole_ShowsInputAndResult(t *testing.T) {
+	m := newConsole(t)
+
+	submit(m, "x = 40")
+	submit(m, "x + 2")
+	submit(m, "error('boom')")
+
+	rendered := test.RenderImmediate(m, 80, 20)
+	assert.Contains(t, rendered, "Lua console")
+	assert.Contains(t, rendered, "> x + 2")
+	assert.Contains(t, rendered, "42")
+	assert.Contains(t, rendered, "console:1: boom")
+	assert.False(t, m.running)
+}
+
+func TestConsole_ContinuesIncompleteInput(t *testing.T) {
+	m := newConsole(t)
+
+	submit(m, "function f()")
+	assert.Equal(t, []string{"function f()"}, m.pending)
+	assert.Equal(t, continuationPrompt, m.input.Prompt)
+
+	submit(m, "return 'multi' end")
+	submit(m, "f()")
+	assert.Empty(t, m.pending)
+	assert.Equal(t, prompt, m.input.Prompt)
+	assert.Contains(t, test.RenderImmediate(m, 80, 20), `"multi"`)
+	assert.Equal(t, []string{"function f()\nreturn 'multi' end", "f()"}, m.history)
+}
+
+func TestConsole_NavigatesHistory(t *testing.T) {
+	m := newConsole(t)
+	submit(m, "1")
+	submit(m, "2")
+	m.input.SetValue("draft")
+
+	m.Update(intents.ConsoleHistory{Delta: -1})
+	assert.Equal(t, "2", m.input.Value())
+	m.Update(intents.ConsoleHistory{Delta: -1})
+	m.Update(intents.ConsoleHistory{Delta: -1})
+	assert.Equal(t, "1", m.input.Value())
+	m.Update(intents.ConsoleHistory{Delta: 1})
+	m.Update(intents.ConsoleHistory{Delta: 1})
+	assert.Equal(t, "draft", m.input.Value())
+}
+
+func TestConsole_ClearAndClose(t *testing.T) {
+	m := newConsole(t)
+	submit(m, "'shown'")
+	m.Update(intents.ConsoleClear{})
+	assert.NotContains(t, test.RenderImmediate(m, 80, 20), "shown")
+
+	// closing leaves an incomplete input first
+	submit(m, "if true then")
+	assert.Nil(t, m.Update(intents.ConsoleClose{}))
+	assert.Empty(t, m.pending)
+
+	cmd := m.Update(intents.ConsoleClose{})
+	if assert.NotNil(t, cmd) {
+		assert.IsType(t, common.CloseViewMsg{}, cmd())
+	}
+}
+
+func TestConsole_HandsOverWaitingInput(t *testing.T) {
+	m := newConsole(t)
+	m.input.SetValue("wait_refresh()")
+
+	var waiting *WaitingMsg
+	test.SimulateModel(m, m.Update(intents.ConsoleSubmit{}), func(msg tea.Msg) {
+		if msg, ok := msg.(WaitingMsg); ok {
+			waiting = &msg
+		}
+	})
+	require.NotNil(t, waiting)
+	assert.False(t, waiting.Runner.Done())
+	assert.True(t, m.running)
+	assert.Contains(t, test.RenderImmediate(m, 80, 20), "running…")
+}
@@ -0,0 +1,31 @@
+package context
+
+import (
+	"strings"
+
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+)
+
+// commandClass picks the timeout class of a jj invocation: `git` and `log`
+// subcommands have classes of their own, everything else gets the class the
+// caller runs it as.
+func commandClass(args []string, class string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if strings.HasPrefix(arg, "-") {
+			if jj.GlobalFlagsWithValue[arg] {
+				i++
+			}
+			continue
+		}
+		switch arg {
+		case "git":
+			return config.CommandClassGit
+		case "log":
+			return config.CommandClassLog
+		}
+		break
+	}
+	return class
+}
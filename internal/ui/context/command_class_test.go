@@ -0,0 +1,28 @@
+package context
+
+import (
+	"testing"
+
+	"github.com/idursun/jjui/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_commandClass(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		class string
+		want  string
+	}{
+		{name: "caller's class", args: []string{"debug", "snapshot"}, class: config.CommandClassMutation, want: config.CommandClassMutation},
+		{name: "preview", args: []string{"diff", "-r", "@"}, class: config.CommandClassPreview, want: config.CommandClassPreview},
+		{name: "log", args: []string{"log", "-r", "::"}, class: config.CommandClassMutation, want: config.CommandClassLog},
+		{name: "git after global flags", args: []string{"--color", "always", "-R", "repo", "git", "fetch"}, class: config.CommandClassPreview, want: config.CommandClassGit},
+		{name: "git after a config file", args: []string{"--config-file", "extra.toml", "git", "fetch"}, class: config.CommandClassMutation, want: config.CommandClassGit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, commandClass(tt.args, tt.class))
+		})
+	}
+}
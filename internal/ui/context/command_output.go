@@ -0,0 +1,174 @@
+package context
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// outputBufferSize is how many lines may queue up before new lines are dropped.
+// Dropping keeps a slow UI from ever blocking the running process.
+const outputBufferSize = 64
+
+// cancelWaitDelay bounds how long an exited command waits for its output pipes to close.
+const cancelWaitDelay = time.Second
+
+var (
+	errCommandCanceled = errors.New("command canceled")
+	errCommandTimedOut = errors.New("command timed out")
+	// ErrOutputTooLarge is returned for a command that wrote more output than
+	// its caller takes.
+	ErrOutputTooLarge = errors.New("output is more than the limit")
+	// ErrQueueBusy is returned for a command that can't wait for its turn
+	// while other commands that write to the repository run.
+	ErrQueueBusy = errors.New("the repository is busy with other commands")
+)
+
+// limitedWriter keeps what is written to it until there is more than limit
+// bytes of it, then calls exceeded and refuses the rest, so that a command's
+// output never grows past the limit in memory. A limit of 0 keeps everything.
+type limitedWriter struct {
+	bytes.Buffer
+	limit    int
+	exceeded func(error)
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.limit > 0 && w.Len()+len(p) > w.limit {
+		err := fmt.Errorf("%w of %d bytes", ErrOutputTooLarge, w.limit)
+		w.exceeded(err)
+		return 0, err
+	}
+	return w.Buffer.Write(p)
+}
+
+// lineWriter splits everything written to it into lines and forwards them to
+// a channel. Carriage returns end a line too, so progress updates show up as
+// they are printed.
+type lineWriter struct {
+	mu      sync.Mutex
+	lines   chan string
+	partial []byte
+	closed  bool
+}
+
+func newLineWriter() *lineWriter {
+	return &lineWriter{lines: make(chan string, outputBufferSize)}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, b := range p {
+		if b == '\n' || b == '\r' {
+			w.flush()
+			continue
+		}
+		w.partial = append(w.partial, b)
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	line := strings.TrimSpace(string(w.partial))
+	w.partial = w.partial[:0]
+	if line == "" || w.closed {
+		return
+	}
+	select {
+	case w.lines <- line:
+	default:
+	}
+}
+
+func (w *lineWriter) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.flush()
+	w.closed = true
+	close(w.lines)
+}
+
+// RunningCommand describes a command that has been started by the runner and
+// has not exited yet.
+type RunningCommand struct {
+	ID      int
+	Command string
+	Started time.Time
+}
+
+type runningCommand struct {
+	RunningCommand
+	cmd      *exec.Cmd
+	canceled bool
+}
+
+// runningCommands tracks the processes started by the runner so they can be
+// listed and canceled from the UI.
+type runningCommands struct {
+	mu       sync.Mutex
+	commands map[int]*runningCommand
+}
+
+func (r *runningCommands) add(id int, c *exec.Cmd) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.commands == nil {
+		r.commands = make(map[int]*runningCommand)
+	}
+	r.commands[id] = &runningCommand{
+		RunningCommand: RunningCommand{ID: id, Command: displayCommand(c.Args), Started: time.Now()},
+		cmd:            c,
+	}
+}
+
+// remove forgets the command and reports whether it was canceled.
+func (r *runningCommands) remove(id int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	command, ok := r.commands[id]
+	if !ok {
+		return false
+	}
+	delete(r.commands, id)
+	return command.canceled
+}
+
+func (r *runningCommands) cancel(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	command, ok := r.commands[id]
+	if !ok {
+		return nil
+	}
+	command.canceled = true
+	return killProcessGroup(command.cmd)
+}
+
+func (r *runningCommands) snapshot() []RunningCommand {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]RunningCommand, 0, len(r.commands))
+	for _, command := range r.commands {
+		out = append(out, command.RunningCommand)
+	}
+	slices.SortFunc(out, func(a, b RunningCommand) int { return a.ID - b.ID })
+	return out
+}
+
+// displayCommand renders the arguments of a jj process the way the flash
+// messages do, without the colour flags added by the runner.
+func displayCommand(args []string) string {
+	if len(args) >= 3 && args[1] == "--color" && args[2] == "always" {
+		args = append([]string{args[0]}, args[3:]...)
+	}
+	return strings.Join(args, " ")
+}
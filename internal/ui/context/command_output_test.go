@@ -0,0 +1,30 @@
+package context
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_limitedWriter_RefusesOutputOverTheLimit(t *testing.T) {
+	var exceeded error
+	w := &limitedWriter{limit: 4, exceeded: func(err error) { exceeded = err }}
+
+	n, err := w.Write([]byte("abc"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Nil(t, exceeded)
+
+	_, err = w.Write([]byte("de"))
+	assert.ErrorIs(t, err, ErrOutputTooLarge)
+	assert.ErrorIs(t, exceeded, ErrOutputTooLarge)
+	assert.Equal(t, "abc", w.String())
+}
+
+func Test_limitedWriter_KeepsEverythingWithoutALimit(t *testing.T) {
+	w := &limitedWriter{exceeded: func(error) { panic(errors.New("no limit")) }}
+	_, err := w.Write(make([]byte, 1<<20))
+	assert.NoError(t, err)
+	assert.Equal(t, 1<<20, w.Len())
+}
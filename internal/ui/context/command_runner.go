@@ -13,85 +13,351 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/idursun/jjui/internal/askpass"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/common"
 )
 
 type CommandRunner interface {
 	RunCommandImmediate(args []string) ([]byte, error)
 	RunCommandImmediateWithEnv(args []string, env []string) ([]byte, error)
+	RunCommandImmediateWithClass(args []string, class string) ([]byte, error)
+	RunCommandImmediateWithLimit(args []string, class string, limit int) ([]byte, error)
+	RunCommandImmediateIfIdle(args []string, class string, limit int) ([]byte, error)
+	RunCommandImmediateCombined(args []string) ([]byte, error)
+	RunCommandBackground(args []string) ([]byte, error)
 	RunCommandStreaming(ctx context.Context, args []string) (*StreamingCommand, error)
 	RunCommand(args []string, continuations ...tea.Cmd) tea.Cmd
 	RunCommandWithInput(args []string, input string, continuations ...tea.Cmd) tea.Cmd
 	RunInteractiveCommand(args []string, continuation tea.Cmd) tea.Cmd
+	CancelCommand(id int) error
+	RunningCommands() []RunningCommand
+	QueuedCommands() int
+	QueueBusy() bool
+	WaitForQueue()
 }
 
 type MainCommandRunner struct {
 	Location  string
 	Askpass   *askpass.Server
 	idCounter atomic.Int64
+	running   runningCommands
+	scheduler commandScheduler
 }
 
 func (a *MainCommandRunner) nextID() int { return int(a.idCounter.Add(1)) }
 
 func (a *MainCommandRunner) RunCommandImmediateWithEnv(args []string, env []string) ([]byte, error) {
-	c := exec.Command("jj", args...)
-	c.Dir = a.Location
+	return a.runCommandImmediate(args, env, config.CommandClassPreview, 0, true)
+}
+
+// RunCommandImmediateWithClass runs the command with the timeout of the given
+// class instead of the preview one, for callers like scripts that may run
+// commands writing to the repository.
+func (a *MainCommandRunner) RunCommandImmediateWithClass(args []string, class string) ([]byte, error) {
+	return a.runCommandImmediate(args, nil, class, 0, true)
+}
+
+// RunCommandImmediateWithLimit is RunCommandImmediateWithClass for callers
+// that take at most limit bytes of output. The command is killed as soon as
+// it writes more, and ErrOutputTooLarge is returned.
+func (a *MainCommandRunner) RunCommandImmediateWithLimit(args []string, class string, limit int) ([]byte, error) {
+	return a.runCommandImmediate(args, nil, class, limit, true)
+}
+
+// RunCommandImmediateIfIdle is RunCommandImmediateWithLimit for callers on
+// the UI loop. Instead of waiting for its turn in the queue, a command that
+// may write to the repository fails with ErrQueueBusy while other such
+// commands run or wait.
+func (a *MainCommandRunner) RunCommandImmediateIfIdle(args []string, class string, limit int) ([]byte, error) {
+	return a.runCommandImmediate(args, nil, class, limit, false)
+}
+
+// runCommandImmediate runs the command and returns its output. Commands that
+// may write to the repository wait for their turn in the queue, unless wait is
+// false, so immediate commands must only be run from the UI loop when they
+// are read-only or don't wait.
+func (a *MainCommandRunner) runCommandImmediate(args []string, env []string, class string, limit int, wait bool) ([]byte, error) {
+	c := a.newCommand(context.Background(), args, class)
+	defer c.stop()
 	if len(env) > 0 {
 		c.Env = append(os.Environ(), env...)
 	}
-	if output, err := c.Output(); err != nil {
+	output := &limitedWriter{limit: limit, exceeded: c.cancel}
+	var errOutput bytes.Buffer
+	c.Stdout = output
+	c.Stderr = &errOutput
+	var err error
+	if wait {
+		err = a.runQueued(a.nextID(), c, nil)
+	} else {
+		err = a.runIfIdle(a.nextID(), c)
+	}
+	if err != nil {
+		if cause := context.Cause(c.ctx); errors.Is(cause, ErrOutputTooLarge) {
+			return nil, cause
+		}
 		var exitError *exec.ExitError
 		if errors.As(err, &exitError) {
-			return nil, errors.New(string(exitError.Stderr))
+			return nil, errors.New(errOutput.String())
 		}
 		return nil, err
-	} else {
-		return bytes.Trim(output, "\n"), nil
 	}
+	return bytes.Trim(output.Bytes(), "\n"), nil
 }
 
 func (a *MainCommandRunner) RunCommandImmediate(args []string) ([]byte, error) {
 	return a.RunCommandImmediateWithEnv(args, nil)
 }
 
+// RunCommandImmediateCombined returns stdout and stderr together, the way the
+// exec prompt's pager shows a read-only command that also reports on stderr.
+func (a *MainCommandRunner) RunCommandImmediateCombined(args []string) ([]byte, error) {
+	c := a.newCommand(context.Background(), args, config.CommandClassPreview)
+	defer c.stop()
+	var output bytes.Buffer
+	c.Stdout = &output
+	c.Stderr = &output
+	if err := a.runQueued(a.nextID(), c, nil); err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			return nil, errors.New(output.String())
+		}
+		return nil, err
+	}
+	return bytes.Trim(output.Bytes(), "\n"), nil
+}
+
+// RunCommandBackground runs a command without reporting it as a user command.
+// Password prompts are still routed through askpass.
+func (a *MainCommandRunner) RunCommandBackground(args []string) ([]byte, error) {
+	started, cancelAskpass, env := a.Askpass.NewSubprocess(strings.Join(args, " "))
+	defer cancelAskpass()
+	c := a.newCommand(context.Background(), args, config.CommandClassMutation)
+	defer c.stop()
+	c.Env = append(os.Environ(), env...)
+	var output bytes.Buffer
+	c.Stdout = &output
+	c.Stderr = &output
+	if err := a.runQueued(a.nextID(), c, started); err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			return nil, errors.New(output.String())
+		}
+		return nil, err
+	}
+	return bytes.Trim(output.Bytes(), "\n"), nil
+}
+
 func (a *MainCommandRunner) RunCommandStreaming(ctx context.Context, args []string) (*StreamingCommand, error) {
-	c := exec.CommandContext(ctx, "jj", args...)
-	c.Dir = a.Location
+	c := a.newCommand(ctx, args, config.CommandClassPreview)
 	pipe, err := c.StdoutPipe()
 	if err != nil {
+		c.stop()
 		return nil, err
 	}
 	errPipe, err := c.StderrPipe()
 	if err != nil {
+		c.stop()
 		return nil, err
 	}
-	if err = c.Start(); err != nil {
+	// the log is read lazily, so instead of holding up the queue while it is
+	// open it only holds the turn until jj has snapshotted the working copy,
+	// which it has once it starts printing the log
+	release := a.scheduler.acquire(args, c.class)
+	if err = c.start(); err != nil {
+		release()
+		c.stop()
 		return nil, err
 	}
+	id := a.nextID()
+	a.running.add(id, c.Cmd)
 	return &StreamingCommand{
-		ReadCloser: pipe,
+		ReadCloser: &releasingReader{ReadCloser: pipe, release: release},
 		ErrPipe:    errPipe,
-		cmd:        c,
-		ctx:        ctx,
+		ID:         id,
+		command:    "jj " + strings.Join(args, " "),
+		cmd:        c.Cmd,
+		ctx:        c.ctx,
+		cancel: func() {
+			release()
+			c.stop()
+		},
+		running: &a.running,
 	}, nil
 }
 
+// releasingReader hands the turn of a streaming command to the next command
+// once the command printed something or closed its output.
+type releasingReader struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releasingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 || err != nil {
+		r.release()
+	}
+	return n, err
+}
+
+func (r *releasingReader) Close() error {
+	r.release()
+	return r.ReadCloser.Close()
+}
+
+// CancelCommand kills the process group of a running command started by this runner.
+func (a *MainCommandRunner) CancelCommand(id int) error {
+	return a.running.cancel(id)
+}
+
+// RunningCommands lists the commands that are still running, oldest first.
+func (a *MainCommandRunner) RunningCommands() []RunningCommand {
+	return a.running.snapshot()
+}
+
+// QueuedCommands returns the number of commands waiting for an earlier
+// command that may write to the repository to finish.
+func (a *MainCommandRunner) QueuedCommands() int {
+	return a.scheduler.queued()
+}
+
+// QueueBusy reports whether a command that may write to the repository is
+// running or waiting to run.
+func (a *MainCommandRunner) QueueBusy() bool {
+	return a.scheduler.busy()
+}
+
+// WaitForQueue blocks until the commands queued so far have finished.
+func (a *MainCommandRunner) WaitForQueue() {
+	a.scheduler.waitIdle()
+}
+
+// jjCommand is a jj process, in a process group of its own unless it may need
+// the terminal for an editor. It is killed once it has run for longer than the timeout
+// configured for its class.
+type jjCommand struct {
+	*exec.Cmd
+	class   string
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	timeout time.Duration
+	timer   *time.Timer
+}
+
+func (a *MainCommandRunner) newCommand(parent context.Context, args []string, class string) *jjCommand {
+	class = commandClass(args, class)
+	ctx, cancel := context.WithCancelCause(parent)
+	c := exec.CommandContext(ctx, "jj", args...)
+	c.Dir = a.Location
+	switch {
+	case class == config.CommandClassGit:
+		// ssh and git would be stopped asking for credentials on the terminal
+		// from a background group, without a terminal they ask through askpass
+		// or fail instead
+		setSession(c)
+	case !jj.NeedsTerminal(args):
+		setProcessGroup(c)
+	}
+	c.Cancel = func() error { return killProcessGroup(c) }
+	// don't let children that inherited the pipes keep a killed command alive
+	c.WaitDelay = cancelWaitDelay
+	return &jjCommand{
+		Cmd:     c,
+		class:   class,
+		ctx:     ctx,
+		cancel:  cancel,
+		timeout: config.GetCommandTimeout(config.Current, class),
+	}
+}
+
+// start starts the process; the timeout only counts from here so that time
+// spent waiting in the queue doesn't count against it.
+func (c *jjCommand) start() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+	if c.timeout > 0 {
+		c.timer = time.AfterFunc(c.timeout, func() { c.cancel(errCommandTimedOut) })
+	}
+	return nil
+}
+
+func (c *jjCommand) stop() {
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.cancel(nil)
+}
+
+func (c *jjCommand) timedOut() bool {
+	return errors.Is(context.Cause(c.ctx), errCommandTimedOut)
+}
+
+// runQueued waits for the command's turn in the queue, then runs it. It must
+// never be called from the UI loop.
+func (a *MainCommandRunner) runQueued(id int, c *jjCommand, started func(pid int)) error {
+	release := a.scheduler.acquire(c.Args[1:], c.class)
+	defer release()
+	return a.run(id, c, started)
+}
+
+// runIfIdle runs the command from the UI loop, which can't wait for its turn
+// in the queue: a command that needs one while the queue is busy fails with
+// ErrQueueBusy instead.
+func (a *MainCommandRunner) runIfIdle(id int, c *jjCommand) error {
+	release, ok := a.scheduler.tryAcquire(c.Args[1:], c.class)
+	if !ok {
+		return ErrQueueBusy
+	}
+	defer release()
+	return a.run(id, c, nil)
+}
+
+// run starts the command, keeps it listed as running until it exits and
+// reports cancellations and timeouts as errors of their own.
+func (a *MainCommandRunner) run(id int, c *jjCommand, started func(pid int)) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	if started != nil {
+		started(c.Process.Pid)
+	}
+	a.running.add(id, c.Cmd)
+	err := c.Wait()
+	if a.running.remove(id) {
+		return errCommandCanceled
+	}
+	if c.timedOut() {
+		return errCommandTimedOut
+	}
+	return err
+}
+
 func (a *MainCommandRunner) runCommandWithInput(args []string, input *string, continuations []tea.Cmd) tea.Cmd {
 	id := a.nextID()
 	command := "jj " + strings.Join(args, " ")
+	var rerunArgs []string
+	if input == nil {
+		rerunArgs = slices.Clone(args)
+	}
+	lines := newLineWriter()
 	commands := make([]tea.Cmd, 0)
 	commands = append(commands,
 		func() tea.Msg {
+			defer lines.Close()
 			started, cancel, env := a.Askpass.NewSubprocess(strings.Join(args, " "))
 			defer cancel()
 			if !slices.Contains(args, "--color") {
 				args = append([]string{"--color", "always"}, args...)
 			}
-			c := exec.Command("jj", args...)
-			c.Dir = a.Location
+			c := a.newCommand(context.Background(), args, config.CommandClassMutation)
+			defer c.stop()
 			c.Env = append(os.Environ(), env...)
 
 			if input != nil {
@@ -109,36 +375,30 @@ func (a *MainCommandRunner) runCommandWithInput(args []string, input *string, co
 			}
 
 			var output bytes.Buffer
-			c.Stderr = &output
-			if err := c.Start(); err != nil {
-				return common.CommandCompletedMsg{
-					ID:  id,
-					Err: err,
-				}
-			}
-			started(c.Process.Pid)
-
-			err := c.Wait()
-			if err != nil {
-				var exitError *exec.ExitError
-				if errors.As(err, &exitError) {
-					msg := output.String()
-					if len(env) == 0 && slices.Contains([]string{"linux", "darwin"}, runtime.GOOS) {
-						msg += "\nHint: enable ssh.hijack_askpass if you expected a password prompt (e.g. ssh passphrase)"
-					}
-					err = errors.New(msg)
+			c.Stderr = io.MultiWriter(&output, lines)
+			c.Stdout = lines
+			err := a.runQueued(id, c, started)
+			exitCode := 0
+			var exitError *exec.ExitError
+			if errors.As(err, &exitError) {
+				exitCode = exitError.ExitCode()
+				msg := output.String()
+				if len(env) == 0 && slices.Contains([]string{"linux", "darwin"}, runtime.GOOS) {
+					msg += "\nHint: enable ssh.hijack_askpass if you expected a password prompt (e.g. ssh passphrase)"
 				}
+				err = errors.New(msg)
 			}
 			return common.CommandCompletedMsg{
-				ID:     id,
-				Output: output.String(),
-				Err:    err,
+				ID:       id,
+				Output:   output.String(),
+				Err:      err,
+				ExitCode: exitCode,
 			}
 		})
 	commands = append(commands, continuations...)
 	return tea.Batch(
 		func() tea.Msg {
-			return common.CommandRunningMsg{ID: id, Command: command}
+			return common.CommandRunningMsg{ID: id, Command: command, Output: lines.lines, Args: rerunArgs}
 		},
 		tea.Sequence(commands...),
 	)
@@ -156,30 +416,58 @@ func (a *MainCommandRunner) RunInteractiveCommand(args []string, continuation te
 	id := a.nextID()
 	command := "jj " + strings.Join(args, " ")
 	c := exec.Command("jj", args...)
-	errBuffer := &bytes.Buffer{}
-	c.Stderr = errBuffer
 	c.Dir = a.Location
+	embedded := config.Current.UI.Terminal.Embedded
+	errBuffer := &bytes.Buffer{}
+	if !embedded {
+		// the embedded terminal shows stderr and reports it on failure
+		c.Stderr = errBuffer
+	}
 	return tea.Batch(
 		func() tea.Msg {
-			return common.CommandRunningMsg{ID: id, Command: command}
+			return common.CommandRunningMsg{ID: id, Command: command, Args: slices.Clone(args), Interactive: true}
 		},
-		tea.ExecProcess(c, func(err error) tea.Msg {
-			if err != nil {
-				return common.CommandCompletedMsg{ID: id, Err: errors.New(errBuffer.String())}
+		func() tea.Msg {
+			// the suspended UI holds the queue until the user is done with the
+			// command, the pane lets it go once the command runs since the UI
+			// keeps queueing commands while the pane is open
+			release := a.scheduler.acquire(args, commandClass(args, config.CommandClassMutation))
+			done := func(err error) tea.Msg {
+				release()
+				if err != nil {
+					exitCode := 0
+					var exitError *exec.ExitError
+					if errors.As(err, &exitError) {
+						exitCode = exitError.ExitCode()
+					}
+					if !embedded {
+						err = errors.New(errBuffer.String())
+					}
+					return common.CommandCompletedMsg{ID: id, Err: err, ExitCode: exitCode}
+				}
+				return tea.Batch(continuation, func() tea.Msg {
+					return common.CommandCompletedMsg{ID: id, Err: nil}
+				})()
+			}
+			if embedded {
+				return common.RunInTerminalMsg{Title: command, Cmd: c, Started: release, Done: done}
 			}
-			return tea.Batch(continuation, func() tea.Msg {
-				return common.CommandCompletedMsg{ID: id, Err: nil}
-			})()
-		}),
+			return tea.ExecProcess(c, done)()
+		},
 	)
 }
 
 type StreamingCommand struct {
 	io.ReadCloser
 	ErrPipe io.ReadCloser
+	// ID identifies the command for [MainCommandRunner.CancelCommand].
+	ID      int
+	command string
 	cmd     *exec.Cmd
 	ctx     context.Context
+	cancel  context.CancelFunc
 	once    sync.Once
+	running *runningCommands
 }
 
 func (c *StreamingCommand) Close() error {
@@ -198,6 +486,7 @@ func (c *StreamingCommand) Close() error {
 
 		log.Println("waiting for command to finish")
 		err = c.cmd.Wait()
+		c.forget()
 		if err != nil && (c.ctx.Err() != nil || errors.Is(err, os.ErrClosed)) {
 			err = nil
 		}
@@ -210,5 +499,27 @@ func (c *StreamingCommand) Close() error {
 }
 
 func (c *StreamingCommand) Wait() error {
-	return c.cmd.Wait()
+	err := c.cmd.Wait()
+	if c.forget() {
+		return errCommandCanceled
+	}
+	return err
+}
+
+// Running reports the command to the live output panel, where it shows with
+// its elapsed time and can be canceled like the other commands. It is a
+// background command, so it leaves no message behind once it is done.
+func (c *StreamingCommand) Running() tea.Msg {
+	return common.CommandRunningMsg{ID: c.ID, Command: c.command, Background: true}
+}
+
+// forget stops tracking the command and reports whether it was canceled.
+func (c *StreamingCommand) forget() bool {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	if c.running != nil {
+		return c.running.remove(c.ID)
+	}
+	return false
 }
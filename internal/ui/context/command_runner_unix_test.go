@@ -0,0 +1,92 @@
+//go:build unix
+
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/idursun/jjui/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJJ puts a jj running script first on the PATH.
+func fakeJJ(t *testing.T, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "jj"), []byte("#!/bin/sh\n"+script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestMainCommandRunner_MutationsNeverOverlap(t *testing.T) {
+	// the lock can only be taken by one jj at a time
+	t.Setenv("JJUI_TEST_LOCK", filepath.Join(t.TempDir(), "lock"))
+	fakeJJ(t, `
+mkdir "$JJUI_TEST_LOCK" 2>/dev/null || { echo "ran alongside another command" >&2; exit 1; }
+sleep 0.05
+rmdir "$JJUI_TEST_LOCK"
+`)
+	runner := &MainCommandRunner{Location: t.TempDir()}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := range cap(errs) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if i%2 == 0 {
+				_, err = runner.RunCommandImmediateWithClass([]string{"new"}, config.CommandClassMutation)
+			} else {
+				// writes to the repository without snapshotting
+				_, err = runner.RunCommandImmediateWithLimit([]string{"bookmark", "set", "main", "--ignore-working-copy"}, config.CommandClassMutation, 1024)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
+
+func TestMainCommandRunner_IfIdleDoesntWaitForTheQueue(t *testing.T) {
+	fakeJJ(t, "echo ran\n")
+	runner := &MainCommandRunner{Location: t.TempDir()}
+	release := runner.scheduler.acquire([]string{"new"}, config.CommandClassMutation)
+
+	_, err := runner.RunCommandImmediateIfIdle([]string{"new"}, config.CommandClassMutation, 0)
+	assert.ErrorIs(t, err, ErrQueueBusy)
+	out, err := runner.RunCommandImmediateIfIdle([]string{"log", "--ignore-working-copy"}, config.CommandClassPreview, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "ran", string(out))
+
+	release()
+	out, err = runner.RunCommandImmediateIfIdle([]string{"new"}, config.CommandClassMutation, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "ran", string(out))
+}
+
+func TestMainCommandRunner_GitTimeoutKillsChildren(t *testing.T) {
+	previous := config.Current.Timeouts.Git
+	config.Current.Timeouts.Git = 1
+	t.Cleanup(func() { config.Current.Timeouts.Git = previous })
+	survived := filepath.Join(t.TempDir(), "survived")
+	t.Setenv("JJUI_TEST_SURVIVED", survived)
+	// a hung remote helper, like ssh waiting for the network
+	fakeJJ(t, `
+(sleep 2; touch "$JJUI_TEST_SURVIVED") &
+wait
+`)
+	runner := &MainCommandRunner{Location: t.TempDir()}
+
+	_, err := runner.RunCommandImmediateWithClass([]string{"git", "fetch"}, config.CommandClassMutation)
+	assert.ErrorIs(t, err, errCommandTimedOut)
+	time.Sleep(1500 * time.Millisecond)
+	assert.NoFileExists(t, survived, "the child outlived the command")
+}
@@ -0,0 +1,102 @@
+package context
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/idursun/jjui/internal/config"
+)
+
+// commandScheduler runs the commands that may write to the repository one at
+// a time, in the order they asked for their turn. jj snapshots the working
+// copy (and so creates an operation) unless --ignore-working-copy is passed,
+// so only those invocations of read-only classes run in parallel.
+type commandScheduler struct {
+	mu      sync.Mutex
+	running bool
+	// waiting holds a channel per queued command, closed when it's its turn
+	waiting []chan struct{}
+}
+
+// isReadOnly reports whether the command of the given class can't write to
+// the repository. Mutation and git commands may write even when they don't
+// snapshot the working copy.
+func isReadOnly(args []string, class string) bool {
+	if class == config.CommandClassMutation || class == config.CommandClassGit {
+		return false
+	}
+	return slices.Contains(args, "--ignore-working-copy")
+}
+
+// acquire blocks until it is the command's turn and returns the function
+// that hands the turn to the next command. Read-only commands don't wait.
+// It must never be called from the UI loop.
+func (s *commandScheduler) acquire(args []string, class string) (release func()) {
+	if isReadOnly(args, class) {
+		return func() {}
+	}
+	s.mu.Lock()
+	if s.running {
+		turn := make(chan struct{})
+		s.waiting = append(s.waiting, turn)
+		s.mu.Unlock()
+		<-turn
+	} else {
+		s.running = true
+		s.mu.Unlock()
+	}
+	return s.releaser()
+}
+
+// tryAcquire takes the turn if no command holds it or waits for it, for
+// commands run from the UI loop, which can't wait.
+func (s *commandScheduler) tryAcquire(args []string, class string) (release func(), ok bool) {
+	if isReadOnly(args, class) {
+		return func() {}, true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running || len(s.waiting) > 0 {
+		return nil, false
+	}
+	s.running = true
+	return s.releaser(), true
+}
+
+func (s *commandScheduler) releaser() func() {
+	var once sync.Once
+	return func() { once.Do(s.next) }
+}
+
+// next hands the turn to the command that has waited longest.
+func (s *commandScheduler) next() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.waiting) == 0 {
+		s.running = false
+		return
+	}
+	turn := s.waiting[0]
+	s.waiting = s.waiting[1:]
+	close(turn)
+}
+
+// waitIdle blocks until the commands queued so far have finished, without
+// holding up the commands queued after it.
+func (s *commandScheduler) waitIdle() {
+	s.acquire(nil, config.CommandClassMutation)()
+}
+
+// queued returns the number of commands waiting for their turn.
+func (s *commandScheduler) queued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.waiting)
+}
+
+// busy reports whether a command holds the turn or is waiting for one.
+func (s *commandScheduler) busy() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running || len(s.waiting) > 0
+}
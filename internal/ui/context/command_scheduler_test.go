@@ -0,0 +1,134 @@
+package context
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/idursun/jjui/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// waitFor polls until cond holds, failing the test after a second.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	assert.Eventually(t, cond, time.Second, time.Millisecond)
+}
+
+func Test_commandScheduler_RunsInOrderOfArrival(t *testing.T) {
+	var s commandScheduler
+	release := s.acquire([]string{"new"}, config.CommandClassMutation)
+	assert.True(t, s.busy())
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done := s.acquire([]string{"describe"}, config.CommandClassMutation)
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			done()
+		}()
+		// queue the commands one after the other
+		waitFor(t, func() bool { return s.queued() == i+1 })
+	}
+
+	release()
+	wg.Wait()
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+	assert.False(t, s.busy())
+	assert.Zero(t, s.queued())
+}
+
+func Test_commandScheduler_ReadOnlyCommandsDontWait(t *testing.T) {
+	var s commandScheduler
+	release := s.acquire([]string{"new"}, config.CommandClassMutation)
+	defer release()
+
+	done := make(chan struct{})
+	go func() {
+		s.acquire([]string{"log", "--ignore-working-copy"}, config.CommandClassPreview)()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("read-only command waited for the queue")
+	}
+	assert.Zero(t, s.queued())
+}
+
+func Test_commandScheduler_MutationsWaitWithoutSnapshotting(t *testing.T) {
+	var s commandScheduler
+	release := s.acquire([]string{"new"}, config.CommandClassMutation)
+
+	acquired := make(chan struct{})
+	go func() {
+		s.acquire([]string{"bookmark", "set", "main", "--ignore-working-copy"}, config.CommandClassMutation)()
+		close(acquired)
+	}()
+	waitFor(t, func() bool { return s.queued() == 1 })
+	release()
+	<-acquired
+	assert.False(t, s.busy())
+}
+
+func Test_commandScheduler_TryAcquireDoesntWait(t *testing.T) {
+	var s commandScheduler
+	release, ok := s.tryAcquire([]string{"new"}, config.CommandClassMutation)
+	assert.True(t, ok)
+
+	_, ok = s.tryAcquire([]string{"describe"}, config.CommandClassMutation)
+	assert.False(t, ok, "the turn is taken")
+	readOnly, ok := s.tryAcquire([]string{"log", "--ignore-working-copy"}, config.CommandClassPreview)
+	assert.True(t, ok)
+	readOnly()
+
+	release()
+	release, ok = s.tryAcquire([]string{"describe"}, config.CommandClassMutation)
+	assert.True(t, ok)
+	release()
+	assert.False(t, s.busy())
+}
+
+func Test_commandScheduler_ReleaseIsIdempotent(t *testing.T) {
+	var s commandScheduler
+	first := s.acquire([]string{"new"}, config.CommandClassMutation)
+
+	acquired := make(chan func())
+	go func() { acquired <- s.acquire([]string{"new"}, config.CommandClassMutation) }()
+	waitFor(t, func() bool { return s.queued() == 1 })
+
+	first()
+	second := <-acquired
+	// releasing the first turn again must not hand out the second one twice
+	first()
+	assert.True(t, s.busy())
+	second()
+	assert.False(t, s.busy())
+}
+
+func Test_commandScheduler_WaitIdle(t *testing.T) {
+	var s commandScheduler
+	release := s.acquire([]string{"new"}, config.CommandClassMutation)
+
+	idle := make(chan struct{})
+	go func() {
+		s.waitIdle()
+		close(idle)
+	}()
+	waitFor(t, func() bool { return s.queued() == 1 })
+
+	select {
+	case <-idle:
+		t.Fatal("waitIdle returned while a command was running")
+	default:
+	}
+	release()
+	<-idle
+	assert.False(t, s.busy())
+}
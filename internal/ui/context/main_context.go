@@ -23,8 +23,10 @@ type SelectedOperation = common.SelectedOperation
 
 type MainContext struct {
 	CommandRunner
-	SelectedItem              SelectedItem   // Single item where cursor is hover.
-	CheckedItems              []SelectedItem // Items checked ✓ by the user.
+	SelectedItem              SelectedItem              // Single item where cursor is hover.
+	CheckedItems              []SelectedItem            // Items checked ✓ by the user.
+	LoadedRevisions           []*jj.Commit              // Revisions loaded in the log, in graph order.
+	Decorations               map[string][]common.Badge // Badges scripts gave the loaded revisions, by commit id.
 	Location                  string
 	JJConfig                  *config.JJConfig
 	DefaultRevset             string
@@ -52,6 +54,22 @@ func NewAppContext(location string, aps *askpass.Server) *MainContext {
 	return m
 }
 
+// CommandHook is implemented by runners that let scripts stop or rewrite the
+// commands run for the user before they run.
+type CommandHook interface {
+	BeforeCommand(args []string, interactive bool, run func(args []string) tea.Cmd, stopped func(err error) tea.Cmd) tea.Cmd
+}
+
+// BeforeCommand runs the command the user asked for through the hooks of the
+// runner, for commands that aren't started with RunCommand, e.g. those run in
+// the terminal. Stopped is called instead of run when a hook stops it.
+func (ctx *MainContext) BeforeCommand(args []string, interactive bool, run func(args []string) tea.Cmd, stopped func(err error) tea.Cmd) tea.Cmd {
+	if hook, ok := ctx.CommandRunner.(CommandHook); ok {
+		return hook.BeforeCommand(args, interactive, run, stopped)
+	}
+	return run(args)
+}
+
 func (ctx *MainContext) ClearCheckedItems(ofType reflect.Type) {
 	ctx.CheckedItems = slices.DeleteFunc(ctx.CheckedItems, func(i SelectedItem) bool {
 		return ofType == nil || ofType == reflect.TypeOf(i)
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package context
+
+import "os/exec"
+
+func setProcessGroup(*exec.Cmd) {}
+
+func setSession(*exec.Cmd) {}
+
+func killProcessGroup(c *exec.Cmd) error {
+	if c.Process == nil {
+		return nil
+	}
+	return c.Process.Kill()
+}
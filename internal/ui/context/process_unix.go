@@ -0,0 +1,32 @@
+//go:build unix
+
+package context
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in its own process group so that
+// canceling it also stops the processes it spawned (e.g. ssh for git).
+func setProcessGroup(c *exec.Cmd) {
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// setSession starts the command in a session of its own, which leads its own
+// process group too, without a controlling terminal.
+func setSession(c *exec.Cmd) {
+	c.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// killProcessGroup kills the command's process group, or only the command when
+// it was left in the foreground group.
+func killProcessGroup(c *exec.Cmd) error {
+	if c.Process == nil {
+		return nil
+	}
+	if c.SysProcAttr == nil || !(c.SysProcAttr.Setpgid || c.SysProcAttr.Setsid) {
+		return c.Process.Kill()
+	}
+	return syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+}
@@ -13,6 +13,7 @@ import (
 
 type viewMode interface {
 	totalLines(width int) int
+	rowOf(line int, width int) int
 	scrollHorizontal(delta int, viewportWidth int)
 	ViewRect(dl *render.DisplayContext, box layout.Box, scrollY int)
 }
@@ -34,6 +35,10 @@ func (v *defaultView) totalLines(_ int) int {
 	return len(v.lines)
 }
 
+func (v *defaultView) rowOf(line int, _ int) int {
+	return line
+}
+
 func (v *defaultView) scrollHorizontal(delta int, viewportWidth int) {
 	maxScroll := max(0, v.maxLineWidth-viewportWidth)
 	v.scrollX = max(0, min(v.scrollX+delta, maxScroll))
@@ -101,6 +106,14 @@ func (v *wrappedView) totalLines(width int) int {
 	return v.totalVisualRows
 }
 
+func (v *wrappedView) rowOf(line int, width int) int {
+	v.ensureIndex(width)
+	if line < 0 || line >= len(v.visualRowStart) {
+		return line
+	}
+	return v.visualRowStart[line]
+}
+
 func (v *wrappedView) firstLine(scrollY int, width int) (line int, skip int) {
 	v.ensureIndex(width)
 	n := len(v.visualRowStart)
@@ -264,6 +277,18 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 	return nil
 }
 
+// ScrollToLine scrolls the given line of the content to the top.
+func (m *Model) ScrollToLine(line int) {
+	m.scrollY = m.mode.rowOf(line, m.viewportWidth)
+}
+
+// VisibleRow returns the row of the viewport the given line of the content
+// starts at, if it is visible.
+func (m *Model) VisibleRow(line int) (int, bool) {
+	row := m.mode.rowOf(line, m.viewportWidth) - m.scrollY
+	return row, row >= 0 && row < m.viewportHeight
+}
+
 func (m *Model) ViewRect(dl *render.DisplayContext, box layout.Box) {
 	width := box.R.Dx()
 	height := box.R.Dy()
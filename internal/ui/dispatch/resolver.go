@@ -16,6 +16,7 @@ type Result struct {
 	Owner         string
 	Args          map[string]any
 	LuaScript     string
+	LuaAction     string
 	Pending       bool
 	Consumed      bool
 	Continuations []Continuation
@@ -94,7 +95,7 @@ func (r *Resolver) resolveAction(action keybindings.Action, args map[string]any,
 		cfg, hasCfg := r.configuredActions[action]
 		if hasCfg {
 			if script := strings.TrimSpace(cfg.Lua); script != "" {
-				return Result{LuaScript: script, Consumed: true}
+				return Result{LuaScript: script, LuaAction: string(action), Consumed: true}
 			}
 			// Configured action with no Lua is invalid config; treat as consumed no-op.
 			return Result{Consumed: true}
@@ -2,17 +2,22 @@ package exec_process
 
 import (
 	"bufio"
+	"errors"
 	"io"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/charmbracelet/x/term"
+	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/common"
 	"github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/intents"
 )
 
 func ExecMsgFromLine(prompt string, line string) common.ExecMsg {
@@ -35,9 +40,24 @@ func ExecLine(ctx *context.MainContext, msg common.ExecMsg) tea.Cmd {
 	replacements := ctx.CreateReplacements()
 	switch msg.Mode {
 	case common.ExecJJ:
-		args := strings.Fields(msg.Line)
+		// quoted arguments keep their spaces, like in a shell
+		args, err := jj.SplitArgs(msg.Line)
+		if err != nil {
+			return func() tea.Msg { return common.ExecProcessCompletedMsg{Err: err, Msg: msg} }
+		}
 		args = jj.TemplatedArgs(args, replacements)
-		return execProgram("jj", args, ctx.Location, nil, msg)
+		paged := config.Current.Exec.Pager && jj.IsReadOnly(args)
+		run := func(args []string) tea.Cmd {
+			if paged {
+				return pageOutput(ctx, args, msg)
+			}
+			return execProgram("jj", args, ctx.Location, nil, msg)
+		}
+		// a stopped command reopens the prompt like a failed one
+		stopped := func(err error) tea.Cmd {
+			return func() tea.Msg { return common.ExecProcessCompletedMsg{Err: err, Msg: msg} }
+		}
+		return ctx.BeforeCommand(args, !paged, run, stopped)
 	case common.ExecShell:
 		// user input is run via `$SHELL -c` to support user specifying command lines
 		// that have pipes (eg, to a pager) or redirection.
@@ -51,6 +71,31 @@ func ExecLine(ctx *context.MainContext, msg common.ExecMsg) tea.Cmd {
 	return nil
 }
 
+// pageOutput runs a jj command that only shows the repository and shows what
+// it prints in the pager. Commands that change the repository run in the
+// terminal, where they can prompt for credentials and aren't cut short by the
+// preview timeout. A failed command reopens the prompt like it does when
+// the command is run in the terminal.
+func pageOutput(ctx *context.MainContext, args []string, msg common.ExecMsg) tea.Cmd {
+	if !slices.ContainsFunc(args, func(arg string) bool { return arg == "--color" || strings.HasPrefix(arg, "--color=") }) {
+		args = append([]string{"--color", "always"}, args...)
+	}
+	return func() tea.Msg {
+		output, err := ctx.RunCommandImmediateCombined(args)
+		completed := func() tea.Msg {
+			return common.ExecProcessCompletedMsg{Err: err, Msg: msg}
+		}
+		if err != nil {
+			text := strings.TrimSpace(ansi.Strip(err.Error()))
+			return tea.BatchMsg{intents.Invoke(intents.AddMessage{Text: text, Err: errors.New(text)}), completed}
+		}
+		show := func() tea.Msg {
+			return common.ShowPagerMsg{Title: "jj " + msg.Line, Content: string(output)}
+		}
+		return tea.BatchMsg{show, completed}
+	}
+}
+
 // This is different from command_runner.RunInteractiveCommand.
 // This function does not capture any IO. We want all IO to be given to the program.
 //
@@ -63,14 +108,28 @@ func ExecLine(ctx *context.MainContext, msg common.ExecMsg) tea.Cmd {
 // CommandCompleted machinery we use for background jj processes.
 // However, if the program fails we ask the user for confirmation before closing
 // and returning stdio back to jjui.
+//
+// With the embedded terminal enabled the program runs in a pane instead, which
+// stays open after it exits until a key is pressed.
 func execProgram(program string, args []string, location string, env map[string]string, msg common.ExecMsg) tea.Cmd {
 	p := &process{program: program, args: args, env: env, location: location}
-	return tea.Exec(p, func(err error) tea.Msg {
+	done := func(err error) tea.Msg {
 		return common.ExecProcessCompletedMsg{
 			Err: err,
 			Msg: msg,
 		}
-	})
+	}
+	if config.Current.UI.Terminal.Embedded {
+		return func() tea.Msg {
+			return common.RunInTerminalMsg{
+				Title: strings.TrimSpace(msg.Mode.Prompt + msg.Line),
+				Cmd:   p.command(),
+				Done:  done,
+				Hold:  true,
+			}
+		}
+	}
+	return tea.Exec(p, done)
 }
 
 type process struct {
@@ -90,13 +149,9 @@ type runResult struct {
 	exitedBeforeTimer bool
 }
 
-// Run This is a blocking call.
-func (p *process) Run() error {
+func (p *process) command() *exec.Cmd {
 	cmd := exec.Command(p.program, p.args...)
 	cmd.Dir = p.location
-	cmd.Stdin = p.stdin
-	cmd.Stdout = p.stdout
-	cmd.Stderr = p.stderr
 	var env []string
 	for k, v := range p.env {
 		name := strings.TrimPrefix(k, "$")
@@ -105,6 +160,15 @@ func (p *process) Run() error {
 	// extend the current environment with context replacements.
 	// this is useful for sub-programs to access context vars.
 	cmd.Env = append(os.Environ(), env...)
+	return cmd
+}
+
+// Run This is a blocking call.
+func (p *process) Run() error {
+	cmd := p.command()
+	cmd.Stdin = p.stdin
+	cmd.Stdout = p.stdout
+	cmd.Stderr = p.stderr
 
 	stdinFile, stdinIsTTY := ttyFile(p.stdin)
 	stopObserve := func() bool { return false }
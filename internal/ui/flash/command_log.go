@@ -0,0 +1,118 @@
+package flash
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/idursun/jjui/internal/config"
+)
+
+// commandLogLimit is how many commands the log keeps.
+const commandLogLimit = 500
+
+// commandLogOutputLimit caps the output stored for each command.
+const commandLogOutputLimit = 8 * 1024
+
+// commandLogRecord is how a completed command is stored, one JSON object per
+// line of the repository's command log.
+type commandLogRecord struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	Args    []string  `json:"args,omitempty"`
+	// Interactive commands are re-run in a terminal.
+	Interactive bool   `json:"interactive,omitempty"`
+	Output      string `json:"output,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// commandLogKey keeps a command log per repository.
+func commandLogKey(location string) config.HistoryKey {
+	return config.HistoryKey("commands " + config.GetRepoID(location))
+}
+
+func (m *Model) commandLog() *config.History {
+	if m.context == nil || m.context.Histories == nil {
+		return nil
+	}
+	log := m.context.Histories.GetHistory(commandLogKey(m.context.Location), false)
+	log.SetLimit(commandLogLimit)
+	return log
+}
+
+// logCommand appends the command to the log, to be written when jjui exits.
+func (m *Model) logCommand(msg flashMessage) {
+	log := m.commandLog()
+	if log == nil {
+		return
+	}
+	record := commandLogRecord{
+		Time:        msg.time,
+		Command:     msg.command,
+		Args:        msg.args,
+		Interactive: msg.interactive,
+		Output:      truncateOutput(msg.text),
+	}
+	if msg.error != nil {
+		record.Error = truncateOutput(msg.error.Error())
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	log.Append(string(data))
+	if m.loggedCommands == nil {
+		m.loggedCommands = make(map[uint64]string)
+	}
+	m.loggedCommands[msg.id] = string(data)
+}
+
+// forgetCommand keeps a deleted command of this session out of the log.
+func (m *Model) forgetCommand(id uint64) {
+	line, ok := m.loggedCommands[id]
+	if !ok {
+		return
+	}
+	delete(m.loggedCommands, id)
+	if log := m.commandLog(); log != nil {
+		log.Remove(line)
+	}
+}
+
+func truncateOutput(s string) string {
+	if len(s) <= commandLogOutputLimit {
+		return s
+	}
+	return strings.ToValidUTF8(s[:commandLogOutputLimit], "")
+}
+
+// EarlierCommandHistory returns the commands that earlier sessions ran in
+// this repository, oldest first.
+func (m *Model) EarlierCommandHistory() []CommandHistoryEntry {
+	log := m.commandLog()
+	if log == nil {
+		return nil
+	}
+	lines := log.Loaded()
+	lines = lines[max(0, len(lines)-commandLogLimit):]
+	entries := make([]CommandHistoryEntry, 0, len(lines))
+	for _, line := range lines {
+		var record commandLogRecord
+		if strings.TrimSpace(line) == "" || json.Unmarshal([]byte(line), &record) != nil {
+			continue
+		}
+		entry := CommandHistoryEntry{
+			Command:     record.Command,
+			Args:        record.Args,
+			Interactive: record.Interactive,
+			Text:        record.Output,
+			Time:        record.Time,
+		}
+		if record.Error != "" {
+			entry.Err = errors.New(record.Error)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
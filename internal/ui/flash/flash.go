@@ -1,6 +1,7 @@
 package flash
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -8,6 +9,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/common"
 	"github.com/idursun/jjui/internal/ui/context"
 	"github.com/idursun/jjui/internal/ui/intents"
@@ -21,11 +23,40 @@ type expireMessageMsg struct {
 	id uint64
 }
 
+type commandOutputMsg struct {
+	id   int
+	line string
+}
+
+type pendingCommand struct {
+	command     string
+	args        []string
+	interactive bool
+	started     time.Time
+	lines       []string
+	output      <-chan string
+	// background commands are dropped silently once they complete
+	background bool
+}
+
 type flashMessage struct {
-	text    string
-	command string
-	error   error
-	id      uint64
+	text        string
+	command     string
+	args        []string
+	interactive bool
+	error       error
+	id          uint64
+	time        time.Time
+	failed      *FailedCommand
+}
+
+// FailedCommand is a failed command that jj's error message suggests a way
+// out of, see [jj.ParseCommandError].
+type FailedCommand struct {
+	Args []string
+	// Interactive commands are retried in a terminal.
+	Interactive bool
+	Recovery    jj.CommandRecovery
 }
 
 type FlashMessageView struct {
@@ -37,8 +68,10 @@ type FlashMessageView struct {
 type Model struct {
 	context         *context.MainContext
 	messages        []flashMessage
-	messageHistory  []flashMessage // completed commands only
-	pendingCommands map[int]string
+	messageHistory  []flashMessage    // completed commands only
+	loggedCommands  map[uint64]string // command log lines of this session, by message id
+	notifications   []Notification
+	pendingCommands map[int]*pendingCommand
 	pendingResults  map[int]pendingResult
 	spinner         spinner.Model
 	successStyle    lipgloss.Style
@@ -46,11 +79,16 @@ type Model struct {
 	textStyle       lipgloss.Style
 	matchedStyle    lipgloss.Style
 	currentId       uint64
+	// focused is whether the terminal has focus, see [tea.FocusMsg].
+	focused bool
 }
 
 const HistoryLimit = 50
 const commandMarkWidth = 3
 
+// outputLinesLimit is how many of the latest output lines a running command shows.
+const outputLinesLimit = 5
+
 type pendingResult struct {
 	Output string
 	Err    error
@@ -68,19 +106,38 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 		m.removeLiveMessageByID(msg.id)
 		return nil
 	case common.CommandRunningMsg:
-		m.pendingCommands[msg.ID] = msg.Command
 		if result, ok := m.pendingResults[msg.ID]; ok {
-			delete(m.pendingCommands, msg.ID)
 			delete(m.pendingResults, msg.ID)
-			return m.completeCommand(msg.Command, result.Output, result.Err)
+			if msg.Background {
+				return nil
+			}
+			return m.completeCommand(pendingCommand{command: msg.Command, args: msg.Args, interactive: msg.Interactive}, result.Output, result.Err)
+		}
+		m.pendingCommands[msg.ID] = &pendingCommand{
+			command:     msg.Command,
+			args:        msg.Args,
+			interactive: msg.Interactive,
+			started:     time.Now(),
+			output:      msg.Output,
+			background:  msg.Background,
+		}
+		return tea.Batch(m.spinner.Tick, listenForOutput(msg.ID, msg.Output))
+	case commandOutputMsg:
+		pending, ok := m.pendingCommands[msg.id]
+		if !ok {
+			return nil
 		}
-		return m.spinner.Tick
+		pending.lines = append(pending.lines, msg.line)
+		if len(pending.lines) > outputLinesLimit {
+			pending.lines = pending.lines[len(pending.lines)-outputLinesLimit:]
+		}
+		return listenForOutput(msg.id, pending.output)
 	case common.CommandCompletedMsg:
 		if msg.ID == 0 {
-			return m.completeCommand("", msg.Output, msg.Err)
+			return m.completeCommand(pendingCommand{}, msg.Output, msg.Err)
 		}
-		cmd := m.pendingCommands[msg.ID]
-		if cmd == "" {
+		pending, ok := m.pendingCommands[msg.ID]
+		if !ok {
 			if m.pendingResults == nil {
 				m.pendingResults = make(map[int]pendingResult)
 			}
@@ -91,7 +148,17 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			return nil
 		}
 		delete(m.pendingCommands, msg.ID)
-		return m.completeCommand(cmd, msg.Output, msg.Err)
+		if pending.background {
+			return nil
+		}
+		return tea.Batch(
+			m.completeCommand(*pending, msg.Output, msg.Err),
+			m.notifyTerminal(pending, msg.Err),
+		)
+	case tea.FocusMsg:
+		m.focused = true
+	case tea.BlurMsg:
+		m.focused = false
 	case common.UpdateRevisionsFailedMsg:
 		m.add(msg.Output, msg.Err)
 	default:
@@ -147,10 +214,43 @@ func (m *Model) renderMessages(dl *render.DisplayContext, area layout.Rectangle,
 	return y
 }
 
+// listenForOutput waits for the next output line of a running command.
+func listenForOutput(id int, output <-chan string) tea.Cmd {
+	if output == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		line, ok := <-output
+		if !ok {
+			return nil
+		}
+		return commandOutputMsg{id: id, line: line}
+	}
+}
+
+// LatestPendingCommand returns the id of the most recently started command that is still running.
+func (m *Model) LatestPendingCommand() (int, bool) {
+	latest, found := 0, false
+	for id, pending := range m.pendingCommands {
+		if !found || pending.started.After(m.pendingCommands[latest].started) {
+			latest, found = id, true
+		}
+	}
+	return latest, found
+}
+
 func (m *Model) renderPendingCommands(dl *render.DisplayContext, area layout.Rectangle, y int) int {
 	maxWidth := area.Dx() - 4
-	for _, cmd := range m.pendingCommands {
-		content := m.renderCommandLine(cmd, nil, true)
+	for _, pending := range m.pendingCommands {
+		elapsed := time.Since(pending.started).Truncate(time.Second)
+		parts := []string{m.renderCommandLine(pending.command, nil, true) + m.textStyle.Render(fmt.Sprintf(" %s", elapsed))}
+		for _, line := range pending.lines {
+			parts = append(parts, m.textStyle.Render(line))
+		}
+		if key := config.GetBindingKeyHint(config.Current, "ui.cancel_command"); key != "" {
+			parts = append(parts, m.matchedStyle.Render(key)+m.textStyle.Render(" cancel"))
+		}
+		content := strings.Join(parts, "\n")
 		w, h := lipgloss.Size(content)
 		if w > maxWidth {
 			content = lipgloss.NewStyle().Width(maxWidth).Render(content)
@@ -203,6 +303,11 @@ func (m *Model) renderMessageContent(message flashMessage, maxWidth int) string
 	if bodyText != "" {
 		parts = append(parts, style.Render(bodyText))
 	}
+	if message.failed != nil {
+		if key := config.GetBindingKeyHint(config.Current, "ui.recover_command"); key != "" {
+			parts = append(parts, m.matchedStyle.Render(key)+m.textStyle.Render(" "+message.failed.Recovery.String()))
+		}
+	}
 
 	text := strings.Join(parts, "\n")
 	naturalContent := text
@@ -213,8 +318,13 @@ func (m *Model) renderMessageContent(message flashMessage, maxWidth int) string
 	return lipgloss.NewStyle().Border(lipgloss.NormalBorder()).PaddingLeft(1).PaddingRight(1).BorderForeground(style.GetForeground()).Render(naturalContent)
 }
 
-func (m *Model) completeCommand(command string, output string, commandErr error) tea.Cmd {
-	id := m.AddWithCommand(output, command, commandErr)
+func (m *Model) completeCommand(command pendingCommand, output string, commandErr error) tea.Cmd {
+	id := m.addCommand(output, command.command, command.args, command.interactive, commandErr)
+	if commandErr != nil && len(command.args) > 0 {
+		if recovery, ok := jj.ParseCommandError(command.args, commandErr.Error()); ok {
+			m.setFailed(id, &FailedCommand{Args: command.args, Interactive: command.interactive, Recovery: recovery})
+		}
+	}
 	if id != 0 && commandErr == nil {
 		expiringMessageTimeout := config.GetExpiringFlashMessageTimeout(config.Current)
 		if expiringMessageTimeout > time.Duration(0) {
@@ -262,20 +372,29 @@ func (m *Model) add(text string, error error) uint64 {
 }
 
 func (m *Model) AddWithCommand(text string, command string, error error) uint64 {
+	return m.addCommand(text, command, nil, false, error)
+}
+
+func (m *Model) addCommand(text string, command string, args []string, interactive bool, error error) uint64 {
 	text = strings.TrimSpace(text)
 	if text == "" && error == nil && command == "" {
 		return 0
 	}
 
 	msg := flashMessage{
-		id:      m.nextId(),
-		text:    text,
-		command: command,
-		error:   error,
+		id:          m.nextId(),
+		text:        text,
+		command:     command,
+		args:        args,
+		interactive: interactive,
+		error:       error,
+		time:        time.Now(),
 	}
 
 	m.messages = append(m.messages, msg)
+	m.recordNotification(msg)
 	if msg.command != "" {
+		m.logCommand(msg)
 		m.messageHistory = append(m.messageHistory, msg)
 		if len(m.messageHistory) > HistoryLimit {
 			m.messageHistory = append([]flashMessage(nil), m.messageHistory[len(m.messageHistory)-HistoryLimit:]...)
@@ -284,6 +403,27 @@ func (m *Model) AddWithCommand(text string, command string, error error) uint64
 	return msg.id
 }
 
+func (m *Model) setFailed(id uint64, failed *FailedCommand) {
+	for i := range m.messages {
+		if m.messages[i].id == id {
+			m.messages[i].failed = failed
+			return
+		}
+	}
+}
+
+// TakeFailedCommand dismisses the newest message that offers a recovery and
+// returns its failed command.
+func (m *Model) TakeFailedCommand() (FailedCommand, bool) {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if failed := m.messages[i].failed; failed != nil {
+			m.messages = append(m.messages[:i], m.messages[i+1:]...)
+			return *failed, true
+		}
+	}
+	return FailedCommand{}, false
+}
+
 func (m *Model) Any() bool {
 	return len(m.messages) > 0
 }
@@ -300,14 +440,21 @@ func (m *Model) DeleteOldest() {
 }
 
 type CommandHistoryEntry struct {
+	// ID is 0 for the commands of earlier sessions.
 	ID      uint64
 	Command string
-	Text    string
-	Err     error
+	// Args re-run the command; nil when it can't be re-run as is.
+	Args []string
+	// Interactive commands are re-run in a terminal.
+	Interactive bool
+	Text        string
+	Err         error
+	Time        time.Time
 }
 
 type CommandHistorySource interface {
 	CommandHistorySnapshot() []CommandHistoryEntry
+	EarlierCommandHistory() []CommandHistoryEntry
 	DeleteCommandHistoryByID(id uint64)
 }
 
@@ -315,10 +462,13 @@ func (m *Model) CommandHistorySnapshot() []CommandHistoryEntry {
 	out := make([]CommandHistoryEntry, 0, len(m.messageHistory))
 	for _, item := range m.messageHistory {
 		out = append(out, CommandHistoryEntry{
-			ID:      item.id,
-			Command: item.command,
-			Text:    item.text,
-			Err:     item.error,
+			ID:          item.id,
+			Command:     item.command,
+			Args:        item.args,
+			Interactive: item.interactive,
+			Text:        item.text,
+			Err:         item.error,
+			Time:        item.time,
 		})
 	}
 	return out
@@ -332,6 +482,7 @@ func (m *Model) DeleteCommandHistoryByID(id uint64) {
 		m.messageHistory = append(m.messageHistory[:i], m.messageHistory[i+1:]...)
 		break
 	}
+	m.forgetCommand(id)
 	m.removeLiveMessageByID(id)
 }
 
@@ -351,12 +502,13 @@ func New(context *context.MainContext) *Model {
 		context:         context,
 		messages:        make([]flashMessage, 0),
 		messageHistory:  make([]flashMessage, 0),
-		pendingCommands: make(map[int]string),
+		pendingCommands: make(map[int]*pendingCommand),
 		pendingResults:  make(map[int]pendingResult),
 		successStyle:    successStyle,
 		errorStyle:      errorStyle,
 		textStyle:       textStyle,
 		matchedStyle:    matchedStyle,
 		spinner:         s,
+		focused:         true,
 	}
 }
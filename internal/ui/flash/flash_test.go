@@ -101,3 +101,82 @@ func TestDeleteOldest_RemovesFirstMessage(t *testing.T) {
 		assert.Equal(t, "second", m.messages[0].text)
 	}
 }
+
+func TestUpdate_StreamsOutputOfRunningCommand(t *testing.T) {
+	m := New(test.NewTestContext(test.NewTestCommandRunner(t)))
+	output := make(chan string, 2)
+	output <- "Fetching origin"
+	output <- "remote: Counting objects: 50%"
+	close(output)
+
+	cmd := m.Update(common.CommandRunningMsg{ID: 1, Command: "jj git fetch", Output: output})
+	assert.NotNil(t, cmd)
+	m.Update(listenForOutput(1, output)())
+	m.Update(listenForOutput(1, output)())
+	assert.Nil(t, listenForOutput(1, output)(), "closed output stops listening")
+
+	id, ok := m.LatestPendingCommand()
+	assert.True(t, ok)
+	assert.Equal(t, 1, id)
+
+	rendered := test.RenderImmediate(m, 80, 20)
+	assert.Contains(t, rendered, "jj git fetch")
+	assert.Contains(t, rendered, "Fetching origin")
+	assert.Contains(t, rendered, "Counting objects: 50%")
+
+	m.Update(common.CommandCompletedMsg{ID: 1})
+	_, ok = m.LatestPendingCommand()
+	assert.False(t, ok)
+}
+
+func TestUpdate_BackgroundCommandLeavesNoMessage(t *testing.T) {
+	m := New(test.NewTestContext(test.NewTestCommandRunner(t)))
+
+	m.Update(common.CommandRunningMsg{ID: 1, Command: "jj log", Background: true})
+	id, ok := m.LatestPendingCommand()
+	assert.True(t, ok, "background commands can be canceled while they run")
+	assert.Equal(t, 1, id)
+	assert.Contains(t, test.RenderImmediate(m, 80, 20), "jj log")
+
+	assert.Nil(t, m.Update(common.CommandCompletedMsg{ID: 1, Background: true}))
+	_, ok = m.LatestPendingCommand()
+	assert.False(t, ok)
+	assert.Empty(t, m.messages)
+
+	// the completion may arrive before the command is reported as running
+	m.Update(common.CommandCompletedMsg{ID: 2, Background: true})
+	assert.Nil(t, m.Update(common.CommandRunningMsg{ID: 2, Command: "jj log", Background: true}))
+	_, ok = m.LatestPendingCommand()
+	assert.False(t, ok)
+	assert.Empty(t, m.messages)
+}
+
+func TestUpdate_FailedCommandOffersRecovery(t *testing.T) {
+	m := New(test.NewTestContext(test.NewTestCommandRunner(t)))
+	args := []string{"abandon", "-r", "abc"}
+
+	m.Update(common.CommandRunningMsg{ID: 1, Command: "jj abandon -r abc", Args: args})
+	m.Update(common.CommandCompletedMsg{ID: 1, Err: errors.New("Error: Commit abc is immutable")})
+
+	rendered := test.RenderImmediate(m, 80, 20)
+	assert.Contains(t, rendered, "retry with --ignore-immutable")
+
+	failed, ok := m.TakeFailedCommand()
+	assert.True(t, ok)
+	assert.Equal(t, args, failed.Args)
+	assert.Equal(t, []string{"abandon", "-r", "abc", "--ignore-immutable"}, failed.Recovery.RetryArgs(failed.Args))
+	assert.Equal(t, 0, m.LiveMessagesCount())
+
+	_, ok = m.TakeFailedCommand()
+	assert.False(t, ok)
+}
+
+func TestUpdate_FailedCommandWithoutArgsOffersNoRecovery(t *testing.T) {
+	m := New(test.NewTestContext(test.NewTestCommandRunner(t)))
+
+	m.Update(common.CommandRunningMsg{ID: 1, Command: "jj describe --stdin"})
+	m.Update(common.CommandCompletedMsg{ID: 1, Err: errors.New("Error: Commit abc is immutable")})
+
+	_, ok := m.TakeFailedCommand()
+	assert.False(t, ok)
+}
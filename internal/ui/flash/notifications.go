@@ -0,0 +1,104 @@
+package flash
+
+import (
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/idursun/jjui/internal/config"
+)
+
+// NotificationLimit is how many messages the notification center keeps.
+const NotificationLimit = 200
+
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeveritySuccess
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeveritySuccess:
+		return "success"
+	case SeverityError:
+		return "error"
+	}
+	return "info"
+}
+
+// Notification is a flash message as listed in the notification center. It
+// outlives the message, which expires or gets dismissed.
+type Notification struct {
+	Severity Severity
+	Time     time.Time
+	// Command is the command the message originates from, if any.
+	Command string
+	Text    string
+}
+
+func (m *Model) Notifications() []Notification {
+	return append([]Notification(nil), m.notifications...)
+}
+
+func (m *Model) recordNotification(message flashMessage) {
+	notification := Notification{
+		Severity: SeverityInfo,
+		Time:     message.time,
+		Command:  message.command,
+		Text:     message.text,
+	}
+	switch {
+	case message.error != nil:
+		notification.Severity = SeverityError
+		notification.Text = message.error.Error()
+	case message.command != "":
+		notification.Severity = SeveritySuccess
+	}
+	m.notifications = append(m.notifications, notification)
+	if len(m.notifications) > NotificationLimit {
+		m.notifications = append([]Notification(nil), m.notifications[len(m.notifications)-NotificationLimit:]...)
+	}
+}
+
+// notifyTerminal tells the terminal that a long command finished while it
+// wasn't focused, using the configured method.
+func (m *Model) notifyTerminal(pending *pendingCommand, commandErr error) tea.Cmd {
+	if m.focused || pending.command == "" {
+		return nil
+	}
+	if time.Since(pending.started) < config.GetNotificationMinDuration(config.Current) {
+		return nil
+	}
+	method, err := config.GetNotificationMethod(config.Current)
+	if err != nil {
+		return nil
+	}
+	body := pending.command + " finished"
+	if commandErr != nil {
+		body = pending.command + " failed"
+	}
+	if sequence := notificationSequence(method, "jjui", body); sequence != "" {
+		return tea.Raw(sequence)
+	}
+	return nil
+}
+
+// notificationSequence returns the escape sequence that sends a desktop
+// notification, or rings the bell.
+func notificationSequence(method config.NotificationMethod, title string, body string) string {
+	// control characters would end the sequence early
+	sanitize := strings.NewReplacer("\x07", "", "\x1b", "", "\n", " ", ";", ",")
+	switch method {
+	case config.NotificationMethodOSC9:
+		return ansi.Notify(sanitize.Replace(body))
+	case config.NotificationMethodOSC777:
+		return "\x1b]777;notify;" + sanitize.Replace(title) + ";" + sanitize.Replace(body) + "\x07"
+	case config.NotificationMethodBell:
+		return "\a"
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package flash
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifications_RecordsEveryMessageWithSeverity(t *testing.T) {
+	m := New(test.NewTestContext(test.NewTestCommandRunner(t)))
+
+	m.Update(intents.AddMessage{Text: "Copied to clipboard"})
+	m.AddWithCommand("Nothing changed.", "jj new", nil)
+	m.AddWithCommand("", "jj git push", errors.New("Error: refusing to push"))
+	m.DeleteOldest()
+
+	notifications := m.Notifications()
+	if assert.Len(t, notifications, 3) {
+		assert.Equal(t, SeverityInfo, notifications[0].Severity)
+		assert.Equal(t, "Copied to clipboard", notifications[0].Text)
+		assert.Equal(t, SeveritySuccess, notifications[1].Severity)
+		assert.Equal(t, "jj new", notifications[1].Command)
+		assert.Equal(t, SeverityError, notifications[2].Severity)
+		assert.Equal(t, "Error: refusing to push", notifications[2].Text)
+	}
+}
+
+func TestNotifications_AreBounded(t *testing.T) {
+	m := New(test.NewTestContext(test.NewTestCommandRunner(t)))
+
+	for i := 1; i <= NotificationLimit+5; i++ {
+		m.add(fmt.Sprintf("m-%d", i), nil)
+	}
+
+	notifications := m.Notifications()
+	if assert.Len(t, notifications, NotificationLimit) {
+		assert.Equal(t, "m-6", notifications[0].Text)
+	}
+}
+
+func TestNotifyTerminal_OnlyWhenUnfocusedAndLong(t *testing.T) {
+	orig := config.Current.UI.Notifications
+	t.Cleanup(func() { config.Current.UI.Notifications = orig })
+	config.Current.UI.Notifications = config.NotificationsConfig{Method: "bell", MinDuration: 10}
+
+	m := New(test.NewTestContext(test.NewTestCommandRunner(t)))
+	long := &pendingCommand{command: "jj git fetch", started: time.Now().Add(-time.Minute)}
+	short := &pendingCommand{command: "jj git fetch", started: time.Now()}
+
+	assert.Nil(t, m.notifyTerminal(long, nil), "focused terminal isn't notified")
+
+	m.Update(tea.BlurMsg{})
+	assert.NotNil(t, m.notifyTerminal(long, nil))
+	assert.Nil(t, m.notifyTerminal(short, nil))
+
+	m.Update(tea.FocusMsg{})
+	assert.Nil(t, m.notifyTerminal(long, nil))
+
+	m.Update(tea.BlurMsg{})
+	config.Current.UI.Notifications.Method = "none"
+	assert.Nil(t, m.notifyTerminal(long, nil))
+}
+
+func TestNotifyTerminal_CompletedCommand(t *testing.T) {
+	orig := config.Current.UI.Notifications
+	t.Cleanup(func() { config.Current.UI.Notifications = orig })
+	config.Current.UI.Notifications = config.NotificationsConfig{Method: "osc9"}
+
+	m := New(test.NewTestContext(test.NewTestCommandRunner(t)))
+	m.Update(tea.BlurMsg{})
+	m.Update(common.CommandRunningMsg{ID: 1, Command: "jj git fetch"})
+
+	assert.NotNil(t, m.Update(common.CommandCompletedMsg{ID: 1, Err: errors.New("boom")}))
+}
+
+func TestNotificationSequence(t *testing.T) {
+	assert.Equal(t, "\x1b]9;jj git fetch finished\x07", notificationSequence(config.NotificationMethodOSC9, "jjui", "jj git fetch finished"))
+	assert.Equal(t, "\x1b]777;notify;jjui;jj describe -m a,b failed\x07", notificationSequence(config.NotificationMethodOSC777, "jjui", "jj describe -m a;b\x07 failed"))
+	assert.Equal(t, "\a", notificationSequence(config.NotificationMethodBell, "jjui", "done"))
+	assert.Empty(t, notificationSequence(config.NotificationMethodNone, "jjui", "done"))
+}
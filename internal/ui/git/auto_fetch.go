@@ -0,0 +1,27 @@
+package git
+
+import (
+	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/context"
+)
+
+// BackgroundFetchMsg reports the remote bookmarks that changed during a background fetch.
+type BackgroundFetchMsg struct {
+	Changes []jj.RemoteBookmarkChange
+	Err     error
+}
+
+// BackgroundFetch runs `jj git fetch` without reporting it as a user command
+// and diffs the remote bookmarks before and after the fetch.
+func BackgroundFetch(runner context.CommandRunner) tea.Cmd {
+	return func() tea.Msg {
+		before, _ := runner.RunCommandImmediate(jj.BookmarkListAll())
+		if _, err := runner.RunCommandBackground(jj.GitFetch()); err != nil {
+			return BackgroundFetchMsg{Err: err}
+		}
+		after, _ := runner.RunCommandImmediate(jj.BookmarkListAll())
+		changes := jj.DiffRemoteBookmarks(jj.ParseBookmarkListOutput(string(before)), jj.ParseBookmarkListOutput(string(after)))
+		return BackgroundFetchMsg{Changes: changes}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/actions"
 	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/confirmation"
 	"github.com/idursun/jjui/internal/ui/context"
 	"github.com/idursun/jjui/internal/ui/intents"
 	"github.com/idursun/jjui/internal/ui/layout"
@@ -31,6 +32,7 @@ type SelectRemoteMsg struct {
 
 type item struct {
 	category itemCategory
+	pushKind string
 	name     string
 	desc     string
 	command  []string
@@ -114,14 +116,15 @@ type Model struct {
 	menuStyles          menuStyles
 	remoteStyles        remoteStyles
 	title               string
+	confirmation        *confirmation.Model
 }
 
 func (m *Model) IsFocused() bool {
-	return m.filterState == filterEditing
+	return m.filterState == filterEditing || m.confirmation != nil
 }
 
 func (m *Model) IsEditing() bool {
-	return m.filterState == filterEditing
+	return m.filterState == filterEditing || m.confirmation != nil
 }
 
 func (m *Model) StackedActionOwner() string {
@@ -151,7 +154,18 @@ func (m *Model) cycleRemotes(step int) tea.Cmd {
 }
 
 func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	if m.confirmation != nil {
+		switch msg := msg.(type) {
+		case confirmation.CloseMsg:
+			m.confirmation = nil
+			return nil
+		case tea.KeyMsg, intents.Apply, intents.Cancel:
+			return m.confirmation.Update(msg)
+		}
+	}
 	switch msg := msg.(type) {
+	case pushPreviewMsg:
+		return m.showPushPreview(msg)
 	case itemClickMsg:
 		items := m.visibleItems()
 		if msg.Index >= 0 && msg.Index < len(items) {
@@ -217,7 +231,7 @@ func (m *Model) handleIntent(intent intents.Intent) tea.Cmd {
 		if !ok {
 			return nil
 		}
-		return tea.Batch(common.CloseApplied, m.context.RunCommand(jj.Args(selected.command...), common.Refresh))
+		return m.execute(selected)
 	case intents.GitFilter:
 		filter := string(msg.Kind)
 		if filter == "" {
@@ -259,7 +273,7 @@ func (m *Model) handleIntent(intent intents.Intent) tea.Cmd {
 		}
 		for _, listItem := range m.visibleItems() {
 			if listItem.key == msg.Key {
-				return tea.Batch(common.CloseApplied, m.context.RunCommand(jj.Args(listItem.command...), common.Refresh))
+				return m.execute(listItem)
 			}
 		}
 		return nil
@@ -282,18 +296,18 @@ func (m *Model) executeDefaultForFilter(kind intents.GitFilterKind) tea.Cmd {
 	if ok {
 		for _, listItem := range m.visibleItems() {
 			if slices.Equal(listItem.command, defaultCommand) {
-				return tea.Batch(common.CloseApplied, m.context.RunCommand(jj.Args(listItem.command...), common.Refresh))
+				return m.execute(listItem)
 			}
 		}
 	}
 
 	if selected, ok := m.selectedItem(); ok {
-		return tea.Batch(common.CloseApplied, m.context.RunCommand(jj.Args(selected.command...), common.Refresh))
+		return m.execute(selected)
 	}
 
 	for _, listItem := range m.visibleItems() {
 		if string(listItem.category) == string(kind) {
-			return tea.Batch(common.CloseApplied, m.context.RunCommand(jj.Args(listItem.command...), common.Refresh))
+			return m.execute(listItem)
 		}
 	}
 	return nil
@@ -347,6 +361,11 @@ func (m *Model) ViewRect(dl *render.DisplayContext, box layout.Box) {
 
 	_, listBox := contentBox.CutTop(1)
 	m.renderList(dl, listBox)
+
+	if m.confirmation != nil {
+		w, h := lipgloss.Size(m.confirmation.View())
+		m.confirmation.ViewRect(dl, box.Center(w, h))
+	}
 }
 
 func (m *Model) renderRemotes(dl *render.DisplayContext, lineBox layout.Box) {
@@ -653,6 +672,7 @@ func (m *Model) createMenuItems() []item {
 					desc:     fmt.Sprintf("Git push bookmark %s to %s", b.Name, remote.Remote),
 					command:  jj.GitPush("--bookmark", b.Name, "--remote", remote.Remote),
 					category: itemCategoryPush,
+					pushKind: pushKindBookmark,
 				})
 			}
 		}
@@ -663,6 +683,7 @@ func (m *Model) createMenuItems() []item {
 			desc:     "Push tracking bookmarks in the current revset",
 			command:  jj.GitPush("--remote", selectedRemote),
 			category: itemCategoryPush,
+			pushKind: pushKindRemote,
 			key:      "p",
 		},
 		item{
@@ -670,6 +691,7 @@ func (m *Model) createMenuItems() []item {
 			desc:     "Push all bookmarks (including new and deleted bookmarks)",
 			command:  jj.GitPush("--all", "--deleted", "--remote", selectedRemote),
 			category: itemCategoryPush,
+			pushKind: pushKindAll,
 			key:      "a",
 		},
 	)
@@ -682,6 +704,7 @@ func (m *Model) createMenuItems() []item {
 		items = append(items,
 			item{
 				category: itemCategoryPush,
+				pushKind: pushKindChange,
 				name:     fmt.Sprintf("git push %s", strings.Join(revisions.AsPrefixedArgs("--change"), " ")),
 				desc:     fmt.Sprintf("Push selected changes (%s)", strings.Join(revisions.GetIds(), " ")),
 				command:  jj.GitPush(flags...),
@@ -692,6 +715,7 @@ func (m *Model) createMenuItems() []item {
 	for _, commit := range revisions.Revisions {
 		item := item{
 			category: itemCategoryPush,
+			pushKind: pushKindChange,
 			name:     fmt.Sprintf("git push --change %s --remote %s", commit.GetChangeId(), selectedRemote),
 			desc:     fmt.Sprintf("Push the current change (%s)", commit.GetChangeId()),
 			command:  jj.GitPush("--change", commit.GetChangeId(), "--remote", selectedRemote),
@@ -708,6 +732,7 @@ func (m *Model) createMenuItems() []item {
 			desc:     "Push all deleted bookmarks",
 			command:  jj.GitPush("--deleted", "--remote", selectedRemote),
 			category: itemCategoryPush,
+			pushKind: pushKindDeleted,
 			key:      "d",
 		},
 		item{
@@ -715,6 +740,7 @@ func (m *Model) createMenuItems() []item {
 			desc:     "Push all tracked bookmarks",
 			command:  jj.GitPush("--tracked", "--remote", selectedRemote),
 			category: itemCategoryPush,
+			pushKind: pushKindTracked,
 			key:      "t",
 		},
 		item{
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/intents"
 	"github.com/idursun/jjui/internal/ui/layout"
@@ -120,3 +121,50 @@ func TestGit_ZIndex_RendersAboveMainContent(t *testing.T) {
 	rendered := dl.RenderToString(box.R.Dx(), box.R.Dy())
 	assert.Contains(t, rendered, "Remotes:", "git overlay should remain visible above base content")
 }
+
+func Test_PushAll_PreviewsBeforePushing(t *testing.T) {
+	previous := config.Current.Git.ConfirmPush
+	defer func() { config.Current.Git.ConfirmPush = previous }()
+	config.Current.Git.ConfirmPush = []string{"all"}
+
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.GitRemoteList()).SetOutput([]byte("origin"))
+	commandRunner.Expect(jj.GitPush("--all", "--deleted", "--remote", "origin", "--dry-run")).SetOutput([]byte(`Changes to push to origin:
+  Add bookmark feature to 1a2b3c4d5e6f
+  Delete bookmark old from 6a6a6a6a6a6a
+Dry-run requested, not pushing.`))
+	commandRunner.Expect(jj.GitPush("--all", "--deleted", "--remote", "origin"))
+	defer commandRunner.Verify()
+
+	op := NewModel(test.NewTestContext(commandRunner), jj.NewSelectedRevisions())
+	test.SimulateModel(op, op.Init())
+	test.SimulateModel(op, func() tea.Msg { return intents.GitFilter{Kind: intents.GitFilterPush} })
+	test.SimulateModel(op, func() tea.Msg { return intents.GitApplyShortcut{Key: "a"} })
+	assert.NotNil(t, op.confirmation)
+	assert.True(t, op.IsEditing())
+
+	rendered := test.RenderImmediate(op, 100, 40)
+	assert.Contains(t, rendered, "create feature to 1a2b3c4d5e6f")
+	assert.Contains(t, rendered, "delete old from 6a6a6a6a6a6a")
+
+	test.SimulateModel(op, test.Press('y'))
+}
+
+func Test_PushAll_PreviewDeclined(t *testing.T) {
+	previous := config.Current.Git.ConfirmPush
+	defer func() { config.Current.Git.ConfirmPush = previous }()
+	config.Current.Git.ConfirmPush = []string{"all"}
+
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.GitRemoteList()).SetOutput([]byte("origin"))
+	commandRunner.Expect(jj.GitPush("--all", "--deleted", "--remote", "origin", "--dry-run")).SetOutput([]byte(`Changes to push to origin:
+  Move sideways bookmark main from 0a0a0a0a0a0a to 1b1b1b1b1b1b`))
+	defer commandRunner.Verify()
+
+	op := NewModel(test.NewTestContext(commandRunner), jj.NewSelectedRevisions())
+	test.SimulateModel(op, op.Init())
+	test.SimulateModel(op, func() tea.Msg { return intents.GitFilter{Kind: intents.GitFilterPush} })
+	test.SimulateModel(op, func() tea.Msg { return intents.GitApplyShortcut{Key: "a"} })
+	test.SimulateModel(op, func() tea.Msg { return intents.Cancel{} })
+	assert.Nil(t, op.confirmation)
+}
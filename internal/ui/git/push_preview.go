@@ -0,0 +1,87 @@
+package git
+
+import (
+	"fmt"
+	"slices"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/confirmation"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/internal/ui/render"
+)
+
+// Push item kinds, as listed in the `git.confirm_push` setting.
+const (
+	pushKindBookmark = "bookmark"
+	pushKindRemote   = "remote"
+	pushKindAll      = "all"
+	pushKindChange   = "change"
+	pushKindDeleted  = "deleted"
+	pushKindTracked  = "tracked"
+)
+
+type pushPreviewMsg struct {
+	item item
+	plan jj.PushPlan
+	err  error
+}
+
+// execute runs the item's command, first previewing it with --dry-run when
+// the item's push kind is configured to need confirmation.
+func (m *Model) execute(it item) tea.Cmd {
+	if it.category == itemCategoryPush && config.ShouldConfirmPush(config.Current, it.pushKind) {
+		return m.previewPush(it)
+	}
+	return m.run(it)
+}
+
+func (m *Model) run(it item) tea.Cmd {
+	return tea.Batch(common.CloseApplied, m.context.RunCommand(jj.Args(it.command...), common.Refresh))
+}
+
+func (m *Model) previewPush(it item) tea.Cmd {
+	args := append(slices.Clone(it.command), "--dry-run")
+	// the dry run is a push as far as the before_command hooks are concerned
+	return m.context.BeforeCommand(jj.Args(args...), false, func(args []string) tea.Cmd {
+		return func() tea.Msg {
+			// the dry run still talks to the remote, so it may need to ask for credentials
+			output, err := m.context.RunCommandBackground(args)
+			if err != nil {
+				return pushPreviewMsg{item: it, err: err}
+			}
+			return pushPreviewMsg{item: it, plan: jj.ParsePushDryRunOutput(string(output))}
+		}
+	}, nil)
+}
+
+func (m *Model) showPushPreview(msg pushPreviewMsg) tea.Cmd {
+	if msg.err != nil {
+		return intents.Invoke(intents.AddMessage{Text: msg.err.Error(), Err: msg.err})
+	}
+	if len(msg.plan.Updates) == 0 {
+		return intents.Invoke(intents.AddMessage{Text: "Nothing to push"})
+	}
+
+	title := "Planned changes:"
+	if msg.plan.Remote != "" {
+		title = fmt.Sprintf("Planned changes to %s:", msg.plan.Remote)
+	}
+	messages := []string{title}
+	for _, update := range msg.plan.Updates {
+		messages = append(messages, "  "+update.String())
+	}
+	messages = append(messages, "", "Do you want to push?")
+
+	m.confirmation = confirmation.New(
+		messages,
+		confirmation.WithStylePrefix("git"),
+		confirmation.WithZIndex(render.ZMenuDialog),
+		confirmation.WithOption("Yes", m.run(msg.item), key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "yes"))),
+		confirmation.WithOption("No", confirmation.Close, key.NewBinding(key.WithKeys("n", "esc"), key.WithHelp("n/esc", "no"))),
+	)
+	return m.confirmation.Init()
+}
@@ -0,0 +1,336 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/actions"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/confirmation"
+	"github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/internal/ui/layout"
+	"github.com/idursun/jjui/internal/ui/render"
+)
+
+type updateRemotesMsg struct {
+	remotes []jj.Remote
+	err     error
+}
+
+type remotePromptKind int
+
+const (
+	remotePromptNone remotePromptKind = iota
+	remotePromptAddName
+	remotePromptAddUrl
+	remotePromptRename
+	remotePromptSetUrl
+)
+
+var _ common.ImmediateModel = (*RemotesModel)(nil)
+var _ common.Focusable = (*RemotesModel)(nil)
+var _ common.Editable = (*RemotesModel)(nil)
+
+// RemotesModel lists the git remotes of the repository with their URLs and
+// lets the user add, remove, rename and re-point them.
+type RemotesModel struct {
+	context             *context.MainContext
+	remotes             []jj.Remote
+	cursor              int
+	err                 error
+	loading             bool
+	prompt              remotePromptKind
+	promptInput         textinput.Model
+	promptTarget        string
+	confirmation        *confirmation.Model
+	listRenderer        *render.ListRenderer
+	ensureCursorVisible bool
+	menuStyles          menuStyles
+}
+
+func NewRemotesModel(c *context.MainContext) *RemotesModel {
+	m := &RemotesModel{
+		context:      c,
+		loading:      true,
+		listRenderer: render.NewListRenderer(itemScrollMsg{}),
+		menuStyles:   createMenuStyles("git"),
+	}
+	m.listRenderer.Z = render.ZMenuContent
+	m.promptInput = textinput.New()
+	styles := m.promptInput.Styles()
+	styles.Focused.Prompt = m.menuStyles.matched.PaddingLeft(1)
+	styles.Focused.Text = m.menuStyles.text
+	styles.Blurred.Prompt = m.menuStyles.matched.PaddingLeft(1)
+	styles.Blurred.Text = m.menuStyles.text
+	m.promptInput.SetStyles(styles)
+	return m
+}
+
+func (m *RemotesModel) IsFocused() bool {
+	return m.IsEditing()
+}
+
+func (m *RemotesModel) IsEditing() bool {
+	return m.prompt != remotePromptNone || m.confirmation != nil
+}
+
+func (m *RemotesModel) StackedActionOwner() string {
+	return actions.OwnerGitRemotes
+}
+
+func (m *RemotesModel) Init() tea.Cmd {
+	return m.load
+}
+
+func (m *RemotesModel) load() tea.Msg {
+	output, err := m.context.RunCommandImmediate(jj.GitRemoteList())
+	if err != nil {
+		return updateRemotesMsg{err: err}
+	}
+	bookmarksOutput, _ := m.context.RunCommandImmediate(jj.BookmarkListAll())
+	bookmarks := jj.ParseBookmarkListOutput(string(bookmarksOutput))
+	return updateRemotesMsg{remotes: jj.ParseRemotes(string(output), bookmarks)}
+}
+
+func (m *RemotesModel) Update(msg tea.Msg) tea.Cmd {
+	if m.confirmation != nil {
+		switch msg := msg.(type) {
+		case confirmation.CloseMsg:
+			m.confirmation = nil
+			return nil
+		case tea.KeyMsg, intents.Apply, intents.Cancel:
+			return m.confirmation.Update(msg)
+		}
+	}
+	switch msg := msg.(type) {
+	case updateRemotesMsg:
+		m.loading = false
+		m.err = msg.err
+		m.remotes = msg.remotes
+		m.cursor = min(m.cursor, max(len(m.remotes)-1, 0))
+	case itemClickMsg:
+		if msg.Index >= 0 && msg.Index < len(m.remotes) {
+			m.cursor = msg.Index
+		}
+	case itemScrollMsg:
+		if msg.Horizontal {
+			return nil
+		}
+		m.ensureCursorVisible = false
+		m.listRenderer.StartLine = max(m.listRenderer.StartLine+msg.Delta, 0)
+	case intents.Intent:
+		return m.handleIntent(msg)
+	case tea.KeyMsg, tea.PasteMsg:
+		if m.prompt != remotePromptNone {
+			var cmd tea.Cmd
+			m.promptInput, cmd = m.promptInput.Update(msg)
+			return cmd
+		}
+	}
+	return nil
+}
+
+func (m *RemotesModel) handleIntent(intent intents.Intent) tea.Cmd {
+	switch intent := intent.(type) {
+	case intents.Apply:
+		if m.prompt != remotePromptNone {
+			return m.applyPrompt()
+		}
+	case intents.Cancel:
+		if m.prompt != remotePromptNone {
+			m.closePrompt()
+			return nil
+		}
+		return common.Close
+	case intents.GitNavigate:
+		if len(m.remotes) == 0 {
+			return nil
+		}
+		m.cursor = min(max(m.cursor+intent.Delta, 0), len(m.remotes)-1)
+		m.ensureCursorVisible = true
+	case intents.GitRemoteAction:
+		return m.runAction(intent.Kind)
+	}
+	return nil
+}
+
+func (m *RemotesModel) current() (jj.Remote, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.remotes) {
+		return jj.Remote{}, false
+	}
+	return m.remotes[m.cursor], true
+}
+
+func (m *RemotesModel) runAction(kind intents.GitRemoteActionKind) tea.Cmd {
+	if kind == intents.GitRemoteAdd {
+		return m.openPrompt(remotePromptAddName, "Name: ", "")
+	}
+	remote, ok := m.current()
+	if !ok {
+		return nil
+	}
+	m.promptTarget = remote.Name
+	switch kind {
+	case intents.GitRemoteRename:
+		return m.openPrompt(remotePromptRename, "New name: ", remote.Name)
+	case intents.GitRemoteSetUrl:
+		return m.openPrompt(remotePromptSetUrl, "URL: ", remote.URL)
+	case intents.GitRemoteRemove:
+		message := fmt.Sprintf("Remove remote %s?", remote.Name)
+		if remote.TrackedBookmarks > 0 {
+			message = fmt.Sprintf("Remove remote %s and forget its %d tracked bookmarks?", remote.Name, remote.TrackedBookmarks)
+		}
+		m.confirmation = confirmation.New(
+			[]string{message},
+			confirmation.WithStylePrefix("git"),
+			confirmation.WithZIndex(render.ZMenuDialog),
+			confirmation.WithOption("Yes", tea.Batch(m.run(jj.GitRemoteRemove(remote.Name)), confirmation.Close), key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "yes"))),
+			confirmation.WithOption("No", confirmation.Close, key.NewBinding(key.WithKeys("n", "esc"), key.WithHelp("n/esc", "no"))),
+		)
+		return m.confirmation.Init()
+	}
+	return nil
+}
+
+// run executes a remote command and reloads the list once it completes.
+func (m *RemotesModel) run(args jj.CommandArgs) tea.Cmd {
+	return m.context.RunCommand(args, m.load, common.Refresh)
+}
+
+func (m *RemotesModel) openPrompt(kind remotePromptKind, prompt string, value string) tea.Cmd {
+	m.prompt = kind
+	m.promptInput.Prompt = prompt
+	m.promptInput.SetValue(value)
+	m.promptInput.Focus()
+	m.promptInput.CursorEnd()
+	return textinput.Blink
+}
+
+func (m *RemotesModel) closePrompt() {
+	m.prompt = remotePromptNone
+	m.promptTarget = ""
+	m.promptInput.SetValue("")
+	m.promptInput.Blur()
+}
+
+func (m *RemotesModel) applyPrompt() tea.Cmd {
+	value := strings.TrimSpace(m.promptInput.Value())
+	kind, target := m.prompt, m.promptTarget
+	m.closePrompt()
+	if value == "" {
+		return nil
+	}
+
+	switch kind {
+	case remotePromptAddName:
+		m.promptTarget = value
+		return m.openPrompt(remotePromptAddUrl, "URL: ", "")
+	case remotePromptAddUrl:
+		return m.run(jj.GitRemoteAdd(target, value))
+	case remotePromptRename:
+		if value == target {
+			return nil
+		}
+		return m.run(jj.GitRemoteRename(target, value))
+	case remotePromptSetUrl:
+		return m.run(jj.GitRemoteSetUrl(target, value))
+	}
+	return nil
+}
+
+func (m *RemotesModel) ViewRect(dl *render.DisplayContext, box layout.Box) {
+	pw, ph := box.R.Dx(), box.R.Dy()
+	contentWidth := max(min(pw, 80)-4, 0)
+	contentHeight := max(min(ph, 30)-4, 0)
+	frame := box.Center(contentWidth+2, contentHeight+2)
+	if frame.R.Dx() <= 0 || frame.R.Dy() <= 0 {
+		return
+	}
+
+	dl.AddBackdrop(box.R, render.ZMenuBorder-1)
+	contentBox := frame.Inset(1)
+	if contentBox.R.Dx() <= 0 || contentBox.R.Dy() <= 0 {
+		return
+	}
+	dl.AddFill(contentBox.R, ' ', m.menuStyles.text, render.ZMenuContent)
+
+	borderBase := lipgloss.NewStyle().Width(contentBox.R.Dx()).Height(contentBox.R.Dy()).Render("")
+	dl.AddDraw(frame.R, m.menuStyles.border.Render(borderBase), render.ZMenuBorder)
+
+	titleBox, contentBox := contentBox.CutTop(1)
+	dl.Text(titleBox.R.Min.X, titleBox.R.Min.Y, render.ZMenuContent).
+		Styled("Git Remotes", m.menuStyles.title).
+		Done()
+
+	_, contentBox = contentBox.CutTop(1)
+	statusBox, contentBox := contentBox.CutTop(1)
+	if m.prompt != remotePromptNone {
+		m.promptInput.SetWidth(max(statusBox.R.Dx()-2, 0))
+		dl.AddDraw(statusBox.R, m.promptInput.View(), render.ZMenuContent)
+	} else {
+		dl.Text(statusBox.R.Min.X, statusBox.R.Min.Y, render.ZMenuContent).
+			Styled(" "+m.summary(), m.menuStyles.dimmed).
+			Done()
+	}
+
+	_, listBox := contentBox.CutTop(1)
+	m.renderList(dl, listBox)
+
+	if m.confirmation != nil {
+		w, h := lipgloss.Size(m.confirmation.View())
+		m.confirmation.ViewRect(dl, box.Center(w, h))
+	}
+}
+
+func (m *RemotesModel) summary() string {
+	switch {
+	case m.loading:
+		return "Loading remotes…"
+	case m.err != nil:
+		return m.err.Error()
+	case len(m.remotes) == 0:
+		return "No remotes configured"
+	}
+	return fmt.Sprintf("%d remotes", len(m.remotes))
+}
+
+func (m *RemotesModel) renderList(dl *render.DisplayContext, listBox layout.Box) {
+	itemCount := len(m.remotes)
+	if listBox.R.Dx() <= 0 || listBox.R.Dy() <= 0 || itemCount == 0 {
+		return
+	}
+	width := listBox.R.Dx()
+	const itemHeight = 2
+	m.listRenderer.StartLine = render.ClampStartLine(m.listRenderer.StartLine, listBox.R.Dy(), itemCount*itemHeight)
+	m.listRenderer.Render(
+		dl,
+		listBox,
+		itemCount,
+		m.cursor,
+		m.ensureCursorVisible,
+		func(_ int) int { return itemHeight },
+		func(dl *render.DisplayContext, index int, rect layout.Rectangle) {
+			remote := m.remotes[index]
+			titleStyle, urlStyle := m.menuStyles.text, m.menuStyles.dimmed
+			if index == m.cursor {
+				titleStyle, urlStyle = m.menuStyles.selected, m.menuStyles.selected
+			}
+			tracked := fmt.Sprintf("%d tracked ", remote.TrackedBookmarks)
+			nameWidth := max(width-lipgloss.Width(tracked), 0)
+			title := lipgloss.JoinHorizontal(lipgloss.Top,
+				titleStyle.PaddingLeft(1).Width(nameWidth).Render(remote.Name),
+				urlStyle.Render(tracked))
+			url := urlStyle.PaddingLeft(3).Width(width).Render(remote.URL)
+			dl.AddDraw(rect, lipgloss.JoinVertical(lipgloss.Left, title, url), render.ZMenuContent)
+		},
+		func(index int, _ tea.Mouse) tea.Msg { return itemClickMsg{Index: index} },
+	)
+	m.listRenderer.RegisterScroll(dl, listBox)
+	m.ensureCursorVisible = false
+}
@@ -0,0 +1,91 @@
+package git
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const remoteListOutput = "origin git@github.com:me/repo.git\nupstream https://github.com/them/repo.git"
+
+const remoteBookmarksOutput = `main;.;false;false;false;1a
+main;origin;true;false;false;1a
+main;upstream;true;false;false;1a
+feature;.;false;false;false;2b
+feature;origin;true;false;false;2b`
+
+func newTestRemotesModel(commandRunner *test.CommandRunner) *RemotesModel {
+	model := NewRemotesModel(test.NewTestContext(commandRunner))
+	test.SimulateModel(model, model.Init())
+	return model
+}
+
+func Test_Remotes_ShowsUrlAndTrackedCount(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.GitRemoteList()).SetOutput([]byte(remoteListOutput))
+	commandRunner.Expect(jj.BookmarkListAll()).SetOutput([]byte(remoteBookmarksOutput))
+	defer commandRunner.Verify()
+
+	model := newTestRemotesModel(commandRunner)
+	rendered := test.RenderImmediate(model, 100, 30)
+	assert.Contains(t, rendered, "git@github.com:me/repo.git")
+	assert.Contains(t, rendered, "https://github.com/them/repo.git")
+	assert.Contains(t, rendered, "2 tracked")
+	assert.Contains(t, rendered, "1 tracked")
+}
+
+func Test_Remotes_Add(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.GitRemoteList()).SetOutput([]byte(remoteListOutput))
+	commandRunner.Expect(jj.BookmarkListAll()).SetOutput([]byte(remoteBookmarksOutput))
+	commandRunner.Expect(jj.GitRemoteAdd("fork", "git@github.com:me/fork.git"))
+	defer commandRunner.Verify()
+
+	model := newTestRemotesModel(commandRunner)
+	test.SimulateModel(model, intents.Invoke(intents.GitRemoteAction{Kind: intents.GitRemoteAdd}))
+	assert.True(t, model.IsEditing())
+	test.SimulateModel(model, func() tea.Msg { return tea.PasteMsg{Content: "fork"} })
+	test.SimulateModel(model, intents.Invoke(intents.Apply{}))
+	test.SimulateModel(model, func() tea.Msg { return tea.PasteMsg{Content: "git@github.com:me/fork.git"} })
+	test.SimulateModel(model, intents.Invoke(intents.Apply{}))
+	assert.False(t, model.IsEditing())
+}
+
+func Test_Remotes_RenameAndSetUrl(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.GitRemoteList()).SetOutput([]byte(remoteListOutput))
+	commandRunner.Expect(jj.BookmarkListAll()).SetOutput([]byte(remoteBookmarksOutput))
+	commandRunner.Expect(jj.GitRemoteRename("upstream", "upstream2"))
+	commandRunner.Expect(jj.GitRemoteSetUrl("upstream", "https://github.com/them/repo.git/x"))
+	defer commandRunner.Verify()
+
+	model := newTestRemotesModel(commandRunner)
+	test.SimulateModel(model, intents.Invoke(intents.GitNavigate{Delta: 1}))
+	test.SimulateModel(model, intents.Invoke(intents.GitRemoteAction{Kind: intents.GitRemoteRename}))
+	test.SimulateModel(model, func() tea.Msg { return tea.PasteMsg{Content: "2"} })
+	test.SimulateModel(model, intents.Invoke(intents.Apply{}))
+
+	test.SimulateModel(model, intents.Invoke(intents.GitRemoteAction{Kind: intents.GitRemoteSetUrl}))
+	test.SimulateModel(model, func() tea.Msg { return tea.PasteMsg{Content: "/x"} })
+	test.SimulateModel(model, intents.Invoke(intents.Apply{}))
+}
+
+func Test_Remotes_RemoveAsksForConfirmation(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.GitRemoteList()).SetOutput([]byte(remoteListOutput))
+	commandRunner.Expect(jj.BookmarkListAll()).SetOutput([]byte(remoteBookmarksOutput))
+	commandRunner.Expect(jj.GitRemoteRemove("origin"))
+	defer commandRunner.Verify()
+
+	model := newTestRemotesModel(commandRunner)
+	test.SimulateModel(model, intents.Invoke(intents.GitRemoteAction{Kind: intents.GitRemoteRemove}))
+	assert.NotNil(t, model.confirmation)
+	assert.Contains(t, test.RenderImmediate(model, 100, 30), "forget its 2 tracked bookmarks")
+
+	test.SimulateModel(model, test.Press('y'))
+	assert.Nil(t, model.confirmation)
+}
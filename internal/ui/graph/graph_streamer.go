@@ -26,10 +26,11 @@ type GraphStreamer struct {
 }
 
 // NewGraphStreamer runs `jj log` command with given revset and jjTemplate and
+// passes the started command to started, if given, before waiting for its output.
 // Returns:
 // - Streamer: If stdout is successfully opened.
 // - Error: Returns the stderr output (warnings are also written to stderr).
-func NewGraphStreamer(parentCtx context.Context, runner appContext.CommandRunner, revset string, jjTemplate string) (*GraphStreamer, error) {
+func NewGraphStreamer(parentCtx context.Context, runner appContext.CommandRunner, revset string, jjTemplate string, started func(*appContext.StreamingCommand)) (*GraphStreamer, error) {
 	ctx, cancel := context.WithCancel(parentCtx)
 
 	command, err := runner.RunCommandStreaming(ctx, jj.Log(revset, config.Current.Limit, jjTemplate))
@@ -37,6 +38,9 @@ func NewGraphStreamer(parentCtx context.Context, runner appContext.CommandRunner
 		cancel()
 		return nil, err
 	}
+	if started != nil {
+		started(command)
+	}
 
 	var stderrBuf bytes.Buffer
 	var stderrMu sync.Mutex
@@ -67,7 +71,7 @@ func NewGraphStreamer(parentCtx context.Context, runner appContext.CommandRunner
 	// Non-zero exit or empty stdout
 	if peekErr != nil {
 		// If we can't read stdout, the command likely failed. We wait for it to exit and gather stderr.
-		_ = command.Wait()
+		waitErr := command.Wait()
 
 		stderrMu.Lock()
 		fullStderr := stderrBuf.String()
@@ -76,6 +80,9 @@ func NewGraphStreamer(parentCtx context.Context, runner appContext.CommandRunner
 		cancel()
 
 		if fullStderr == "" {
+			if waitErr != nil {
+				return nil, waitErr // e.g. canceled from the UI
+			}
 			return nil, peekErr // Fallback if no stderr msg but pipe closed
 		}
 		return nil, errors.New(fullStderr)
@@ -42,15 +42,24 @@ var scopeDisplayNames = map[string]string{
 	"revisions.quick_search.input":   "Quick Search Input",
 	"bookmarks":                      "Bookmarks",
 	"bookmarks.filter":               "Bookmarks Filter",
+	"bookmarks.cleanup":              "Bookmark Cleanup",
 	"git":                            "Git",
 	"git.filter":                     "Git Filter",
+	"git.remotes":                    "Git Remotes",
+	"git.remotes.filter":             "Git Remotes Input",
 	"oplog":                          "Operation Log",
 	"oplog.quick_search":             "Operation Log Search",
 	"diff":                           "Diff Viewer",
+	"pager":                          "Pager",
+	"pager.filter":                   "Pager Search",
 	"undo":                           "Undo",
 	"redo":                           "Redo",
 	"revset":                         "Revset Editor",
 	"command_history":                "Command History",
+	"notifications":                  "Notifications",
+	"plugins":                        "Plugins",
+	"console":                        "Lua Console",
+	"terminal":                       "Terminal",
 	"file_search":                    "File Search",
 	"status.input":                   "Status Input",
 	"input":                          "Input",
@@ -79,13 +88,22 @@ var scopeOrder = []string{
 	"revisions.quick_search.input",
 	"bookmarks",
 	"bookmarks.filter",
+	"bookmarks.cleanup",
 	"git",
 	"git.filter",
+	"git.remotes",
+	"git.remotes.filter",
 	"oplog",
 	"oplog.quick_search",
 	"diff",
+	"pager",
+	"pager.filter",
 	"file_search",
 	"command_history",
+	"notifications",
+	"plugins",
+	"console",
+	"terminal",
 	"undo",
 	"redo",
 	"revset",
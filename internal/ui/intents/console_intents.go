@@ -0,0 +1,33 @@
+package intents
+
+//jjui:bind scope=ui action=open_console
+type OpenConsole struct{}
+
+func (OpenConsole) isIntent() {}
+
+//jjui:bind scope=console action=submit
+type ConsoleSubmit struct{}
+
+func (ConsoleSubmit) isIntent() {}
+
+//jjui:bind scope=console action=history_prev set=Delta:-1
+//jjui:bind scope=console action=history_next set=Delta:1
+type ConsoleHistory struct{ Delta int }
+
+func (ConsoleHistory) isIntent() {}
+
+//jjui:bind scope=console action=page_up set=Delta:-1
+//jjui:bind scope=console action=page_down set=Delta:1
+type ConsoleScroll struct{ Delta int }
+
+func (ConsoleScroll) isIntent() {}
+
+//jjui:bind scope=console action=clear
+type ConsoleClear struct{}
+
+func (ConsoleClear) isIntent() {}
+
+//jjui:bind scope=console action=close
+type ConsoleClose struct{}
+
+func (ConsoleClose) isIntent() {}
@@ -21,6 +21,14 @@ const (
 //jjui:bind scope=diff action=half_page_down set=Kind:DiffHalfPageDown
 //jjui:bind scope=diff action=move_top set=Kind:DiffMoveTop
 //jjui:bind scope=diff action=move_bottom set=Kind:DiffMoveBottom
+//jjui:bind scope=pager action=scroll_up set=Kind:DiffScrollUp
+//jjui:bind scope=pager action=scroll_down set=Kind:DiffScrollDown
+//jjui:bind scope=pager action=page_up set=Kind:DiffPageUp
+//jjui:bind scope=pager action=page_down set=Kind:DiffPageDown
+//jjui:bind scope=pager action=half_page_up set=Kind:DiffHalfPageUp
+//jjui:bind scope=pager action=half_page_down set=Kind:DiffHalfPageDown
+//jjui:bind scope=pager action=move_top set=Kind:DiffMoveTop
+//jjui:bind scope=pager action=move_bottom set=Kind:DiffMoveBottom
 type DiffScroll struct {
 	Kind DiffScrollKind
 }
@@ -36,6 +44,8 @@ const (
 
 //jjui:bind scope=diff action=left set=Kind:DiffScrollLeft
 //jjui:bind scope=diff action=right set=Kind:DiffScrollRight
+//jjui:bind scope=pager action=left set=Kind:DiffScrollLeft
+//jjui:bind scope=pager action=right set=Kind:DiffScrollRight
 type DiffScrollHorizontal struct {
 	Kind DiffScrollHorizontalKind
 }
@@ -43,6 +53,7 @@ type DiffScrollHorizontal struct {
 func (DiffScrollHorizontal) isIntent() {}
 
 //jjui:bind scope=diff action=toggle_wrap
+//jjui:bind scope=pager action=toggle_wrap
 type DiffToggleWrap struct{}
 
 func (DiffToggleWrap) isIntent() {}
@@ -53,3 +64,21 @@ type DiffShow struct {
 }
 
 func (DiffShow) isIntent() {}
+
+//jjui:bind scope=pager action=search
+type PagerSearch struct{}
+
+func (PagerSearch) isIntent() {}
+
+//jjui:bind scope=pager action=next_match set=Delta:1
+//jjui:bind scope=pager action=prev_match set=Delta:-1
+type PagerNextMatch struct {
+	Delta int
+}
+
+func (PagerNextMatch) isIntent() {}
+
+//jjui:bind scope=pager action=copy
+type PagerCopy struct{}
+
+func (PagerCopy) isIntent() {}
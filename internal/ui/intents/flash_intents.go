@@ -21,6 +21,36 @@ type CommandHistoryDeleteSelected struct{}
 
 func (CommandHistoryDeleteSelected) isIntent() {}
 
+//jjui:bind scope=command_history action=cancel_selected
+type CommandHistoryCancelSelected struct{}
+
+func (CommandHistoryCancelSelected) isIntent() {}
+
+//jjui:bind scope=command_history action=rerun_selected
+type CommandHistoryRerunSelected struct{}
+
+func (CommandHistoryRerunSelected) isIntent() {}
+
+//jjui:bind scope=command_history action=copy_selected
+type CommandHistoryCopySelected struct{}
+
+func (CommandHistoryCopySelected) isIntent() {}
+
+//jjui:bind scope=command_history action=edit_selected
+type CommandHistoryEditSelected struct{}
+
+func (CommandHistoryEditSelected) isIntent() {}
+
+//jjui:bind scope=command_history action=toggle_output
+type CommandHistoryToggleOutput struct{}
+
+func (CommandHistoryToggleOutput) isIntent() {}
+
+//jjui:bind scope=command_history action=cycle_filter
+type CommandHistoryCycleFilter struct{}
+
+func (CommandHistoryCycleFilter) isIntent() {}
+
 type AddMessage struct {
 	Text   string
 	Err    error
@@ -32,3 +62,24 @@ func (AddMessage) isIntent() {}
 type DismissOldest struct{}
 
 func (DismissOldest) isIntent() {}
+
+//jjui:bind scope=ui action=open_notifications
+type OpenNotifications struct{}
+
+func (OpenNotifications) isIntent() {}
+
+//jjui:bind scope=notifications action=move_up set=Delta:-1
+//jjui:bind scope=notifications action=move_down set=Delta:1
+type NotificationsNavigate struct{ Delta int }
+
+func (NotificationsNavigate) isIntent() {}
+
+//jjui:bind scope=notifications action=cycle_filter
+type NotificationsCycleFilter struct{}
+
+func (NotificationsCycleFilter) isIntent() {}
+
+//jjui:bind scope=notifications action=close
+type NotificationsClose struct{}
+
+func (NotificationsClose) isIntent() {}
@@ -0,0 +1,22 @@
+package intents
+
+//jjui:bind scope=ui action=open_plugins
+type OpenPlugins struct{}
+
+func (OpenPlugins) isIntent() {}
+
+//jjui:bind scope=plugins action=move_up set=Delta:-1
+//jjui:bind scope=plugins action=move_down set=Delta:1
+type PluginsNavigate struct{ Delta int }
+
+func (PluginsNavigate) isIntent() {}
+
+//jjui:bind scope=plugins action=toggle
+type PluginsToggle struct{}
+
+func (PluginsToggle) isIntent() {}
+
+//jjui:bind scope=plugins action=close
+type PluginsClose struct{}
+
+func (PluginsClose) isIntent() {}
@@ -22,6 +22,8 @@ func (Reset) isIntent() {}
 
 //jjui:bind scope=revset action=autocomplete
 //jjui:bind scope=revset action=autocomplete_back set=Reverse:true
+//jjui:bind scope=status.input action=complete
+//jjui:bind scope=status.input action=complete_back set=Reverse:true
 type CompletionCycle struct {
 	Reverse bool
 }
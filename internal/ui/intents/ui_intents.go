@@ -11,7 +11,10 @@ type Redo struct{}
 func (Redo) isIntent() {}
 
 //jjui:bind scope=ui action=exec_jj
-type ExecJJ struct{}
+type ExecJJ struct {
+	// Line prefills the prompt.
+	Line string
+}
 
 func (ExecJJ) isIntent() {}
 
@@ -20,6 +23,11 @@ type ExecShell struct{}
 
 func (ExecShell) isIntent() {}
 
+//jjui:bind scope=ui action=toggle_terminal_focus
+type TerminalToggleFocus struct{}
+
+func (TerminalToggleFocus) isIntent() {}
+
 //jjui:bind scope=revisions.evolog action=quit
 //jjui:bind scope=revisions.details action=quit
 //jjui:bind scope=ui action=quit
@@ -72,11 +80,36 @@ type OpenBookmarks struct{}
 
 func (OpenBookmarks) isIntent() {}
 
+//jjui:bind scope=ui action=open_bookmark_cleanup
+type OpenBookmarkCleanup struct{}
+
+func (OpenBookmarkCleanup) isIntent() {}
+
 //jjui:bind scope=ui action=open_git
 type OpenGit struct{}
 
 func (OpenGit) isIntent() {}
 
+//jjui:bind scope=ui action=open_git_remotes
+type OpenGitRemotes struct{}
+
+func (OpenGitRemotes) isIntent() {}
+
+//jjui:bind scope=ui action=rebase_onto_trunk
+type RebaseOntoTrunk struct{}
+
+func (RebaseOntoTrunk) isIntent() {}
+
+//jjui:bind scope=ui action=cancel_command
+type CancelCommand struct{}
+
+func (CancelCommand) isIntent() {}
+
+//jjui:bind scope=ui action=recover_command
+type RecoverCommand struct{}
+
+func (RecoverCommand) isIntent() {}
+
 //jjui:bind scope=revisions action=open_set_bookmark
 type OpenSetBookmark struct{}
 
@@ -120,6 +153,8 @@ func (BookmarksOpenFilter) isIntent() {}
 //jjui:bind scope=bookmarks action=move_down set=Delta:1
 //jjui:bind scope=bookmarks action=page_up set=Delta:-1,IsPage:true
 //jjui:bind scope=bookmarks action=page_down set=Delta:1,IsPage:true
+//jjui:bind scope=bookmarks.cleanup action=move_up set=Delta:-1
+//jjui:bind scope=bookmarks.cleanup action=move_down set=Delta:1
 type BookmarksNavigate struct {
 	Delta  int
 	IsPage bool
@@ -127,6 +162,63 @@ type BookmarksNavigate struct {
 
 func (BookmarksNavigate) isIntent() {}
 
+type BookmarksSyncFilterKind string
+
+const (
+	BookmarksSyncNeedsPush BookmarksSyncFilterKind = "needs push"
+	BookmarksSyncNeedsPull BookmarksSyncFilterKind = "needs pull"
+	BookmarksSyncDiverged  BookmarksSyncFilterKind = "diverged"
+)
+
+//jjui:bind scope=bookmarks action=filter_needs_push set=Kind:BookmarksSyncNeedsPush
+//jjui:bind scope=bookmarks action=filter_needs_pull set=Kind:BookmarksSyncNeedsPull
+//jjui:bind scope=bookmarks action=filter_diverged set=Kind:BookmarksSyncDiverged
+type BookmarksSyncFilter struct {
+	Kind BookmarksSyncFilterKind
+}
+
+func (BookmarksSyncFilter) isIntent() {}
+
+//jjui:bind scope=bookmarks action=sort_by_sync
+type BookmarksToggleSyncSort struct{}
+
+func (BookmarksToggleSyncSort) isIntent() {}
+
+//jjui:bind scope=bookmarks action=toggle_select
+//jjui:bind scope=bookmarks.cleanup action=toggle_select
+type BookmarksToggleSelect struct{}
+
+func (BookmarksToggleSelect) isIntent() {}
+
+//jjui:bind scope=bookmarks.cleanup action=select_all
+type BookmarksSelectAll struct{}
+
+func (BookmarksSelectAll) isIntent() {}
+
+//jjui:bind scope=bookmarks.cleanup action=delete set=Kind:BookmarksFilterDelete
+//jjui:bind scope=bookmarks.cleanup action=forget set=Kind:BookmarksFilterForget
+//jjui:bind scope=bookmarks.cleanup action=untrack set=Kind:BookmarksFilterUntrack
+type BookmarksCleanup struct {
+	Kind BookmarksFilterKind
+}
+
+func (BookmarksCleanup) isIntent() {}
+
+//jjui:bind scope=bookmarks action=rename
+type BookmarksRename struct{}
+
+func (BookmarksRename) isIntent() {}
+
+//jjui:bind scope=bookmarks action=create
+type BookmarksCreate struct{}
+
+func (BookmarksCreate) isIntent() {}
+
+//jjui:bind scope=bookmarks action=track_glob
+type BookmarksTrackGlob struct{}
+
+func (BookmarksTrackGlob) isIntent() {}
+
 type BookmarksApplyShortcut struct {
 	Key string
 }
@@ -165,6 +257,8 @@ func (GitOpenFilter) isIntent() {}
 //jjui:bind scope=git action=move_down set=Delta:1
 //jjui:bind scope=git action=page_up set=Delta:-1,IsPage:true
 //jjui:bind scope=git action=page_down set=Delta:1,IsPage:true
+//jjui:bind scope=git.remotes action=move_up set=Delta:-1
+//jjui:bind scope=git.remotes action=move_down set=Delta:1
 type GitNavigate struct {
 	Delta  int
 	IsPage bool
@@ -178,6 +272,25 @@ type GitApplyShortcut struct {
 
 func (GitApplyShortcut) isIntent() {}
 
+type GitRemoteActionKind string
+
+const (
+	GitRemoteAdd    GitRemoteActionKind = "add"
+	GitRemoteRemove GitRemoteActionKind = "remove"
+	GitRemoteRename GitRemoteActionKind = "rename"
+	GitRemoteSetUrl GitRemoteActionKind = "set url"
+)
+
+//jjui:bind scope=git.remotes action=add set=Kind:GitRemoteAdd
+//jjui:bind scope=git.remotes action=remove set=Kind:GitRemoteRemove
+//jjui:bind scope=git.remotes action=rename set=Kind:GitRemoteRename
+//jjui:bind scope=git.remotes action=set_url set=Kind:GitRemoteSetUrl
+type GitRemoteAction struct {
+	Kind GitRemoteActionKind
+}
+
+func (GitRemoteAction) isIntent() {}
+
 //jjui:bind scope=choose action=move_up set=Delta:-1
 //jjui:bind scope=choose action=move_down set=Delta:1
 type ChooseNavigate struct {
@@ -210,7 +323,9 @@ func (ChooseCancel) isIntent() {}
 //jjui:bind scope=ui action=cancel
 //jjui:bind scope=help action=cancel
 //jjui:bind scope=bookmarks action=cancel
+//jjui:bind scope=bookmarks.cleanup action=cancel
 //jjui:bind scope=git action=cancel
+//jjui:bind scope=git.remotes action=cancel
 //jjui:bind scope=status.input action=cancel
 //jjui:bind scope=file_search action=cancel
 //jjui:bind scope=revisions.quick_search.input action=cancel
@@ -219,6 +334,7 @@ func (ChooseCancel) isIntent() {}
 //jjui:bind scope=input action=cancel
 //jjui:bind scope=undo action=cancel
 //jjui:bind scope=redo action=cancel
+//jjui:bind scope=pager action=cancel
 type Cancel struct{}
 
 func (Cancel) isIntent() {}
@@ -241,6 +357,8 @@ func (Cancel) isIntent() {}
 //jjui:bind scope=revisions.ace_jump action=apply
 //jjui:bind scope=bookmarks action=apply
 //jjui:bind scope=git action=apply
+//jjui:bind scope=git.remotes action=apply
+//jjui:bind scope=pager action=apply
 //jjui:bind scope=revisions action=apply set=Force:$bool(force)
 //jjui:bind scope=revisions action=force_apply set=Force:true
 //jjui:bind scope=status.input action=apply
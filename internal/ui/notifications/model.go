@@ -0,0 +1,253 @@
+package notifications
+
+import (
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/idursun/jjui/internal/ui/actions"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/flash"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/internal/ui/layout"
+	"github.com/idursun/jjui/internal/ui/render"
+)
+
+var _ common.StackedModel = (*Model)(nil)
+
+const markWidth = 3
+
+// detailLines is how many lines of the selected notification are shown
+// below the list.
+const detailLines = 8
+
+type Source interface {
+	Notifications() []flash.Notification
+}
+
+type selectNotificationMsg struct {
+	index int
+}
+
+type severityFilter int
+
+const (
+	filterAll severityFilter = iota
+	filterError
+	filterSuccess
+	filterInfo
+)
+
+func (f severityFilter) String() string {
+	switch f {
+	case filterError:
+		return "error"
+	case filterSuccess:
+		return "success"
+	case filterInfo:
+		return "info"
+	}
+	return "all"
+}
+
+func (f severityFilter) matches(notification flash.Notification) bool {
+	switch f {
+	case filterError:
+		return notification.Severity == flash.SeverityError
+	case filterSuccess:
+		return notification.Severity == flash.SeveritySuccess
+	case filterInfo:
+		return notification.Severity == flash.SeverityInfo
+	}
+	return true
+}
+
+// Model lists every flash message of the session, newest first.
+type Model struct {
+	source        Source
+	items         []flash.Notification
+	filter        severityFilter
+	selectedIndex int
+	windowStart   int
+	successStyle  lipgloss.Style
+	errorStyle    lipgloss.Style
+	textStyle     lipgloss.Style
+	matchedStyle  lipgloss.Style
+	dimmedStyle   lipgloss.Style
+}
+
+func New(source Source) *Model {
+	m := &Model{
+		source:       source,
+		successStyle: common.DefaultPalette.Get("flash success"),
+		errorStyle:   common.DefaultPalette.Get("flash error"),
+		textStyle:    common.DefaultPalette.Get("flash text"),
+		matchedStyle: common.DefaultPalette.Get("flash matched"),
+		dimmedStyle:  common.DefaultPalette.Get("flash dimmed"),
+	}
+	m.reload()
+	return m
+}
+
+// reload fetches the notifications again, newest first.
+func (m *Model) reload() {
+	m.items = m.items[:0]
+	if m.source == nil {
+		return
+	}
+	all := m.source.Notifications()
+	for i := len(all) - 1; i >= 0; i-- {
+		if m.filter.matches(all[i]) {
+			m.items = append(m.items, all[i])
+		}
+	}
+	m.selectedIndex = min(m.selectedIndex, max(0, len(m.items)-1))
+}
+
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Model) StackedActionOwner() string {
+	return actions.OwnerNotifications
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case intents.Intent:
+		switch intent := msg.(type) {
+		case intents.NotificationsNavigate:
+			if len(m.items) == 0 {
+				return nil
+			}
+			m.selectedIndex = min(len(m.items)-1, max(0, m.selectedIndex+intent.Delta))
+			return nil
+		case intents.NotificationsCycleFilter:
+			m.filter = (m.filter + 1) % 4
+			m.selectedIndex = 0
+			m.windowStart = 0
+			m.reload()
+			return nil
+		case intents.NotificationsClose:
+			return common.Close
+		}
+	case selectNotificationMsg:
+		if msg.index >= 0 && msg.index < len(m.items) {
+			m.selectedIndex = msg.index
+		}
+		return nil
+	case common.CloseViewMsg:
+		return common.Close
+	}
+	return nil
+}
+
+func (m *Model) ViewRect(dl *render.DisplayContext, box layout.Box) {
+	m.reload()
+	rest, _ := box.CutBottom(1)
+	dl.AddDim(rest.R, render.ZOverlay)
+
+	area := rest.R
+	width := max(0, area.Dx()-4)
+	// the border, the header and the separator take four lines
+	height := max(1, area.Dy()-4)
+
+	header := m.textStyle.Render("Notifications") + m.dimmedStyle.Render("  showing ") + m.matchedStyle.Render(m.filter.String())
+	var lines []string
+	if len(m.items) == 0 {
+		lines = append(lines, m.dimmedStyle.Render("no notifications"))
+	} else {
+		detail := m.renderDetail(m.items[m.selectedIndex], width)
+		listHeight := max(1, height-len(detail))
+		m.clampWindow(listHeight)
+		end := min(len(m.items), m.windowStart+listHeight)
+		for i := m.windowStart; i < end; i++ {
+			lines = append(lines, m.renderRow(m.items[i], width, i == m.selectedIndex))
+			rowRect := layout.Rect(area.Min.X+2, area.Min.Y+2+i-m.windowStart, width, 1)
+			dl.AddInteraction(rowRect, selectNotificationMsg{index: i}, render.InteractionClick, render.ZOverlay)
+		}
+		for range listHeight - (end - m.windowStart) {
+			lines = append(lines, "")
+		}
+		lines = append(lines, m.dimmedStyle.Render(strings.Repeat("─", width)))
+		lines = append(lines, detail...)
+	}
+
+	content := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(m.textStyle.GetForeground()).
+		PaddingLeft(1).
+		PaddingRight(1).
+		Width(area.Dx()).
+		Height(area.Dy()).
+		Render(header + "\n" + strings.Join(lines, "\n"))
+	dl.AddDraw(area, content, render.ZOverlay)
+}
+
+func (m *Model) clampWindow(height int) {
+	if m.selectedIndex < m.windowStart {
+		m.windowStart = m.selectedIndex
+	}
+	if m.selectedIndex >= m.windowStart+height {
+		m.windowStart = m.selectedIndex - height + 1
+	}
+	m.windowStart = max(0, min(m.windowStart, len(m.items)-height))
+}
+
+func (m *Model) severityStyle(severity flash.Severity) lipgloss.Style {
+	switch severity {
+	case flash.SeverityError:
+		return m.errorStyle
+	case flash.SeveritySuccess:
+		return m.successStyle
+	}
+	return m.textStyle
+}
+
+func (m *Model) renderMark(severity flash.Severity) string {
+	style := m.severityStyle(severity).Width(markWidth)
+	switch severity {
+	case flash.SeverityError:
+		return style.Render("✗ ")
+	case flash.SeveritySuccess:
+		return style.Render("✓ ")
+	}
+	return style.Render("• ")
+}
+
+// renderRow renders the time, the severity, the command and the first line
+// of the text of a notification on a single line.
+func (m *Model) renderRow(notification flash.Notification, width int, selected bool) string {
+	summary, _, _ := strings.Cut(notification.Text, "\n")
+	var b strings.Builder
+	if selected {
+		b.WriteString(m.matchedStyle.Render("› "))
+	} else {
+		b.WriteString("  ")
+	}
+	b.WriteString(m.dimmedStyle.Render(notification.Time.Format("15:04:05") + " "))
+	b.WriteString(m.renderMark(notification.Severity))
+	if notification.Command != "" {
+		b.WriteString(flash.ColorizeCommand(notification.Command, m.textStyle, m.matchedStyle))
+		if summary != "" {
+			b.WriteString(m.dimmedStyle.Render(" · "))
+		}
+	}
+	b.WriteString(m.severityStyle(notification.Severity).Render(summary))
+	return lipgloss.NewStyle().MaxWidth(width).Render(b.String())
+}
+
+func (m *Model) renderDetail(notification flash.Notification, width int) []string {
+	parts := []string{m.dimmedStyle.Render(notification.Time.Format("2006-01-02 15:04:05") + "  " + notification.Severity.String())}
+	if notification.Command != "" {
+		parts = append(parts, flash.ColorizeCommand(notification.Command, m.textStyle, m.matchedStyle))
+	}
+	if notification.Text != "" {
+		parts = append(parts, m.severityStyle(notification.Severity).Render(notification.Text))
+	}
+	lines := strings.Split(lipgloss.NewStyle().Width(width).Render(strings.Join(parts, "\n")), "\n")
+	if len(lines) > detailLines {
+		lines = lines[:detailLines]
+	}
+	return lines
+}
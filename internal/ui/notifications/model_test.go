@@ -0,0 +1,84 @@
+package notifications
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/flash"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSource(t *testing.T) *flash.Model {
+	source := flash.New(test.NewTestContext(test.NewTestCommandRunner(t)))
+	source.Update(intents.AddMessage{Text: "Copied to clipboard"})
+	source.AddWithCommand("Working copy now at: abc", "jj new", nil)
+	source.AddWithCommand("", "jj git push", errors.New("Error: refusing to push"))
+	return source
+}
+
+func TestNotifications_ListsNewestFirst(t *testing.T) {
+	m := New(newSource(t))
+
+	if assert.Len(t, m.items, 3) {
+		assert.Equal(t, "jj git push", m.items[0].Command)
+		assert.Equal(t, "Copied to clipboard", m.items[2].Text)
+	}
+
+	rendered := test.RenderImmediate(m, 100, 30)
+	assert.Contains(t, rendered, "Notifications")
+	assert.Contains(t, rendered, "jj git push")
+	assert.Contains(t, rendered, "Working copy now at: abc")
+	assert.Contains(t, rendered, "Copied to clipboard")
+}
+
+func TestNotifications_CycleFilter(t *testing.T) {
+	m := New(newSource(t))
+
+	m.Update(intents.NotificationsCycleFilter{})
+	assert.Equal(t, filterError, m.filter)
+	if assert.Len(t, m.items, 1) {
+		assert.Equal(t, flash.SeverityError, m.items[0].Severity)
+	}
+
+	m.Update(intents.NotificationsCycleFilter{})
+	if assert.Len(t, m.items, 1) {
+		assert.Equal(t, "jj new", m.items[0].Command)
+	}
+
+	m.Update(intents.NotificationsCycleFilter{})
+	if assert.Len(t, m.items, 1) {
+		assert.Equal(t, "Copied to clipboard", m.items[0].Text)
+	}
+
+	m.Update(intents.NotificationsCycleFilter{})
+	assert.Equal(t, filterAll, m.filter)
+	assert.Len(t, m.items, 3)
+}
+
+func TestNotifications_NavigateShowsSelectedDetails(t *testing.T) {
+	m := New(newSource(t))
+
+	m.Update(intents.NotificationsNavigate{Delta: 1})
+	m.Update(intents.NotificationsNavigate{Delta: 5})
+	assert.Equal(t, 2, m.selectedIndex)
+	m.Update(intents.NotificationsNavigate{Delta: -1})
+	assert.Equal(t, 1, m.selectedIndex)
+
+	rendered := test.RenderImmediate(m, 100, 30)
+	assert.Contains(t, rendered, "success")
+}
+
+func TestNotifications_Close(t *testing.T) {
+	m := New(nil)
+
+	assert.Empty(t, m.items)
+	assert.Contains(t, test.RenderImmediate(m, 80, 20), "no notifications")
+
+	cmd := m.Update(intents.NotificationsClose{})
+	if assert.NotNil(t, cmd) {
+		assert.IsType(t, common.CloseViewMsg{}, cmd())
+	}
+}
@@ -0,0 +1,69 @@
+package bookmark
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/idursun/jjui/internal/config"
+)
+
+const maxSlugLength = 48
+
+var placeholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// nameFields are the values available to bookmark name templates.
+type nameFields map[string]string
+
+func parseNameFields(output string, jjConfig *config.JJConfig) nameFields {
+	lines := strings.Split(output, "\n")
+	field := func(i int) string {
+		if i < len(lines) {
+			return strings.TrimSpace(lines[i])
+		}
+		return ""
+	}
+	fields := nameFields{
+		"change_id":        field(0),
+		"commit_id":        field(1),
+		"author":           slugify(field(2)),
+		"description_slug": slugify(field(3)),
+	}
+	fields["user"] = fields["author"]
+	if jjConfig != nil {
+		if local, _, ok := strings.Cut(jjConfig.User.Email, "@"); ok && local != "" {
+			fields["user"] = slugify(local)
+		} else if jjConfig.User.Name != "" {
+			fields["user"] = slugify(jjConfig.User.Name)
+		}
+	}
+	return fields
+}
+
+// render replaces {placeholder}s in the template. Unknown placeholders are left as they are.
+func (f nameFields) render(template string) string {
+	return placeholderPattern.ReplaceAllStringFunc(template, func(match string) string {
+		if value, ok := f[match[1:len(match)-1]]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+		if b.Len() >= maxSlugLength {
+			break
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
@@ -0,0 +1,24 @@
+package bookmark
+
+import (
+	"testing"
+
+	"github.com/idursun/jjui/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "fix-the-parser-bug", slugify("Fix: the parser bug!"))
+	assert.Equal(t, "", slugify("  ***  "))
+	assert.LessOrEqual(t, len(slugify("a very long description that keeps going on and on and on forever")), maxSlugLength)
+}
+
+func TestNameFields_Render(t *testing.T) {
+	jjConfig := &config.JJConfig{}
+	jjConfig.User.Email = "jane.doe@example.com"
+	fields := parseNameFields("kxqpmzrn\nab12cd34\nauthor\nAdd bookmark templates\n", jjConfig)
+
+	assert.Equal(t, "jane-doe/add-bookmark-templates", fields.render("{user}/{description_slug}"))
+	assert.Equal(t, "push-kxqpmzrn", fields.render("push-{change_id}"))
+	assert.Equal(t, "ab12cd34-{unknown}", fields.render("{commit_id}-{unknown}"))
+}
@@ -1,12 +1,14 @@
 package bookmark
 
 import (
+	"slices"
 	"strings"
 
 	"charm.land/bubbles/v2/textinput"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/actions"
 	keybindings "github.com/idursun/jjui/internal/ui/bindings"
@@ -27,6 +29,8 @@ type SetBookmarkOperation struct {
 	name            textinput.Model
 	suggestions     []string
 	suggestionIndex int
+	// existing are the bookmarks the revision can move; the naming policy only applies to new ones.
+	existing map[string]bool
 }
 
 func (s *SetBookmarkOperation) IsEditing() bool {
@@ -40,6 +44,11 @@ func (s *SetBookmarkOperation) Update(msg tea.Msg) tea.Cmd {
 		case intents.Cancel:
 			return common.Close
 		case intents.Apply:
+			if !s.existing[s.name.Value()] {
+				if err := config.ValidateBookmarkName(config.Current, s.name.Value()); err != nil {
+					return intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err})
+				}
+			}
 			return s.context.RunCommand(jj.BookmarkSet(s.revision, s.name.Value()), common.CloseApplied, common.Refresh)
 		case intents.AutocompleteCycle:
 			s.cycleSuggestion(msg.Reverse)
@@ -58,17 +67,45 @@ func (s *SetBookmarkOperation) Init() tea.Cmd {
 		bookmarks := jj.ParseBookmarkListOutput(string(output))
 		var suggestions []string
 		for _, b := range bookmarks {
+			s.existing[b.Name] = true
 			if b.Name != "" && !b.Backwards {
 				suggestions = append(suggestions, b.Name)
 			}
 		}
 		s.suggestions = suggestions
-		s.name.SetSuggestions(suggestions)
 	}
 
+	if templated := s.templatedNames(); len(templated) > 0 {
+		s.name.SetValue(templated[0])
+		s.name.CursorEnd()
+		s.suggestions = append(templated, s.suggestions...)
+	}
+	s.name.SetSuggestions(s.suggestions)
+
 	return textinput.Blink
 }
 
+// templatedNames renders the configured bookmark name templates for the revision.
+func (s *SetBookmarkOperation) templatedNames() []string {
+	templates := config.Current.Bookmarks.NameTemplates
+	if len(templates) == 0 {
+		return nil
+	}
+	output, err := s.context.RunCommandImmediate(jj.BookmarkNameFields(s.revision))
+	if err != nil {
+		return nil
+	}
+	fields := parseNameFields(string(output), s.context.JJConfig)
+	var names []string
+	for _, template := range templates {
+		name := strings.ReplaceAll(fields.render(template), " ", "-")
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func (s *SetBookmarkOperation) ViewRect(dl *render.DisplayContext, box layout.Box) {
 	content := s.viewContent()
 	w, h := lipgloss.Size(content)
@@ -119,6 +156,7 @@ func NewSetBookmarkOperation(context *context.MainContext, changeId string) *Set
 		name:     t,
 		revision: changeId,
 		context:  context,
+		existing: make(map[string]bool),
 		// -1 means no active completion cycle.
 		suggestionIndex: -1,
 	}
@@ -4,9 +4,11 @@ import (
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/intents"
 	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestSetBookmarkModel_Update(t *testing.T) {
@@ -20,3 +22,56 @@ func TestSetBookmarkModel_Update(t *testing.T) {
 	test.SimulateModel(op, test.Type("name"))
 	test.SimulateModel(op, func() tea.Msg { return intents.Apply{} })
 }
+
+func TestSetBookmarkModel_PrefillsFromTemplate(t *testing.T) {
+	previous := config.Current.Bookmarks
+	defer func() { config.Current.Bookmarks = previous }()
+	config.Current.Bookmarks.NameTemplates = []string{"push-{change_id}", "{user}/{description_slug}"}
+	config.Current.Bookmarks.NamePattern = `^push-`
+
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.BookmarkListMovable("revision"))
+	commandRunner.Expect(jj.BookmarkNameFields("revision")).SetOutput([]byte("kxqpmzrn\nab12cd34\njane\nAdd templates\n"))
+	commandRunner.Expect(jj.BookmarkSet("revision", "push-kxqpmzrn"))
+	defer commandRunner.Verify()
+
+	op := NewSetBookmarkOperation(test.NewTestContext(commandRunner), "revision")
+	test.SimulateModel(op, op.Init())
+	assert.Equal(t, "push-kxqpmzrn", op.name.Value())
+	assert.Equal(t, []string{"push-kxqpmzrn", "jane/add-templates"}, op.suggestions)
+	test.SimulateModel(op, func() tea.Msg { return intents.Apply{} })
+}
+
+func TestSetBookmarkModel_RejectsNameViolatingPolicy(t *testing.T) {
+	previous := config.Current.Bookmarks.NamePattern
+	defer func() { config.Current.Bookmarks.NamePattern = previous }()
+	config.Current.Bookmarks.NamePattern = `^feat/`
+
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.BookmarkListMovable("revision"))
+	defer commandRunner.Verify()
+
+	op := NewSetBookmarkOperation(test.NewTestContext(commandRunner), "revision")
+	test.SimulateModel(op, op.Init())
+	test.SimulateModel(op, test.Type("name"))
+	cmd := op.Update(intents.Apply{})
+	msg, ok := cmd().(intents.AddMessage)
+	assert.True(t, ok)
+	assert.Error(t, msg.Err)
+}
+
+func TestSetBookmarkModel_MovesExistingBookmarkViolatingPolicy(t *testing.T) {
+	previous := config.Current.Bookmarks.NamePattern
+	defer func() { config.Current.Bookmarks.NamePattern = previous }()
+	config.Current.Bookmarks.NamePattern = `^feat/`
+
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.BookmarkListMovable("revision")).SetOutput([]byte("legacy;.;false;false;false;a\n"))
+	commandRunner.Expect(jj.BookmarkSet("revision", "legacy"))
+	defer commandRunner.Verify()
+
+	op := NewSetBookmarkOperation(test.NewTestContext(commandRunner), "revision")
+	test.SimulateModel(op, op.Init())
+	test.SimulateModel(op, test.Type("legacy"))
+	test.SimulateModel(op, func() tea.Msg { return intents.Apply{} })
+}
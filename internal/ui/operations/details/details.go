@@ -12,6 +12,7 @@ import (
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/actions"
 	keybindings "github.com/idursun/jjui/internal/ui/bindings"
@@ -473,22 +474,20 @@ func (s *Operation) createListItems(content string, selectedFiles []string) []*i
 }
 
 func (s *Operation) load(revision string) tea.Cmd {
-	output, err := s.context.RunCommandImmediate(jj.Snapshot())
-	if err == nil {
-		output, err = s.context.RunCommandImmediate(jj.Status(revision))
+	return func() tea.Msg {
+		// snapshotting writes an operation, so it waits for its turn in the
+		// queue off the UI loop and mustn't be killed like a preview
+		output, err := s.context.RunCommandImmediateWithClass(jj.Snapshot(), config.CommandClassMutation)
 		if err == nil {
-			return func() tea.Msg {
-				summary := string(output)
-				selectedFiles := s.getSelectedFiles(false)
-				return updateCommitStatusMsg{summary, selectedFiles}
-			}
+			output, err = s.context.RunCommandImmediate(jj.Status(revision))
 		}
-	}
-	return func() tea.Msg {
-		return common.CommandCompletedMsg{
-			Output: string(output),
-			Err:    err,
+		if err != nil {
+			return common.CommandCompletedMsg{
+				Output: string(output),
+				Err:    err,
+			}
 		}
+		return updateCommitStatusMsg{string(output), s.getSelectedFiles(false)}
 	}
 }
 
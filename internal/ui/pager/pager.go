@@ -0,0 +1,237 @@
+package pager
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/idursun/jjui/internal/ui/actions"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/diff"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/internal/ui/layout"
+	"github.com/idursun/jjui/internal/ui/render"
+)
+
+var (
+	_ common.StackedModel = (*Model)(nil)
+	_ common.Editable     = (*Model)(nil)
+)
+
+type scrollMsg struct {
+	Delta      int
+	Horizontal bool
+}
+
+func (s scrollMsg) SetDelta(delta int, horizontal bool) tea.Msg {
+	s.Delta = delta
+	s.Horizontal = horizontal
+	return s
+}
+
+// Model shows the output of a command, e.g. one run with `:`, in a panel that
+// can be scrolled, searched and copied.
+type Model struct {
+	title string
+	// lines is the output without colours, which is what is searched and
+	// copied.
+	lines       []string
+	view        *diff.Model
+	searchInput textinput.Model
+	searching   bool
+	query       string
+	// previousQuery is the search before the one being edited.
+	previousQuery string
+	matches       []int
+	current       int
+
+	textStyle     lipgloss.Style
+	dimmedStyle   lipgloss.Style
+	matchedStyle  lipgloss.Style
+	selectedStyle lipgloss.Style
+}
+
+func New(title string, content string) *Model {
+	m := &Model{
+		title:         title,
+		lines:         strings.Split(ansi.Strip(strings.ReplaceAll(content, "\r", "")), "\n"),
+		view:          diff.New(content),
+		textStyle:     common.DefaultPalette.Get("pager text"),
+		dimmedStyle:   common.DefaultPalette.Get("pager dimmed"),
+		matchedStyle:  common.DefaultPalette.Get("pager matched"),
+		selectedStyle: common.DefaultPalette.Get("pager selected"),
+	}
+	m.searchInput = textinput.New()
+	m.searchInput.Prompt = "/"
+	styles := m.searchInput.Styles()
+	styles.Focused.Prompt = m.matchedStyle
+	styles.Focused.Text = m.textStyle
+	styles.Blurred.Prompt = m.matchedStyle
+	styles.Blurred.Text = m.textStyle
+	m.searchInput.SetStyles(styles)
+	return m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Model) StackedActionOwner() string {
+	return actions.OwnerPager
+}
+
+func (m *Model) IsEditing() bool {
+	return m.searching
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case intents.Intent:
+		return m.handleIntent(msg)
+	case scrollMsg:
+		return m.view.Update(diff.ScrollMsg{Delta: msg.Delta, Horizontal: msg.Horizontal})
+	case tea.KeyMsg, tea.PasteMsg:
+		if !m.searching {
+			return nil
+		}
+		updated, cmd := m.searchInput.Update(msg)
+		changed := updated.Value() != m.searchInput.Value()
+		m.searchInput = updated
+		if changed {
+			m.search(updated.Value())
+		}
+		return cmd
+	case common.CloseViewMsg:
+		return common.Close
+	}
+	return nil
+}
+
+func (m *Model) handleIntent(intent intents.Intent) tea.Cmd {
+	switch intent := intent.(type) {
+	case intents.DiffScroll, intents.DiffScrollHorizontal, intents.DiffToggleWrap:
+		return m.view.Update(intent)
+	case intents.PagerSearch:
+		m.searching = true
+		m.previousQuery = m.query
+		m.searchInput.SetValue(m.query)
+		m.searchInput.Focus()
+		m.searchInput.CursorEnd()
+		return textinput.Blink
+	case intents.PagerNextMatch:
+		if len(m.matches) == 0 {
+			return nil
+		}
+		m.current = (m.current + intent.Delta + len(m.matches)) % len(m.matches)
+		m.view.ScrollToLine(m.matches[m.current])
+		return nil
+	case intents.PagerCopy:
+		if err := clipboard.WriteAll(strings.Join(m.lines, "\n")); err != nil {
+			return intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err})
+		}
+		return intents.Invoke(intents.AddMessage{Text: "Copied to clipboard"})
+	case intents.Apply:
+		if m.searching {
+			m.searching = false
+			m.searchInput.Blur()
+			m.search(m.searchInput.Value())
+		}
+		return nil
+	case intents.Cancel:
+		if m.searching {
+			m.searching = false
+			m.searchInput.Blur()
+			m.search(m.previousQuery)
+			return nil
+		}
+		if m.query != "" {
+			m.search("")
+			return nil
+		}
+		return common.Close
+	}
+	return nil
+}
+
+// search finds the lines containing the query, ignoring case, and scrolls to
+// the first of them.
+func (m *Model) search(query string) {
+	m.query = strings.TrimSpace(query)
+	m.matches = m.matches[:0]
+	m.current = 0
+	if m.query == "" {
+		return
+	}
+	needle := strings.ToLower(m.query)
+	for i, line := range m.lines {
+		if strings.Contains(strings.ToLower(line), needle) {
+			m.matches = append(m.matches, i)
+		}
+	}
+	if len(m.matches) > 0 {
+		m.view.ScrollToLine(m.matches[0])
+	}
+}
+
+func (m *Model) ViewRect(dl *render.DisplayContext, box layout.Box) {
+	area := box.R
+	if area.Dx() < 5 || area.Dy() < 4 {
+		return
+	}
+	dl.AddBackdrop(area, render.ZOverlay-1)
+
+	header := m.textStyle.Render(m.title)
+	if status := m.renderSearchStatus(); status != "" {
+		header += m.dimmedStyle.Render("  ") + status
+	}
+	panel := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(m.textStyle.GetForeground()).
+		PaddingLeft(1).
+		PaddingRight(1).
+		Width(area.Dx()).
+		Height(area.Dy()).
+		Render(lipgloss.NewStyle().MaxWidth(area.Dx() - 4).Render(header))
+	dl.AddDraw(area, panel, render.ZOverlay)
+
+	// the border and the header take three lines
+	body := layout.Rect(area.Min.X+2, area.Min.Y+2, area.Dx()-4, area.Dy()-3)
+	if m.searching {
+		var input layout.Rectangle
+		body, input = layout.Rect(body.Min.X, body.Min.Y, body.Dx(), body.Dy()-1), layout.Rect(body.Min.X, body.Max.Y-1, body.Dx(), 1)
+		m.searchInput.SetWidth(max(1, input.Dx()-2))
+		dl.AddDraw(input, m.searchInput.View(), render.ZOverlay)
+	}
+	if body.Dy() <= 0 {
+		return
+	}
+
+	// the output is drawn on its own, since the panel is above what the
+	// viewer draws on
+	content := render.NewDisplayContext()
+	m.view.ViewRect(content, layout.NewBox(layout.Rect(0, 0, body.Dx(), body.Dy())))
+	dl.AddDraw(body, content.RenderToString(body.Dx(), body.Dy()), render.ZOverlay)
+	dl.AddInteraction(body, scrollMsg{}, render.InteractionScroll, render.ZOverlay)
+
+	if len(m.matches) > 0 {
+		if row, ok := m.view.VisibleRow(m.matches[m.current]); ok {
+			dl.AddHighlight(layout.Rect(body.Min.X, body.Min.Y+row, body.Dx(), 1), m.selectedStyle, render.ZOverlay)
+		}
+	}
+}
+
+// renderSearchStatus describes the state of the search.
+func (m *Model) renderSearchStatus() string {
+	if m.query == "" {
+		return ""
+	}
+	if len(m.matches) == 0 {
+		return m.dimmedStyle.Render(fmt.Sprintf("no matches for %q", m.query))
+	}
+	return m.matchedStyle.Render(fmt.Sprintf("match %d/%d", m.current+1, len(m.matches))) +
+		m.dimmedStyle.Render(fmt.Sprintf(" for %q", m.query))
+}
@@ -0,0 +1,77 @@
+package pager
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const output = "\x1b[1mfirst\x1b[0m line\nsecond line\nthird\nfourth line\nfifth"
+
+func TestViewRect_ShowsTitleAndOutput(t *testing.T) {
+	model := New("jj log", output)
+	rendered := test.Stripped(test.RenderImmediate(model, 40, 10))
+	assert.Contains(t, rendered, "jj log")
+	assert.Contains(t, rendered, "second line")
+	assert.Contains(t, rendered, "fifth")
+}
+
+func TestSearch_FindsLinesAndCyclesThroughMatches(t *testing.T) {
+	model := New("jj log", output)
+	test.RenderImmediate(model, 40, 5)
+
+	model.Update(intents.PagerSearch{})
+	assert.True(t, model.IsEditing())
+	for _, r := range "LINE" {
+		model.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	model.Update(intents.Apply{})
+	assert.False(t, model.IsEditing())
+	assert.Equal(t, []int{0, 1, 3}, model.matches)
+	assert.Contains(t, test.Stripped(test.RenderImmediate(model, 40, 5)), `match 1/3 for "LINE"`)
+
+	model.Update(intents.PagerNextMatch{Delta: 1})
+	model.Update(intents.PagerNextMatch{Delta: 1})
+	rendered := test.Stripped(test.RenderImmediate(model, 40, 5))
+	assert.Contains(t, rendered, `match 3/3 for "LINE"`)
+	assert.Contains(t, rendered, "fourth line")
+	assert.NotContains(t, rendered, "second line")
+
+	model.Update(intents.PagerNextMatch{Delta: 1})
+	assert.Equal(t, 0, model.current)
+	model.Update(intents.PagerNextMatch{Delta: -1})
+	assert.Equal(t, 2, model.current)
+}
+
+func TestSearch_NoMatches(t *testing.T) {
+	model := New("jj log", output)
+	model.search("missing")
+	assert.Empty(t, model.matches)
+	assert.Nil(t, model.Update(intents.PagerNextMatch{Delta: 1}))
+	assert.Contains(t, test.Stripped(test.RenderImmediate(model, 40, 10)), `no matches for "missing"`)
+}
+
+func TestCancel_RestoresSearchThenClearsItThenCloses(t *testing.T) {
+	model := New("jj log", output)
+	model.search("third")
+
+	model.Update(intents.PagerSearch{})
+	model.Update(tea.KeyPressMsg{Code: 'x', Text: "x"})
+	assert.Equal(t, "thirdx", model.query)
+	assert.Nil(t, model.Update(intents.Cancel{}))
+	assert.False(t, model.IsEditing())
+	assert.Equal(t, "third", model.query)
+	assert.Equal(t, []int{2}, model.matches)
+
+	assert.Nil(t, model.Update(intents.Cancel{}))
+	assert.Empty(t, model.query)
+	assert.Empty(t, model.matches)
+
+	cmd := model.Update(intents.Cancel{})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, common.CloseViewMsg{}, cmd())
+}
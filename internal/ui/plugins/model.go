@@ -0,0 +1,195 @@
+package plugins
+
+import (
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/idursun/jjui/internal/scripting"
+	"github.com/idursun/jjui/internal/ui/actions"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/internal/ui/layout"
+	"github.com/idursun/jjui/internal/ui/render"
+)
+
+var _ common.StackedModel = (*Model)(nil)
+
+// detailLines is how many lines of the selected plugin are shown below the
+// list.
+const detailLines = 6
+
+type selectPluginMsg struct {
+	index int
+}
+
+// Model lists the plugins found at start, and lets them be enabled or
+// disabled for the next start.
+type Model struct {
+	items         []*scripting.Plugin
+	selectedIndex int
+	windowStart   int
+	textStyle     lipgloss.Style
+	matchedStyle  lipgloss.Style
+	dimmedStyle   lipgloss.Style
+	successStyle  lipgloss.Style
+	errorStyle    lipgloss.Style
+}
+
+func New(items []*scripting.Plugin) *Model {
+	return &Model{
+		items:        items,
+		textStyle:    common.DefaultPalette.Get("flash text"),
+		matchedStyle: common.DefaultPalette.Get("flash matched"),
+		dimmedStyle:  common.DefaultPalette.Get("flash dimmed"),
+		successStyle: common.DefaultPalette.Get("flash success"),
+		errorStyle:   common.DefaultPalette.Get("flash error"),
+	}
+}
+
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Model) StackedActionOwner() string {
+	return actions.OwnerPlugins
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case intents.Intent:
+		switch intent := msg.(type) {
+		case intents.PluginsNavigate:
+			if len(m.items) == 0 {
+				return nil
+			}
+			m.selectedIndex = min(len(m.items)-1, max(0, m.selectedIndex+intent.Delta))
+			return nil
+		case intents.PluginsToggle:
+			if len(m.items) == 0 {
+				return nil
+			}
+			plugin := m.items[m.selectedIndex]
+			if err := scripting.SetPluginEnabled(plugin, !plugin.Enabled); err != nil {
+				return intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err})
+			}
+			return nil
+		case intents.PluginsClose:
+			return common.Close
+		}
+	case selectPluginMsg:
+		if msg.index >= 0 && msg.index < len(m.items) {
+			m.selectedIndex = msg.index
+		}
+		return nil
+	case common.CloseViewMsg:
+		return common.Close
+	}
+	return nil
+}
+
+func (m *Model) ViewRect(dl *render.DisplayContext, box layout.Box) {
+	rest, _ := box.CutBottom(1)
+	dl.AddDim(rest.R, render.ZOverlay)
+
+	area := rest.R
+	width := max(0, area.Dx()-4)
+	// the border, the header and the separator take four lines
+	height := max(1, area.Dy()-4)
+
+	header := m.textStyle.Render("Plugins") + m.dimmedStyle.Render("  changes apply on the next start")
+	var lines []string
+	if len(m.items) == 0 {
+		lines = append(lines, m.dimmedStyle.Render("no plugins"))
+	} else {
+		detail := m.renderDetail(m.items[m.selectedIndex], width)
+		listHeight := max(1, height-len(detail))
+		m.clampWindow(listHeight)
+		end := min(len(m.items), m.windowStart+listHeight)
+		for i := m.windowStart; i < end; i++ {
+			lines = append(lines, m.renderRow(m.items[i], width, i == m.selectedIndex))
+			rowRect := layout.Rect(area.Min.X+2, area.Min.Y+2+i-m.windowStart, width, 1)
+			dl.AddInteraction(rowRect, selectPluginMsg{index: i}, render.InteractionClick, render.ZOverlay)
+		}
+		for range listHeight - (end - m.windowStart) {
+			lines = append(lines, "")
+		}
+		lines = append(lines, m.dimmedStyle.Render(strings.Repeat("─", width)))
+		lines = append(lines, detail...)
+	}
+
+	content := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(m.textStyle.GetForeground()).
+		PaddingLeft(1).
+		PaddingRight(1).
+		Width(area.Dx()).
+		Height(area.Dy()).
+		Render(header + "\n" + strings.Join(lines, "\n"))
+	dl.AddDraw(area, content, render.ZOverlay)
+}
+
+func (m *Model) clampWindow(height int) {
+	if m.selectedIndex < m.windowStart {
+		m.windowStart = m.selectedIndex
+	}
+	if m.selectedIndex >= m.windowStart+height {
+		m.windowStart = m.selectedIndex - height + 1
+	}
+	m.windowStart = max(0, min(m.windowStart, len(m.items)-height))
+}
+
+// status describes the plugin as it is now, and what the next start does
+// with it when it has been enabled or disabled since.
+func (m *Model) status(plugin *scripting.Plugin) string {
+	switch {
+	case plugin.Err != nil:
+		return m.errorStyle.Render("failed")
+	case plugin.Loaded && !plugin.Enabled:
+		return m.dimmedStyle.Render("disabled on restart")
+	case plugin.Loaded:
+		return m.successStyle.Render("loaded")
+	case plugin.Enabled:
+		return m.dimmedStyle.Render("loads on restart")
+	}
+	return m.dimmedStyle.Render("disabled")
+}
+
+func (m *Model) renderRow(plugin *scripting.Plugin, width int, selected bool) string {
+	var b strings.Builder
+	if selected {
+		b.WriteString(m.matchedStyle.Render("› "))
+	} else {
+		b.WriteString("  ")
+	}
+	if plugin.Enabled {
+		b.WriteString(m.textStyle.Render("[x] "))
+	} else {
+		b.WriteString(m.dimmedStyle.Render("[ ] "))
+	}
+	b.WriteString(m.textStyle.Render(plugin.Name))
+	if plugin.Version != "" {
+		b.WriteString(m.dimmedStyle.Render(" " + plugin.Version))
+	}
+	b.WriteString("  ")
+	b.WriteString(m.status(plugin))
+	if plugin.Description != "" {
+		b.WriteString(m.dimmedStyle.Render(" · " + plugin.Description))
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(b.String())
+}
+
+func (m *Model) renderDetail(plugin *scripting.Plugin, width int) []string {
+	parts := []string{m.dimmedStyle.Render(plugin.Dir)}
+	if plugin.Description != "" {
+		parts = append(parts, m.textStyle.Render(plugin.Description))
+	}
+	if plugin.Err != nil {
+		parts = append(parts, m.errorStyle.Render(plugin.Err.Error()))
+	}
+	lines := strings.Split(lipgloss.NewStyle().Width(width).Render(strings.Join(parts, "\n")), "\n")
+	if len(lines) > detailLines {
+		lines = lines[:detailLines]
+	}
+	return lines
+}
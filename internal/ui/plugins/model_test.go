@@ -0,0 +1,84 @@
+package plugins
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/idursun/jjui/internal/scripting"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/intents"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPlugins() []*scripting.Plugin {
+	return []*scripting.Plugin{
+		{
+			PluginManifest: scripting.PluginManifest{Name: "greeter", Version: "1.0.0", Description: "Says hello"},
+			Dir:            "/plugins/greeter",
+			Enabled:        true,
+			Loaded:         true,
+		},
+		{
+			PluginManifest: scripting.PluginManifest{Name: "broken"},
+			Dir:            "/plugins/broken",
+			Enabled:        true,
+			Err:            errors.New("init.lua: boom"),
+		},
+		{
+			PluginManifest: scripting.PluginManifest{Name: "idle"},
+			Dir:            "/plugins/idle",
+		},
+	}
+}
+
+func TestPlugins_ListsPluginsWithStatus(t *testing.T) {
+	m := New(newPlugins())
+
+	rendered := test.RenderImmediate(m, 100, 30)
+	assert.Contains(t, rendered, "Plugins")
+	assert.Contains(t, rendered, "greeter 1.0.0  loaded · Says hello")
+	assert.Contains(t, rendered, "broken  failed")
+	assert.Contains(t, rendered, "idle  disabled")
+	assert.Contains(t, rendered, "/plugins/greeter")
+}
+
+func TestPlugins_NavigateShowsSelectedError(t *testing.T) {
+	m := New(newPlugins())
+
+	m.Update(intents.PluginsNavigate{Delta: 5})
+	assert.Equal(t, 2, m.selectedIndex)
+	m.Update(intents.PluginsNavigate{Delta: -1})
+	assert.Equal(t, 1, m.selectedIndex)
+
+	rendered := test.RenderImmediate(m, 100, 30)
+	assert.Contains(t, rendered, "/plugins/broken")
+	assert.Contains(t, rendered, "init.lua: boom")
+}
+
+func TestPlugins_TogglePersistsForNextStart(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	items := newPlugins()
+	m := New(items)
+
+	assert.Nil(t, m.Update(intents.PluginsToggle{}))
+	assert.False(t, items[0].Enabled)
+	assert.Contains(t, test.RenderImmediate(m, 100, 30), "disabled on restart")
+
+	m.Update(intents.PluginsNavigate{Delta: 2})
+	m.Update(intents.PluginsToggle{})
+	assert.True(t, items[2].Enabled)
+	assert.Contains(t, test.RenderImmediate(m, 100, 30), "loads on restart")
+}
+
+func TestPlugins_Close(t *testing.T) {
+	m := New(nil)
+
+	assert.Contains(t, test.RenderImmediate(m, 80, 20), "no plugins")
+
+	m.Update(intents.PluginsToggle{})
+	cmd := m.Update(intents.PluginsClose{})
+	if assert.NotNil(t, cmd) {
+		assert.IsType(t, common.CloseViewMsg{}, cmd())
+	}
+}
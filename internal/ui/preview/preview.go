@@ -10,6 +10,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/scripting"
 	"github.com/idursun/jjui/internal/ui/common"
 	"github.com/idursun/jjui/internal/ui/context"
 	"github.com/idursun/jjui/internal/ui/intents"
@@ -26,6 +27,8 @@ type Model struct {
 	previewAtBottom     bool
 	content             string
 	context             *context.MainContext
+	// provider is the preview provider that waits for a command, if any.
+	provider *scripting.Runner
 }
 
 const (
@@ -41,6 +44,10 @@ type updatePreviewContentMsg struct {
 	Content string
 }
 
+type runPreviewProviderMsg struct {
+	item common.SelectedItem
+}
+
 type ScrollMsg struct {
 	Delta      int
 	Horizontal bool
@@ -134,6 +141,19 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 	if k, ok := msg.(previewMsg); ok {
 		msg = k.msg
 	}
+	if m.provider != nil {
+		cmd := m.provider.HandleMsg(msg)
+		if m.provider.Done() {
+			m.provider = nil
+		}
+		if cmd != nil {
+			return tea.Batch(cmd, m.update(msg))
+		}
+	}
+	return m.update(msg)
+}
+
+func (m *Model) update(msg tea.Msg) tea.Cmd {
 	switch msg := msg.(type) {
 	case ScrollMsg:
 		if msg.Horizontal {
@@ -154,6 +174,24 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 	case updatePreviewContentMsg:
 		m.SetContent(msg.Content)
 		return nil
+	case runPreviewProviderMsg:
+		// the provider of the previous selection is of no use anymore
+		if m.provider != nil {
+			m.provider.Stop()
+		}
+		var cmd tea.Cmd
+		m.provider, cmd = scripting.RunPreviewProvider(m.context, msg.item, m.view.Width(), m.view.Height())
+		return cmd
+	case scripting.PreviewResultMsg:
+		switch {
+		case !msg.Handled:
+			args := m.previewArgs(msg.Item)
+			return func() tea.Msg { return m.runPreviewCommand(args) }
+		case msg.Args != nil:
+			return func() tea.Msg { return m.runPreviewCommand(msg.Args) }
+		}
+		m.SetContent(msg.Content)
+		return nil
 	}
 	return nil
 }
@@ -191,53 +229,66 @@ func (m *Model) refreshPreview() tea.Cmd {
 }
 
 func (m *Model) refreshPreviewForItem(item common.SelectedItem) tea.Cmd {
+	if scripting.HasPreviewProvider(m.context, item) {
+		// the provider runs like any other script, its commands off the main loop
+		return common.Debounce(debounceId, debounceDuration, func() tea.Msg {
+			return runPreviewProviderMsg{item: item}
+		})
+	}
 	return common.Debounce(debounceId, debounceDuration, func() tea.Msg {
-		var args []string
-		previewWidth := strconv.Itoa(m.view.Width())
-		switch sel := item.(type) {
-		case common.SelectedFile:
-			args = jj.TemplatedArgs(config.Current.Preview.FileCommand, map[string]string{
-				jj.RevsetPlaceholder:       m.context.CurrentRevset,
-				jj.ChangeIdPlaceholder:     sel.ChangeId,
-				jj.CommitIdPlaceholder:     sel.CommitId,
-				jj.FilePlaceholder:         sel.File,
-				jj.PreviewWidthPlaceholder: previewWidth,
-			})
-		case common.SelectedRevision:
-			args = jj.TemplatedArgs(config.Current.Preview.RevisionCommand, map[string]string{
-				jj.RevsetPlaceholder:       m.context.CurrentRevset,
-				jj.ChangeIdPlaceholder:     sel.ChangeId,
-				jj.CommitIdPlaceholder:     sel.CommitId,
-				jj.PreviewWidthPlaceholder: previewWidth,
-			})
-		case common.SelectedCommit:
-			args = jj.TemplatedArgs(config.Current.Preview.EvologCommand, map[string]string{
-				jj.RevsetPlaceholder:       m.context.CurrentRevset,
-				jj.CommitIdPlaceholder:     sel.CommitId,
-				jj.PreviewWidthPlaceholder: previewWidth,
-			})
-		case common.SelectedOperation:
-			args = jj.TemplatedArgs(config.Current.Preview.OplogCommand, map[string]string{
-				jj.RevsetPlaceholder:       m.context.CurrentRevset,
-				jj.OperationIdPlaceholder:  sel.OperationId,
-				jj.PreviewWidthPlaceholder: previewWidth,
-			})
-		}
-
-		env := []string{
-			// The preview subprocess does not run in a pane-sized PTY, so let
-			// width-sensitive tools like `jj diff` see the preview size via the
-			// conventional terminal size environment variables.
-			"COLUMNS=" + strconv.Itoa(m.view.Width()),
-			"LINES=" + strconv.Itoa(m.view.Height()),
-		}
-		output, _ := m.context.RunCommandImmediateWithEnv(args, env)
-		return updatePreviewContentMsg{
-			Content: string(output),
-		}
+		return m.runPreviewCommand(m.previewArgs(item))
 	})
 }
 
+func (m *Model) previewArgs(item common.SelectedItem) []string {
+	var args []string
+	previewWidth := strconv.Itoa(m.view.Width())
+	switch sel := item.(type) {
+	case common.SelectedFile:
+		args = jj.TemplatedArgs(config.Current.Preview.FileCommand, map[string]string{
+			jj.RevsetPlaceholder:       m.context.CurrentRevset,
+			jj.ChangeIdPlaceholder:     sel.ChangeId,
+			jj.CommitIdPlaceholder:     sel.CommitId,
+			jj.FilePlaceholder:         sel.File,
+			jj.PreviewWidthPlaceholder: previewWidth,
+		})
+	case common.SelectedRevision:
+		args = jj.TemplatedArgs(config.Current.Preview.RevisionCommand, map[string]string{
+			jj.RevsetPlaceholder:       m.context.CurrentRevset,
+			jj.ChangeIdPlaceholder:     sel.ChangeId,
+			jj.CommitIdPlaceholder:     sel.CommitId,
+			jj.PreviewWidthPlaceholder: previewWidth,
+		})
+	case common.SelectedCommit:
+		args = jj.TemplatedArgs(config.Current.Preview.EvologCommand, map[string]string{
+			jj.RevsetPlaceholder:       m.context.CurrentRevset,
+			jj.CommitIdPlaceholder:     sel.CommitId,
+			jj.PreviewWidthPlaceholder: previewWidth,
+		})
+	case common.SelectedOperation:
+		args = jj.TemplatedArgs(config.Current.Preview.OplogCommand, map[string]string{
+			jj.RevsetPlaceholder:       m.context.CurrentRevset,
+			jj.OperationIdPlaceholder:  sel.OperationId,
+			jj.PreviewWidthPlaceholder: previewWidth,
+		})
+	}
+	return args
+}
+
+func (m *Model) runPreviewCommand(args []string) tea.Msg {
+	env := []string{
+		// The preview subprocess does not run in a pane-sized PTY, so let
+		// width-sensitive tools like `jj diff` see the preview size via the
+		// conventional terminal size environment variables.
+		"COLUMNS=" + strconv.Itoa(m.view.Width()),
+		"LINES=" + strconv.Itoa(m.view.Height()),
+	}
+	output, _ := m.context.RunCommandImmediateWithEnv(args, env)
+	return updatePreviewContentMsg{
+		Content: string(output),
+	}
+}
+
 func New(context *context.MainContext) *Model {
 	previewAutoPosition := false
 	previewAtBottom := false
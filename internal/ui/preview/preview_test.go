@@ -5,6 +5,7 @@ import (
 
 	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/scripting"
 	"github.com/idursun/jjui/internal/ui/common"
 	"github.com/idursun/jjui/internal/ui/intents"
 	"github.com/idursun/jjui/internal/ui/layout"
@@ -178,6 +179,94 @@ func TestUpdate_PreviewShowDoesNotBreakSelectionRefresh(t *testing.T) {
 	assert.Equal(t, "auto preview", model.content)
 }
 
+func setupPreviewProvider(t *testing.T, commandRunner *test.CommandRunner, script string) *Model {
+	t.Helper()
+	ctx := test.NewTestContext(commandRunner)
+	ctx.CurrentRevset = "all()"
+	require.NoError(t, scripting.InitVM(ctx))
+	t.Cleanup(func() { scripting.CloseVM(ctx) })
+	require.NoError(t, ctx.ScriptVM.DoString(script))
+	return New(ctx)
+}
+
+func TestUpdate_PreviewProviderContent(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	defer commandRunner.Verify()
+	model := setupPreviewProvider(t, commandRunner, `
+		jjui.preview.register("revision", function(item)
+			return "PR for " .. item.change_id .. " in " .. item.revset
+		end)
+	`)
+
+	cmd := model.Update(common.SelectionChangedMsg{Item: common.SelectedRevision{ChangeId: "change", CommitId: "commit"}})
+	test.SimulateModel(model, cmd)
+
+	assert.Equal(t, "PR for change in all()", model.content)
+}
+
+func TestUpdate_PreviewProviderWaitsForCommands(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect([]string{"show", "commit"}).SetOutput([]byte("shown"))
+	defer commandRunner.Verify()
+	model := setupPreviewProvider(t, commandRunner, `
+		jjui.preview.register("revision", function(item)
+			return "provider: " .. jj_wait("show", item.commit_id)
+		end)
+	`)
+
+	cmd := model.Update(common.SelectionChangedMsg{Item: common.SelectedRevision{ChangeId: "change", CommitId: "commit"}})
+	test.SimulateModel(model, cmd)
+
+	assert.Equal(t, "provider: shown", model.content)
+	assert.Nil(t, model.provider)
+}
+
+func TestUpdate_PreviewProviderDelegatesToCommand(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect([]string{"diff", "--stat", "-r", "commit"}).SetOutput([]byte("stat"))
+	defer commandRunner.Verify()
+	model := setupPreviewProvider(t, commandRunner, `
+		jjui.preview.register("revision", function(item)
+			return { command = { "diff", "--stat", "-r", item.commit_id } }
+		end)
+	`)
+
+	cmd := model.Update(common.SelectionChangedMsg{Item: common.SelectedRevision{ChangeId: "change", CommitId: "commit"}})
+	test.SimulateModel(model, cmd)
+
+	assert.Equal(t, "stat", model.content)
+}
+
+func TestUpdate_PreviewProviderFallsBackToConfiguredCommand(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	defer commandRunner.Verify()
+	model := setupPreviewProvider(t, commandRunner, `
+		jjui.preview.register("file", function(item)
+			if item.file ~= "pr.json" then
+				return nil
+			end
+			return { content = "status" }
+		end)
+	`)
+
+	selected := common.SelectedFile{ChangeId: "change", CommitId: "commit", File: "main.go"}
+	args := jj.TemplatedArgs(config.Current.Preview.FileCommand, map[string]string{
+		jj.RevsetPlaceholder:       "all()",
+		jj.ChangeIdPlaceholder:     selected.ChangeId,
+		jj.CommitIdPlaceholder:     selected.CommitId,
+		jj.FilePlaceholder:         selected.File,
+		jj.PreviewWidthPlaceholder: "0",
+	})
+	commandRunner.Expect(args).SetOutput([]byte("diff"))
+
+	test.SimulateModel(model, model.Update(common.SelectionChangedMsg{Item: selected}))
+	assert.Equal(t, "diff", model.content)
+
+	selected.File = "pr.json"
+	test.SimulateModel(model, model.Update(common.SelectionChangedMsg{Item: selected}))
+	assert.Equal(t, "status", model.content)
+}
+
 func TestSetContent_ExpandsTabsUsingTabStops(t *testing.T) {
 	ctx := test.NewTestContext(test.NewTestCommandRunner(t))
 	model := New(ctx)
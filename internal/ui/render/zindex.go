@@ -29,6 +29,9 @@ const (
 	// ZMenuContent is for menu content items
 	ZMenuContent = 101
 
+	// ZMenuDialog is for confirmation dialogs opened from within a menu
+	ZMenuDialog = 150
+
 	// ZOverlay is for overlays like sequence overlay and flash messages
 	ZOverlay = 200
 
@@ -26,9 +26,10 @@ func TestHandleDispatchedAction_RevisionsModeOpeners(t *testing.T) {
 	model := New(ctx)
 	model.updateGraphRows(rows, "a")
 
-	_, handled := model.HandleDispatchedAction(keybindings.Action("revisions.open_details"), nil)
+	cmd, handled := model.HandleDispatchedAction(keybindings.Action("revisions.open_details"), nil)
 	assert.True(t, handled, "open_details should be handled by revisions dispatcher")
 	assert.Equal(t, "details", model.CurrentOperation().Name())
+	test.SimulateModel(model, cmd)
 
 	model.Update(intents.Cancel{})
 	model.updateGraphRows(rows, "a")
@@ -18,6 +18,7 @@ import (
 type DisplayContextRenderer struct {
 	listRenderer  *render.ListRenderer
 	selections    map[string]bool
+	decorations   map[string][]common.Badge
 	textStyle     lipgloss.Style
 	dimmedStyle   lipgloss.Style
 	selectedStyle lipgloss.Style
@@ -100,6 +101,11 @@ func (r *DisplayContextRenderer) SetSelections(selections map[string]bool) {
 	r.selections = selections
 }
 
+// SetDecorations sets the badges drawn next to the revisions, by commit id
+func (r *DisplayContextRenderer) SetDecorations(decorations map[string][]common.Badge) {
+	r.decorations = decorations
+}
+
 // Render renders the revisions list to a DisplayContext
 func (r *DisplayContextRenderer) Render(
 	dl *render.DisplayContext,
@@ -602,6 +608,14 @@ func (ir *itemRenderer) renderLine(tb *render.TextBuilder, line *parser.GraphRow
 		tb.Write(" " + beforeCommitID)
 	}
 
+	// Add badges scripts decorated the revision with
+	if line.Flags&parser.Revision == parser.Revision && ir.row.Commit != nil {
+		for _, badge := range ir.renderer.decorations[ir.row.Commit.CommitId] {
+			tb.Write(" ")
+			tb.Styled(badge.Text, badge.Style)
+		}
+	}
+
 	// Add affected marker
 	if line.Flags&parser.Revision == parser.Revision && ir.row.IsAffected {
 		tb.Styled(" (affected by last operation)", ir.renderer.dimmedStyle)
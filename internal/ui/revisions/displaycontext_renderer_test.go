@@ -6,11 +6,15 @@ import (
 	"testing"
 
 	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi"
 
 	"charm.land/lipgloss/v2"
+	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/parser"
+	"github.com/idursun/jjui/internal/ui/common"
 	"github.com/idursun/jjui/internal/ui/layout"
+	"github.com/idursun/jjui/internal/ui/operations"
 	"github.com/idursun/jjui/internal/ui/operations/describe"
 	"github.com/idursun/jjui/internal/ui/operations/details"
 	"github.com/idursun/jjui/internal/ui/render"
@@ -111,3 +115,33 @@ func TestDisplayContextRenderer_SingleRowDescriptionOverlay(t *testing.T) {
 	assert.Contains(t, out, overlayContent,
 		"describe overlay should render for single-line commits")
 }
+
+func TestDisplayContextRenderer_DrawsDecorationsOnRevisionLine(t *testing.T) {
+	f, err := os.Open("testdata/single-line-log.log")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	rows := parser.ParseRows(f)
+	require.NotEmpty(t, rows)
+	targetRow := rows[0]
+	require.NotNil(t, targetRow.Commit)
+
+	r := NewDisplayContextRenderer(lipgloss.NewStyle(), lipgloss.NewStyle(), lipgloss.NewStyle(), lipgloss.NewStyle())
+	r.SetDecorations(map[string][]common.Badge{
+		targetRow.Commit.CommitId: {
+			common.NewBadge("CI ✓", config.Color{Fg: "green"}),
+			common.NewBadge("reviewed", config.Color{}),
+		},
+	})
+
+	width, height := 100, 2
+	dl := render.NewDisplayContext()
+	viewRect := layout.NewBox(layout.Rect(0, 0, width, height))
+	r.Render(dl, []parser.Row{targetRow}, 0, viewRect, operations.NewDefault(), "", true)
+
+	buf := uv.NewScreenBuffer(width, height)
+	dl.Render(buf)
+	line := ansi.Strip(strings.Split(buf.Render(), "\n")[0])
+
+	assert.Contains(t, line, "revealing what's invisible CI ✓ reviewed")
+}
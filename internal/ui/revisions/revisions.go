@@ -69,6 +69,8 @@ type Model struct {
 	matchedStyle           lipgloss.Style
 	ensureCursorView       bool
 	requestInFlight        bool
+	// pendingRefresh holds the refreshes requested while commands were queued
+	pendingRefresh *intents.Refresh
 }
 
 type revisionsMsg struct {
@@ -98,6 +100,13 @@ func (v ViewportScrollMsg) SetDelta(delta int, horizontal bool) tea.Msg {
 	return v
 }
 
+// operationCheckedMsg carries the id of the latest operation an auto refresh
+// found after snapshotting the working copy.
+type operationCheckedMsg string
+
+// queueIdleMsg is sent once the commands queued before a deferred refresh have finished.
+type queueIdleMsg struct{}
+
 type updateRevisionsMsg struct {
 	rows             []parser.Row
 	selectedRevision string
@@ -109,6 +118,8 @@ type streamingReadyMsg struct {
 	tag              uint64
 	err              error
 	output           string
+	// commandID is the log command shown in the live output panel until the graph is ready
+	commandID int
 }
 
 type appendRowsBatchMsg struct {
@@ -300,12 +311,20 @@ func (m *Model) internalUpdate(msg tea.Msg) tea.Cmd {
 		m.op = operations.NewDefault()
 		return m.updateSelection()
 	case common.CommandCompletedMsg:
+		if msg.Background {
+			return nil
+		}
 		m.output = msg.Output
 		m.err = msg.Err
 		return nil
 	case common.AutoRefreshMsg:
-		id, _ := m.context.RunCommandImmediate(jj.OpLogId(true))
-		currentOperationId := string(id)
+		// snapshotting would only wait behind the queue, which ends in a refresh anyway
+		if m.context.QueueBusy() {
+			return nil
+		}
+		return m.checkOperation
+	case operationCheckedMsg:
+		currentOperationId := string(msg)
 		log.Println("Previous operation ID:", m.previousOpLogId, "Current operation ID:", currentOperationId)
 		if currentOperationId != m.previousOpLogId {
 			m.previousOpLogId = currentOperationId
@@ -315,10 +334,25 @@ func (m *Model) internalUpdate(msg tea.Msg) tea.Cmd {
 		m.isLoading = false
 		return nil
 	case common.RefreshMsg:
-		return tea.Batch(m.refresh(intents.Refresh{
+		intent := intents.Refresh{
 			KeepSelections:   msg.KeepSelections,
 			SelectedRevision: msg.SelectedRevision,
-		}), m.op.Update(msg))
+		}
+		if m.context.QueueBusy() {
+			return tea.Batch(m.deferRefresh(intent), m.op.Update(msg))
+		}
+		m.pendingRefresh = nil
+		return tea.Batch(m.refresh(intent), m.op.Update(msg))
+	case queueIdleMsg:
+		if m.pendingRefresh == nil {
+			return nil
+		}
+		if m.context.QueueBusy() {
+			return m.waitForQueue()
+		}
+		intent := *m.pendingRefresh
+		m.pendingRefresh = nil
+		return m.refresh(intent)
 	case updateRevisionsMsg:
 		m.isLoading = false
 		m.updateGraphRows(msg.rows, msg.selectedRevision)
@@ -326,11 +360,15 @@ func (m *Model) internalUpdate(msg tea.Msg) tea.Cmd {
 			return common.UpdateRevisionsSuccessMsg{}
 		})
 	case streamingReadyMsg:
+		var ready tea.Cmd
+		if msg.commandID != 0 {
+			ready = func() tea.Msg { return common.CommandCompletedMsg{ID: msg.commandID, Background: true} }
+		}
 		if msg.tag != m.tag.Load() {
 			if msg.streamer != nil {
 				msg.streamer.Close()
 			}
-			return nil
+			return ready
 		}
 
 		if m.streamer != nil {
@@ -342,12 +380,12 @@ func (m *Model) internalUpdate(msg tea.Msg) tea.Cmd {
 			m.hasMore = false
 			m.isLoading = false
 			m.requestInFlight = false
-			return func() tea.Msg {
+			return tea.Batch(ready, func() tea.Msg {
 				return common.UpdateRevisionsFailedMsg{
 					Err:    msg.err,
 					Output: msg.output,
 				}
-			}
+			})
 		}
 
 		m.streamer = msg.streamer
@@ -367,7 +405,7 @@ func (m *Model) internalUpdate(msg tea.Msg) tea.Cmd {
 		}
 		log.Println("Starting streaming revisions with tag:", msg.tag)
 
-		cmds := []tea.Cmd{m.requestMoreRows(msg.tag)}
+		cmds := []tea.Cmd{ready, m.requestMoreRows(msg.tag)}
 		if warning := streamingWarningCmd(msg.output, msg.err); warning != nil {
 			cmds = append(cmds, warning)
 		}
@@ -394,6 +432,7 @@ func (m *Model) internalUpdate(msg tea.Msg) tea.Cmd {
 
 		currentSelectedRevision := m.SelectedRevision()
 		m.rows = m.offScreenRows
+		m.publishLoadedRevisions()
 		if m.revisionToSelect != "" {
 			m.SetCursor(m.selectRevision(m.revisionToSelect))
 			m.revisionToSelect = ""
@@ -532,6 +571,33 @@ func (m *Model) startBookmarkSet() tea.Cmd {
 	return m.op.Init()
 }
 
+// deferRefresh merges the refresh into the one that runs once the queued
+// commands have finished, so a burst of commands reloads the log only once.
+func (m *Model) deferRefresh(intent intents.Refresh) tea.Cmd {
+	if m.pendingRefresh != nil {
+		intent.KeepSelections = intent.KeepSelections && m.pendingRefresh.KeepSelections
+		if intent.SelectedRevision == "" {
+			intent.SelectedRevision = m.pendingRefresh.SelectedRevision
+		}
+	}
+	m.pendingRefresh = &intent
+	return m.waitForQueue()
+}
+
+func (m *Model) waitForQueue() tea.Cmd {
+	return func() tea.Msg {
+		m.context.WaitForQueue()
+		return queueIdleMsg{}
+	}
+}
+
+// checkOperation snapshots the working copy off the UI loop, since it waits
+// for its turn in the queue, and reports the latest operation.
+func (m *Model) checkOperation() tea.Msg {
+	id, _ := m.context.RunCommandImmediate(jj.OpLogId(true))
+	return operationCheckedMsg(id)
+}
+
 func (m *Model) refresh(intent intents.Refresh) tea.Cmd {
 	if !intent.KeepSelections {
 		m.context.ClearCheckedItems(reflect.TypeFor[appContext.SelectedRevision]())
@@ -888,6 +954,15 @@ func (m *Model) highlightChanges() tea.Msg {
 	return nil
 }
 
+// publishLoadedRevisions lets scripts see the revisions in the log.
+func (m *Model) publishLoadedRevisions() {
+	revisions := make([]*jj.Commit, 0, len(m.rows))
+	for _, row := range m.rows {
+		revisions = append(revisions, row.Commit)
+	}
+	m.context.LoadedRevisions = revisions
+}
+
 func (m *Model) updateGraphRows(rows []parser.Row, selectedRevision string) {
 	if rows == nil {
 		rows = []parser.Row{}
@@ -898,6 +973,7 @@ func (m *Model) updateGraphRows(rows []parser.Row, selectedRevision string) {
 		currentSelectedRevision = cur.GetChangeId()
 	}
 	m.rows = rows
+	m.publishLoadedRevisions()
 
 	if len(m.rows) > 0 {
 		m.SetCursor(m.selectRevision(currentSelectedRevision))
@@ -926,6 +1002,7 @@ func (m *Model) ViewRect(dl *render.DisplayContext, box layout.Box) {
 
 	// Set selections
 	m.displayContextRenderer.SetSelections(m.context.GetSelectedRevisions())
+	m.displayContextRenderer.SetDecorations(m.context.Decorations)
 
 	// Get operation if any
 	var op operations.Operation
@@ -974,12 +1051,18 @@ func (m *Model) load(revset string, selectedRevision string) tea.Cmd {
 }
 
 func (m *Model) loadStreaming(revset string, selectedRevision string, tag uint64) tea.Cmd {
-	return func() tea.Msg {
+	started := make(chan *appContext.StreamingCommand, 1)
+	load := func() tea.Msg {
+		defer close(started)
 		if m.tag.Load() != tag {
 			return nil
 		}
 
-		streamer, err := graph.NewGraphStreamer(context.Background(), m.context, revset, m.context.JJConfig.Templates.Log)
+		commandID := 0
+		streamer, err := graph.NewGraphStreamer(context.Background(), m.context, revset, m.context.JJConfig.Templates.Log, func(command *appContext.StreamingCommand) {
+			commandID = command.ID
+			started <- command
+		})
 		var errMsg string
 		if err != nil {
 			if err == io.EOF {
@@ -996,8 +1079,19 @@ func (m *Model) loadStreaming(revset string, selectedRevision string, tag uint64
 			tag:              tag,
 			err:              err,
 			output:           errMsg,
+			commandID:        commandID,
+		}
+	}
+	// show the query in the live output panel while it's waiting for the first
+	// rows, so that a slow revset can be canceled
+	report := func() tea.Msg {
+		command, ok := <-started
+		if !ok || command.ID == 0 {
+			return nil
 		}
+		return command.Running()
 	}
+	return tea.Batch(report, load)
 }
 
 func (m *Model) requestMoreRows(tag uint64) tea.Cmd {
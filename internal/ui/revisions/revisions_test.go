@@ -12,6 +12,7 @@ import (
 	"github.com/idursun/jjui/internal/ui/intents"
 	"github.com/idursun/jjui/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestModel_highlightChanges(t *testing.T) {
@@ -152,3 +153,41 @@ func TestModel_StartAceJumpMsg_OpensAceJumpOperation(t *testing.T) {
 	assert.Equal(t, keybindings.Scope(actions.OwnerAceJump), scopes[0])
 	assert.Len(t, scopes, 1)
 }
+
+func TestModel_RefreshDeferredWhileQueueBusy(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.SetQueued(1)
+	model := New(test.NewTestContext(commandRunner))
+	model.updateGraphRows(rows, "a")
+
+	model.Update(common.RefreshMsg{KeepSelections: true})
+	model.Update(common.RefreshMsg{KeepSelections: true, SelectedRevision: "b"})
+	assert.False(t, model.isLoading, "refresh waits for the queue")
+	if assert.NotNil(t, model.pendingRefresh) {
+		assert.Equal(t, "b", model.pendingRefresh.SelectedRevision)
+		assert.True(t, model.pendingRefresh.KeepSelections)
+	}
+
+	assert.Nil(t, model.Update(common.AutoRefreshMsg{}), "no snapshot while the queue is busy")
+
+	commandRunner.SetQueued(0)
+	cmd := model.Update(queueIdleMsg{})
+	assert.NotNil(t, cmd)
+	assert.True(t, model.isLoading)
+	assert.Nil(t, model.pendingRefresh)
+	assert.Nil(t, model.Update(queueIdleMsg{}), "merged refreshes only reload once")
+}
+
+func TestModel_AutoRefreshSnapshotsOffTheUILoop(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.OpLogId(true)).SetOutput([]byte("op"))
+	defer commandRunner.Verify()
+	model := New(test.NewTestContext(commandRunner))
+
+	cmd := model.Update(common.AutoRefreshMsg{})
+	require.NotNil(t, cmd)
+	assert.Equal(t, operationCheckedMsg("op"), cmd())
+
+	assert.NotNil(t, model.Update(operationCheckedMsg("op")), "a new operation refreshes")
+	assert.Nil(t, model.Update(operationCheckedMsg("op")), "the same operation doesn't")
+}
@@ -0,0 +1,359 @@
+package status
+
+import (
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/jj/source"
+	"github.com/idursun/jjui/internal/ui/common/autocompletion"
+)
+
+var _ autocompletion.CompletionProvider = (*CompletionProvider)(nil)
+
+// valueKind tells where the values of an argument come from.
+type valueKind int
+
+const (
+	valueNone valueKind = iota
+	valueRevision
+	valueBookmark
+	valueRemote
+	valueFile
+	valueOperation
+)
+
+var valueSources = map[valueKind]source.Source{
+	valueRevision:  source.RevisionSource{},
+	valueBookmark:  source.BookmarkSource{},
+	valueRemote:    source.RemoteSource{},
+	valueFile:      source.FileSource{},
+	valueOperation: source.OperationSource{},
+}
+
+// flagValueKinds maps the long names of flags to what they take.
+var flagValueKinds = map[string]valueKind{
+	"revision":      valueRevision,
+	"revisions":     valueRevision,
+	"change":        valueRevision,
+	"from":          valueRevision,
+	"to":            valueRevision,
+	"into":          valueRevision,
+	"destination":   valueRevision,
+	"onto":          valueRevision,
+	"insert-after":  valueRevision,
+	"insert-before": valueRevision,
+	"after":         valueRevision,
+	"before":        valueRevision,
+	"source":        valueRevision,
+	"branch":        valueRevision,
+	"bookmark":      valueBookmark,
+	"remote":        valueRemote,
+	"at-op":         valueOperation,
+	"at-operation":  valueOperation,
+	"operation":     valueOperation,
+}
+
+// positionalValueKinds maps commands to what their positional arguments are.
+var positionalValueKinds = map[string]valueKind{
+	"abandon":            valueRevision,
+	"describe":           valueRevision,
+	"duplicate":          valueRevision,
+	"edit":               valueRevision,
+	"new":                valueRevision,
+	"parallelize":        valueRevision,
+	"show":               valueRevision,
+	"bookmark delete":    valueBookmark,
+	"bookmark forget":    valueBookmark,
+	"bookmark move":      valueBookmark,
+	"bookmark rename":    valueBookmark,
+	"bookmark set":       valueBookmark,
+	"bookmark track":     valueBookmark,
+	"bookmark untrack":   valueBookmark,
+	"git remote remove":  valueRemote,
+	"git remote rename":  valueRemote,
+	"git remote set-url": valueRemote,
+	"op abandon":         valueOperation,
+	"op restore":         valueOperation,
+	"op revert":          valueOperation,
+	"op show":            valueOperation,
+	"absorb":             valueFile,
+	"commit":             valueFile,
+	"diff":               valueFile,
+	"file annotate":      valueFile,
+	"file list":          valueFile,
+	"file show":          valueFile,
+	"file track":         valueFile,
+	"file untrack":       valueFile,
+	"interdiff":          valueFile,
+	"log":                valueFile,
+	"resolve":            valueFile,
+	"restore":            valueFile,
+	"split":              valueFile,
+	"squash":             valueFile,
+	"status":             valueFile,
+}
+
+// CompletionProvider completes the subcommands, flags and values of the jj
+// command typed in the exec prompt, using what jj tells shells about itself.
+// What it needs from jj is fetched by the command Fetch returns, until then
+// it completes without it.
+type CompletionProvider struct {
+	runner       source.Runner
+	spec         *source.CommandSpec
+	specLoaded   bool
+	specWanted   bool
+	values       map[valueKind][]source.Item
+	wanted       map[valueKind]bool
+	fetching     map[valueKind]bool
+	fetchingSpec bool
+	// generation tells the values of the previous prompt from the current ones.
+	generation int
+}
+
+// completionLoadedMsg carries what a Fetch of the provider loaded.
+type completionLoadedMsg struct {
+	generation int
+	spec       *source.CommandSpec
+	isSpec     bool
+	kind       valueKind
+	items      []source.Item
+}
+
+func NewCompletionProvider(runner source.Runner) *CompletionProvider {
+	return &CompletionProvider{runner: runner}
+}
+
+// Reset forgets the values loaded for the previous prompt, so that they are
+// fetched again when they are needed. The command metadata is kept.
+func (p *CompletionProvider) Reset() {
+	p.values = nil
+	p.wanted = nil
+	p.fetching = nil
+	p.generation++
+}
+
+// Fetch loads what the last completions asked for but didn't have, off the
+// UI loop.
+func (p *CompletionProvider) Fetch() tea.Cmd {
+	if p.runner == nil {
+		return nil
+	}
+	var cmds []tea.Cmd
+	runner, generation := p.runner, p.generation
+	if p.specWanted && !p.specLoaded && !p.fetchingSpec {
+		p.fetchingSpec = true
+		cmds = append(cmds, func() tea.Msg {
+			// without the metadata there is nothing to complete
+			spec, _ := source.LoadCommandSpec(runner)
+			return completionLoadedMsg{generation: generation, spec: spec, isSpec: true}
+		})
+	}
+	for kind := range p.wanted {
+		if p.fetching[kind] {
+			continue
+		}
+		if p.fetching == nil {
+			p.fetching = make(map[valueKind]bool)
+		}
+		p.fetching[kind] = true
+		s := valueSources[kind]
+		cmds = append(cmds, func() tea.Msg {
+			items, _ := s.Fetch(runner)
+			return completionLoadedMsg{generation: generation, kind: kind, items: items}
+		})
+	}
+	p.wanted = nil
+	return tea.Batch(cmds...)
+}
+
+// loaded keeps what a Fetch loaded and reports whether the completions should
+// be updated with it.
+func (p *CompletionProvider) loaded(msg completionLoadedMsg) bool {
+	if msg.isSpec {
+		p.spec, p.specLoaded, p.fetchingSpec = msg.spec, true, false
+		return true
+	}
+	if msg.generation != p.generation {
+		return false
+	}
+	if p.values == nil {
+		p.values = make(map[valueKind][]source.Item)
+	}
+	p.values[msg.kind] = msg.items
+	delete(p.fetching, msg.kind)
+	return true
+}
+
+// completionContext is where in the command the last token is.
+type completionContext struct {
+	command     *source.CommandSpec
+	path        []string
+	positionals int
+	// flag is the flag the last token is the value of, if any.
+	flag *source.FlagSpec
+}
+
+func (p *CompletionProvider) GetCompletions(input string) []string {
+	spec := p.loadSpec()
+	if spec == nil {
+		return nil
+	}
+	start, token := p.GetLastToken(input)
+	c := p.context(spec, input[:start])
+
+	var items []source.Item
+	switch {
+	case c.flag != nil:
+		items = p.flagValues(c, *c.flag)
+	case strings.HasPrefix(token, "-"):
+		items = flagItems(spec, c.command)
+	default:
+		if c.positionals == 0 {
+			for _, sub := range c.command.Subcommands {
+				items = append(items, source.Item{Name: sub.Name, Kind: source.KindCommand, SignatureHelp: sub.Help})
+			}
+		}
+		if len(items) == 0 {
+			items = append(items, c.command.Values...)
+			items = append(items, p.load(positionalValueKinds[strings.Join(c.path, " ")])...)
+		}
+	}
+
+	var completions []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if strings.HasPrefix(item.Name, token) && !seen[item.Name] {
+			seen[item.Name] = true
+			completions = append(completions, item.Name)
+		}
+	}
+	return completions
+}
+
+// GetSignatureHelp describes the flag whose value is being typed, or else the
+// command.
+func (p *CompletionProvider) GetSignatureHelp(input string) string {
+	spec := p.loadSpec()
+	if spec == nil {
+		return ""
+	}
+	start, _ := p.GetLastToken(input)
+	c := p.context(spec, input[:start])
+	switch {
+	case c.flag != nil && c.flag.Help != "":
+		return c.flag.Name() + ": " + c.flag.Help
+	case len(c.path) > 0 && c.command.Help != "":
+		return "jj " + strings.Join(c.path, " ") + ": " + c.command.Help
+	}
+	return ""
+}
+
+// GetLastToken returns the word being typed; the value of `--flag=value` is a
+// word of its own.
+func (p *CompletionProvider) GetLastToken(input string) (int, string) {
+	start := strings.LastIndexAny(input, " \t") + 1
+	token := input[start:]
+	if strings.HasPrefix(token, "--") {
+		if i := strings.Index(token, "="); i >= 0 {
+			return start + i + 1, token[i+1:]
+		}
+	}
+	return start, token
+}
+
+// context walks the words before the last token to find the command they
+// name and whether the last token is the value of a flag.
+func (p *CompletionProvider) context(spec *source.CommandSpec, before string) completionContext {
+	c := completionContext{command: spec}
+	words := strings.Fields(before)
+	// the value of `--flag=` is being typed
+	var pending string
+	if len(words) > 0 && strings.HasSuffix(before, "=") {
+		pending = strings.TrimSuffix(words[len(words)-1], "=")
+		words = words[:len(words)-1]
+	}
+	for i, word := range words {
+		if strings.HasPrefix(word, "-") && word != "-" {
+			name, _, hasValue := strings.Cut(word, "=")
+			flag, ok := lookupFlag(spec, c.command, name)
+			if !ok || !flag.TakesValue || hasValue {
+				continue
+			}
+			if i == len(words)-1 {
+				c.flag = &flag
+				break
+			}
+			// the next word is the value
+			words[i+1] = "-"
+			continue
+		}
+		if word == "-" {
+			continue
+		}
+		if sub := c.command.Subcommand(word); sub != nil && c.positionals == 0 {
+			c.command = sub
+			c.path = append(c.path, word)
+			continue
+		}
+		c.positionals++
+	}
+	if pending != "" {
+		if flag, ok := lookupFlag(spec, c.command, pending); ok {
+			c.flag = &flag
+		}
+	}
+	return c
+}
+
+// lookupFlag finds a flag of the command, or a global one.
+func lookupFlag(spec *source.CommandSpec, command *source.CommandSpec, arg string) (source.FlagSpec, bool) {
+	if flag, ok := command.Flag(arg); ok {
+		return flag, true
+	}
+	return spec.Flag(arg)
+}
+
+// flagItems lists the flags of the command followed by the global ones.
+func flagItems(spec *source.CommandSpec, command *source.CommandSpec) []source.Item {
+	var items []source.Item
+	for _, commands := range []*source.CommandSpec{command, spec} {
+		for _, flag := range commands.Flags {
+			items = append(items, source.Item{Name: flag.Name(), Kind: source.KindFlag, SignatureHelp: flag.Help})
+		}
+	}
+	return items
+}
+
+func (p *CompletionProvider) flagValues(c completionContext, flag source.FlagSpec) []source.Item {
+	if len(flag.Values) > 0 {
+		return flag.Values
+	}
+	kind := flagValueKinds[flag.Long]
+	if len(c.path) > 0 && c.path[0] == "op" && (flag.Long == "from" || flag.Long == "to") {
+		kind = valueOperation
+	}
+	return p.load(kind)
+}
+
+// loadSpec returns the command metadata, or asks for it to be fetched.
+func (p *CompletionProvider) loadSpec() *source.CommandSpec {
+	if !p.specLoaded {
+		p.specWanted = true
+	}
+	return p.spec
+}
+
+// load returns the values of the kind, which are fetched once per prompt.
+func (p *CompletionProvider) load(kind valueKind) []source.Item {
+	if _, ok := valueSources[kind]; !ok || p.runner == nil {
+		return nil
+	}
+	items, loaded := p.values[kind]
+	if !loaded {
+		if p.wanted == nil {
+			p.wanted = make(map[valueKind]bool)
+		}
+		p.wanted[kind] = true
+	}
+	return items
+}
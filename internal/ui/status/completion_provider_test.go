@@ -0,0 +1,132 @@
+package status
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const fishCompletion = `complete -c jj -n "__fish_jj_needs_command" -s R -l repository -d 'Path to repository to operate on' -r
+complete -c jj -n "__fish_jj_needs_command" -l at-op -d 'Operation to load the repo at' -r
+complete -c jj -n "__fish_jj_needs_command" -f -a "bookmark" -d 'Manage bookmarks'
+complete -c jj -n "__fish_jj_needs_command" -f -a "log" -d 'Show revision history'
+complete -c jj -n "__fish_jj_needs_command" -f -a "new" -d 'Create a new, empty change'
+complete -c jj -n "__fish_jj_using_subcommand log" -s r -l revisions -d 'Which revisions to show' -r
+complete -c jj -n "__fish_jj_using_subcommand log" -l reversed -d 'Show revisions in the opposite order'
+complete -c jj -n "__fish_jj_using_subcommand new" -s m -l message -d 'The change description to use' -r
+complete -c jj -n "__fish_jj_using_subcommand bookmark; and not __fish_seen_subcommand_from delete list" -f -a "delete" -d 'Delete an existing bookmark'
+complete -c jj -n "__fish_jj_using_subcommand bookmark; and not __fish_seen_subcommand_from delete list" -f -a "list" -d 'List bookmarks and their targets'
+`
+
+func newTestProvider() *CompletionProvider {
+	return NewCompletionProvider(func(args []string) ([]byte, error) {
+		switch args[0] {
+		case "util":
+			return []byte(fishCompletion), nil
+		case "log":
+			return []byte("kxyz\tfix the thing\nmabc\tadd a feature\n"), nil
+		case "op":
+			return []byte("0a1b\tsnapshot working copy\n"), nil
+		case "file":
+			return []byte("README.md\nmain.go\n"), nil
+		case "bookmark":
+			return []byte("main;.;false;false;false;abc\n"), nil
+		}
+		return nil, nil
+	})
+}
+
+// settle calls complete until the provider has fetched everything it asked
+// for and returns the last result.
+func settle[T any](provider *CompletionProvider, complete func() T) T {
+	for {
+		result := complete()
+		cmd := provider.Fetch()
+		if cmd == nil {
+			return result
+		}
+		deliver(provider, cmd())
+	}
+}
+
+func deliver(provider *CompletionProvider, msg tea.Msg) {
+	switch msg := msg.(type) {
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			deliver(provider, cmd())
+		}
+	case completionLoadedMsg:
+		provider.loaded(msg)
+	}
+}
+
+func TestCompletionProvider_FetchesOffTheUILoop(t *testing.T) {
+	ran := false
+	provider := NewCompletionProvider(func(args []string) ([]byte, error) {
+		ran = true
+		return []byte(fishCompletion), nil
+	})
+	assert.Nil(t, provider.GetCompletions(""))
+	assert.False(t, ran, "completing doesn't run jj")
+
+	deliver(provider, provider.Fetch()())
+	assert.Equal(t, []string{"bookmark", "log", "new"}, provider.GetCompletions(""))
+}
+
+func TestCompletionProvider_DropsValuesOfPreviousPrompt(t *testing.T) {
+	provider := newTestProvider()
+	settle(provider, func() []string { return provider.GetCompletions("") })
+	provider.GetCompletions("log -r ")
+	cmd := provider.Fetch()
+	provider.Reset()
+	deliver(provider, cmd())
+
+	assert.Nil(t, provider.GetCompletions("log -r "), "the values are fetched again")
+	assert.NotNil(t, provider.Fetch())
+}
+
+func TestCompletionProvider_GetCompletions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"subcommands", "", []string{"bookmark", "log", "new"}},
+		{"subcommand prefix", "b", []string{"bookmark"}},
+		{"nested subcommands", "bookmark ", []string{"delete", "list"}},
+		{"flags", "log --re", []string{"--revisions", "--reversed", "--repository"}},
+		{"revision flag value", "log -r ", []string{"kxyz", "mabc"}},
+		{"revision flag value after equals", "log --revisions=m", []string{"mabc"}},
+		{"global flag value", "--at-op ", []string{"0a1b"}},
+		{"flag value is skipped", "log -r k ", []string{"README.md", "main.go"}},
+		{"positional revisions", "new k", []string{"kxyz"}},
+		{"flag without known values", "new -m ", nil},
+		{"positional bookmarks", "bookmark delete m", []string{"main"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := newTestProvider()
+			assert.Equal(t, tc.expected, settle(provider, func() []string { return provider.GetCompletions(tc.input) }))
+		})
+	}
+}
+
+func TestCompletionProvider_GetLastToken(t *testing.T) {
+	provider := newTestProvider()
+	index, token := provider.GetLastToken("log --revisions=ma")
+	assert.Equal(t, 16, index)
+	assert.Equal(t, "ma", token)
+
+	index, token = provider.GetLastToken("log -r")
+	assert.Equal(t, 4, index)
+	assert.Equal(t, "-r", token)
+}
+
+func TestCompletionProvider_GetSignatureHelp(t *testing.T) {
+	provider := newTestProvider()
+	settle(provider, func() []string { return provider.GetCompletions("") })
+	assert.Equal(t, "--revisions: Which revisions to show", provider.GetSignatureHelp("log -r "))
+	assert.Equal(t, "jj bookmark delete: Delete an existing bookmark", provider.GetSignatureHelp("bookmark delete "))
+	assert.Empty(t, provider.GetSignatureHelp("lo"))
+}
@@ -1,6 +1,7 @@
 package status
 
 import (
+	"fmt"
 	"strings"
 
 	"charm.land/bubbles/v2/textinput"
@@ -12,6 +13,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/common/autocompletion"
 	"github.com/idursun/jjui/internal/ui/context"
 	"github.com/idursun/jjui/internal/ui/exec_process"
 	"github.com/idursun/jjui/internal/ui/fuzzy_files"
@@ -44,6 +46,10 @@ type Model struct {
 	styles          styles
 	statusExpanded  bool
 	statusTruncated bool
+	scriptRunning   bool
+	// completion completes the jj command being typed, when it is enabled.
+	completion         *autocompletion.AutoCompletionInput
+	completionProvider *CompletionProvider
 }
 
 type styles struct {
@@ -84,16 +90,26 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			m.input.SetValue(msg.Msg.Line)
 			m.input.CursorEnd()
 
-			return tea.Batch(m.fuzzy.Init(), m.input.Focus(), fuzzy_search.Search(m.input.Value()))
+			return tea.Batch(m.fuzzy.Init(), m.input.Focus(), fuzzy_search.Search(m.input.Value()), m.startCompletion(msg.Msg.Mode))
+		}
+		return nil
+	case completionLoadedMsg:
+		if m.completionProvider != nil && m.completionProvider.loaded(msg) {
+			return m.updateCompletion()
 		}
 		return nil
 	case intents.Intent:
-		switch msg.(type) {
+		switch intent := msg.(type) {
+		case intents.CompletionCycle:
+			if m.IsFocused() {
+				return m.cycleCompletion(intent.Reverse)
+			}
 		case intents.Cancel:
 			if m.IsFocused() {
 				editMode := m.mode
 				fuzzy := m.fuzzy
 				m.fuzzy = nil
+				m.completion = nil
 				m.focusKind = FocusNone
 				m.input.Reset()
 				if fuzzy != nil && strings.HasSuffix(editMode, "file") {
@@ -116,6 +132,7 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 				m.saveEditingSuggestions()
 
 				m.fuzzy = nil
+				m.completion = nil
 				m.focusKind = FocusNone
 				m.mode = ""
 				m.input.Reset()
@@ -141,8 +158,11 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			var cmd tea.Cmd
 			previous := m.input.Value()
 			m.input, cmd = m.input.Update(msg)
-			if m.fuzzy != nil && m.input.Value() != previous {
-				cmd = tea.Batch(cmd, fuzzy_search.Search(m.input.Value()))
+			if m.input.Value() != previous {
+				cmd = tea.Batch(cmd, m.updateCompletion())
+				if m.fuzzy != nil {
+					cmd = tea.Batch(cmd, fuzzy_search.Search(m.input.Value()))
+				}
 			}
 			return cmd
 		}
@@ -162,7 +182,61 @@ func (m *Model) StartExec(mode common.ExecMode) tea.Cmd {
 	m.focusKind = FocusInput
 
 	m.fuzzy = fuzzy_input.NewModel(&m.input, m.input.AvailableSuggestions())
-	return tea.Batch(m.fuzzy.Init(), m.input.Focus())
+	return tea.Batch(m.fuzzy.Init(), m.input.Focus(), m.startCompletion(mode))
+}
+
+// StartExecWithInput starts the exec prompt with line already typed in.
+func (m *Model) StartExecWithInput(mode common.ExecMode, line string) tea.Cmd {
+	cmd := m.StartExec(mode)
+	if line != "" {
+		m.input.SetValue(line)
+		m.input.CursorEnd()
+		cmd = tea.Batch(cmd, m.updateCompletion())
+	}
+	return cmd
+}
+
+// startCompletion sets up the completion of the jj command being typed, which
+// only the jj exec prompt has.
+func (m *Model) startCompletion(mode common.ExecMode) tea.Cmd {
+	m.completion = nil
+	if mode.Mode != common.ExecJJ.Mode || !config.Current.Suggest.Exec.Completion || m.context == nil {
+		return nil
+	}
+	if m.completionProvider == nil {
+		m.completionProvider = NewCompletionProvider(m.context.RunCommandImmediate)
+	}
+	m.completionProvider.Reset()
+	m.completion = autocompletion.New(m.completionProvider, autocompletion.WithStylePrefix("status"))
+	return m.updateCompletion()
+}
+
+// updateCompletion completes the input with what is loaded and fetches what
+// is missing.
+func (m *Model) updateCompletion() tea.Cmd {
+	if m.completion == nil {
+		return nil
+	}
+	m.completion.SetValue(m.input.Value())
+	return m.completionProvider.Fetch()
+}
+
+// cycleCompletion replaces the word being typed with the next completion.
+// Without completions, tab accepts the suggestion from the history as before.
+func (m *Model) cycleCompletion(reverse bool) tea.Cmd {
+	key := tea.KeyPressMsg{Code: tea.KeyTab}
+	if reverse {
+		key.Mod = tea.ModShift
+	}
+	if m.completion == nil {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(key)
+		return cmd
+	}
+	m.completion.Update(key)
+	m.input.SetValue(m.completion.Value())
+	m.input.CursorEnd()
+	return m.completionProvider.Fetch()
 }
 
 func (m *Model) StartQuickSearch() tea.Cmd {
@@ -194,6 +268,16 @@ func (m *Model) ViewRect(dl *render.DisplayContext, box layout.Box) {
 	modeWidth := max(10, len(m.mode)+2)
 	mode := m.styles.title.Width(modeWidth).Render(m.mode)
 
+	if queue := m.renderQueue(); queue != "" {
+		mode = lipgloss.JoinHorizontal(lipgloss.Left, mode, queue)
+		modeWidth += lipgloss.Width(queue)
+	}
+	if m.scriptRunning {
+		script := m.styles.shortcut.Render(" script running… press esc to abort")
+		mode = lipgloss.JoinHorizontal(lipgloss.Left, mode, script)
+		modeWidth += lipgloss.Width(script)
+	}
+
 	var statusLine string
 	if m.IsFocused() {
 		content := m.renderContent(width, modeWidth)
@@ -205,7 +289,35 @@ func (m *Model) ViewRect(dl *render.DisplayContext, box layout.Box) {
 
 	dl.AddDraw(box.R, statusLine, 0)
 	m.renderExpandedStatus(dl, box, width)
-	m.renderFuzzyOverlay(dl, box)
+	m.renderFuzzyOverlay(dl, box, m.renderCompletion(dl, box))
+}
+
+// renderCompletion draws the completions, or the help of what is being
+// typed, above the status line and returns how many lines it took.
+func (m *Model) renderCompletion(dl *render.DisplayContext, box layout.Box) int {
+	if m.completion == nil || box.R.Min.Y < 1 {
+		return 0
+	}
+	if len(m.completion.Suggestions) == 0 && m.completion.SignatureHelp == "" {
+		return 0
+	}
+	_, completions, _ := strings.Cut(m.completion.View(), "\n")
+	line := lipgloss.NewStyle().MaxWidth(box.R.Dx()).Render(strings.Split(completions, "\n")[0])
+	line = lipgloss.PlaceHorizontal(box.R.Dx(), 0, line, lipgloss.WithWhitespaceStyle(m.styles.text))
+	dl.AddDraw(layout.Rect(box.R.Min.X, box.R.Min.Y-1, box.R.Dx(), 1), line, render.ZFuzzyInput)
+	return 1
+}
+
+// renderQueue shows how many commands are waiting for their turn to run.
+func (m *Model) renderQueue() string {
+	if m.context == nil || m.context.CommandRunner == nil {
+		return ""
+	}
+	queued := m.context.QueuedCommands()
+	if queued == 0 {
+		return ""
+	}
+	return m.styles.shortcut.Render(fmt.Sprintf(" %d queued", queued))
 }
 
 // renderHelpBar renders the help keybindings bar when idle.
@@ -289,14 +401,20 @@ func (m *Model) renderExpandedStatusContent(dl *render.DisplayContext, box layou
 }
 
 // renderFuzzyOverlay handles fuzzy search overlay
-func (m *Model) renderFuzzyOverlay(dl *render.DisplayContext, box layout.Box) {
+func (m *Model) renderFuzzyOverlay(dl *render.DisplayContext, box layout.Box, reserved int) {
 	if m.fuzzy == nil {
 		return
 	}
-	overlayRect := layout.Rect(box.R.Min.X, 0, box.R.Dx(), box.R.Min.Y)
+	overlayRect := layout.Rect(box.R.Min.X, 0, box.R.Dx(), max(0, box.R.Min.Y-reserved))
 	m.fuzzy.ViewRect(dl, layout.Box{R: overlayRect})
 }
 
+// SetScriptRunning tells whether a Lua script is running, which can be
+// aborted with esc.
+func (m *Model) SetScriptRunning(running bool) {
+	m.scriptRunning = running
+}
+
 func (m *Model) SetHelp(entries []helpkeys.Entry) {
 	if len(m.entries) != len(entries) {
 		m.statusExpanded = false
@@ -9,6 +9,9 @@ import (
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/common"
 	"github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/layout"
+	"github.com/idursun/jjui/internal/ui/render"
+	"github.com/idursun/jjui/test"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -103,3 +106,20 @@ func TestStatus_Update_FileSearchFocusesAndTypingUpdatesInput(t *testing.T) {
 	m.Update(tea.KeyPressMsg{Text: "x", Code: 'x'})
 	assert.Equal(t, "x", m.InputValue())
 }
+
+func TestStatus_ViewRect_ShowsQueuedCommands(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	m := New(test.NewTestContext(commandRunner))
+	m.SetMode("normal")
+
+	view := func() string {
+		dl := render.NewDisplayContext()
+		box := layout.NewBox(layout.Rect(0, 0, 60, 1))
+		m.ViewRect(dl, box)
+		return dl.RenderToString(box.R.Dx(), box.R.Dy())
+	}
+	assert.NotContains(t, view(), "queued")
+
+	commandRunner.SetQueued(2)
+	assert.Contains(t, view(), "2 queued")
+}
@@ -0,0 +1,301 @@
+package terminal
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/vt"
+	"github.com/creack/pty"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/layout"
+	"github.com/idursun/jjui/internal/ui/render"
+)
+
+var _ common.ImmediateModel = (*Model)(nil)
+
+// errorLines is how many of the last lines on the screen describe the error
+// of a failed command.
+const errorLines = 5
+
+var lastID atomic.Int64
+
+type outputMsg struct {
+	id int64
+}
+
+type exitedMsg struct {
+	id  int64
+	err error
+}
+
+// Model is a pane that runs a command in a pseudo-terminal, so the graph stays
+// visible while the command is in use. Keys are sent to the command while the
+// pane has focus.
+type Model struct {
+	id       int64
+	title    string
+	request  common.RunInTerminalMsg
+	emulator *vt.SafeEmulator
+	pty      *os.File
+	updates  chan struct{}
+	exit     chan error
+	focused  bool
+	exited   bool
+	closed   bool
+	err      error
+	width    int
+	height   int
+
+	titleStyle  lipgloss.Style
+	dimmedStyle lipgloss.Style
+	errorStyle  lipgloss.Style
+	cursorStyle lipgloss.Style
+}
+
+func New(msg common.RunInTerminalMsg) *Model {
+	return &Model{
+		id:          lastID.Add(1),
+		title:       msg.Title,
+		request:     msg,
+		focused:     true,
+		updates:     make(chan struct{}, 1),
+		exit:        make(chan error, 1),
+		titleStyle:  common.DefaultPalette.Get("terminal title"),
+		dimmedStyle: common.DefaultPalette.Get("terminal dimmed"),
+		errorStyle:  common.DefaultPalette.Get("terminal error"),
+		cursorStyle: common.DefaultPalette.Get("terminal selected"),
+	}
+}
+
+// Start runs the command in a pseudo-terminal of the given size, which is the
+// size of the pane without its border.
+func (m *Model) Start(width int, height int) tea.Cmd {
+	m.width, m.height = max(1, width), max(1, height)
+	m.emulator = vt.NewSafeEmulator(m.width, m.height)
+
+	cmd := m.request.Cmd
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	// the pane understands what xterm does, whatever the outer terminal is
+	cmd.Env = append(cmd.Env, "TERM=xterm-256color")
+	ptmx, err := pty.StartWithSize(cmd, &pty.Winsize{Rows: uint16(m.height), Cols: uint16(m.width)})
+	if err != nil {
+		m.exited, m.closed = true, true
+		return m.done(err)
+	}
+	m.pty = ptmx
+	if m.request.Started != nil {
+		m.request.Started()
+	}
+
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := ptmx.Read(buf)
+			if n > 0 {
+				_, _ = m.emulator.Write(buf[:n])
+				select {
+				case m.updates <- struct{}{}:
+				default:
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	// the emulator answers the queries of the command, e.g. the cursor position
+	go func() {
+		_, _ = io.Copy(ptmx, m.emulator)
+	}()
+	go func() {
+		err := cmd.Wait()
+		<-read
+		_ = ptmx.Close()
+		// ends the copying of the answers; Close would race with Read
+		if input, ok := m.emulator.InputPipe().(io.Closer); ok {
+			_ = input.Close()
+		}
+		m.exit <- err
+	}()
+	return m.listen()
+}
+
+func (m *Model) listen() tea.Cmd {
+	id, updates, exit := m.id, m.updates, m.exit
+	return func() tea.Msg {
+		select {
+		case <-updates:
+			return outputMsg{id: id}
+		case err := <-exit:
+			return exitedMsg{id: id, err: err}
+		}
+	}
+}
+
+func (m *Model) done(err error) tea.Cmd {
+	if m.request.Done == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return m.request.Done(err)
+	}
+}
+
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Model) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case outputMsg:
+		if msg.id == m.id && !m.exited {
+			return m.listen()
+		}
+	case exitedMsg:
+		if msg.id != m.id {
+			return nil
+		}
+		m.exited = true
+		if msg.err != nil {
+			m.err = m.screenError(msg.err)
+		} else if !m.request.Hold {
+			m.closed = true
+		}
+		return m.done(m.err)
+	case tea.KeyPressMsg:
+		if m.exited {
+			// the output was read, give the space back to the graph
+			m.closed = true
+			return nil
+		}
+		if m.emulator != nil {
+			m.emulator.SendKey(uv.KeyPressEvent(uv.Key(msg.Key())))
+		}
+	case tea.PasteMsg:
+		if m.emulator != nil && m.focused && !m.exited {
+			m.emulator.Paste(msg.Content)
+		}
+	}
+	return nil
+}
+
+// screenError is the error of a failed command told by the last lines of its
+// screen, which still unwraps to how the command exited.
+type screenError struct {
+	text string
+	err  error
+}
+
+func (e *screenError) Error() string {
+	return e.text
+}
+
+func (e *screenError) Unwrap() error {
+	return e.err
+}
+
+// screenError returns the last lines on the screen as the error, since that
+// is where a failed command explains itself.
+func (m *Model) screenError(err error) error {
+	if m.emulator == nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimSpace(ansi.Strip(m.emulator.Render())), "\n")
+	lines = lines[max(0, len(lines)-errorLines):]
+	text := strings.TrimSpace(strings.Join(lines, "\n"))
+	if text == "" {
+		return err
+	}
+	return &screenError{text: text, err: err}
+}
+
+func (m *Model) ViewRect(dl *render.DisplayContext, box layout.Box) {
+	area := box.R
+	if area.Dx() < 3 || area.Dy() < 3 {
+		return
+	}
+	// the terminal is resized in Update, the pane only draws what fits
+	width, height := min(area.Dx()-2, m.width), min(area.Dy()-2, m.height)
+
+	title := m.titleStyle.Render(" " + m.title + " ")
+	switch {
+	case m.err != nil:
+		title += m.errorStyle.Render(" failed, press any key to close ")
+	case m.exited:
+		title += m.dimmedStyle.Render(" exited, press any key to close ")
+	}
+	borderStyle := m.titleStyle
+	if !m.focused {
+		borderStyle = m.dimmedStyle
+	}
+	border := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderStyle.GetForeground()).
+		Width(area.Dx()).
+		Height(area.Dy()).
+		Render("")
+	dl.AddDraw(area, border, render.ZPreview)
+	dl.AddDraw(layout.Rect(area.Min.X+2, area.Min.Y, max(0, area.Dx()-4), 1), title, render.ZPreview)
+
+	if m.emulator == nil {
+		return
+	}
+	inner := layout.Rect(area.Min.X+1, area.Min.Y+1, width, height)
+	dl.AddDraw(inner, m.emulator.Render(), render.ZPreview)
+	if m.focused && !m.exited {
+		cursor := m.emulator.CursorPosition()
+		if cursor.X < width && cursor.Y < height {
+			dl.AddPaint(layout.Rect(inner.Min.X+cursor.X, inner.Min.Y+cursor.Y, 1, 1), m.cursorStyle, render.ZPreview)
+		}
+	}
+}
+
+// Resize sets the size of the terminal, which is the size of the pane without
+// its border.
+func (m *Model) Resize(width int, height int) {
+	width, height = max(1, width), max(1, height)
+	if m.emulator == nil || (width == m.width && height == m.height) {
+		return
+	}
+	m.width, m.height = width, height
+	m.emulator.Resize(width, height)
+	if m.pty != nil && !m.exited {
+		_ = pty.Setsize(m.pty, &pty.Winsize{Rows: uint16(height), Cols: uint16(width)})
+	}
+}
+
+func (m *Model) SetFocused(focused bool) {
+	m.focused = focused
+	if m.emulator == nil || m.exited {
+		return
+	}
+	if focused {
+		m.emulator.Focus()
+	} else {
+		m.emulator.Blur()
+	}
+}
+
+func (m *Model) IsFocused() bool {
+	return m.focused
+}
+
+// Running reports whether the command is still running.
+func (m *Model) Running() bool {
+	return !m.exited
+}
+
+// Closed reports whether the pane is done and can be removed.
+func (m *Model) Closed() bool {
+	return m.closed
+}
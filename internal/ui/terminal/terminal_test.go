@@ -0,0 +1,127 @@
+package terminal
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type doneMsg struct {
+	err error
+}
+
+// runToExit feeds the pane its own messages until the command exits and
+// returns what the pane reported through Done.
+func runToExit(t *testing.T, m *Model, cmd tea.Cmd) tea.Msg {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for cmd != nil {
+		msgs := make(chan tea.Msg, 1)
+		go func() { msgs <- cmd() }()
+		select {
+		case msg := <-msgs:
+			if _, ok := msg.(doneMsg); ok {
+				return msg
+			}
+			cmd = m.Update(msg)
+		case <-deadline:
+			t.Fatal("command didn't exit")
+		}
+	}
+	return nil
+}
+
+func newPane(t *testing.T, script string, hold bool) *Model {
+	if runtime.GOOS == "windows" {
+		t.Skip("pseudo-terminals aren't supported on windows")
+	}
+	return New(common.RunInTerminalMsg{
+		Title: "test",
+		Cmd:   exec.Command("sh", "-c", script),
+		Done:  func(err error) tea.Msg { return doneMsg{err: err} },
+		Hold:  hold,
+	})
+}
+
+func TestTerminal_ClosesWhenCommandSucceeds(t *testing.T) {
+	m := newPane(t, "printf hello", false)
+
+	msg := runToExit(t, m, m.Start(20, 5))
+
+	require.IsType(t, doneMsg{}, msg)
+	assert.NoError(t, msg.(doneMsg).err)
+	assert.False(t, m.Running())
+	assert.True(t, m.Closed())
+}
+
+func TestTerminal_HoldsOutputUntilKeyPress(t *testing.T) {
+	m := newPane(t, "printf hello", true)
+
+	runToExit(t, m, m.Start(20, 5))
+
+	assert.False(t, m.Closed())
+	rendered := test.RenderImmediate(m, 30, 7)
+	assert.Contains(t, rendered, "hello")
+	assert.Contains(t, rendered, "exited")
+
+	m.Update(tea.KeyPressMsg{Code: 'q', Text: "q"})
+	assert.True(t, m.Closed())
+}
+
+func TestTerminal_FailedCommandReportsScreen(t *testing.T) {
+	m := newPane(t, "echo 'Error: no such revision'; exit 1", false)
+
+	msg := runToExit(t, m, m.Start(40, 5))
+
+	require.IsType(t, doneMsg{}, msg)
+	assert.EqualError(t, msg.(doneMsg).err, "Error: no such revision")
+	var exitError *exec.ExitError
+	require.ErrorAs(t, msg.(doneMsg).err, &exitError, "the exit code is kept for after_command")
+	assert.Equal(t, 1, exitError.ExitCode())
+	assert.False(t, m.Closed(), "failed commands keep the pane open")
+}
+
+func TestTerminal_SendsKeysToCommand(t *testing.T) {
+	m := newPane(t, "read line; printf \"got %s\" \"$line\"", true)
+
+	cmd := m.Start(30, 5)
+	for _, r := range "abc" {
+		m.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	runToExit(t, m, cmd)
+
+	assert.Contains(t, test.RenderImmediate(m, 40, 7), "got abc")
+}
+
+func TestTerminal_ReportsStartBeforeExit(t *testing.T) {
+	m := newPane(t, "read line", false)
+	started := false
+	m.request.Started = func() { started = true }
+
+	cmd := m.Start(20, 5)
+	assert.True(t, started, "the command runs until it reads a line")
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	runToExit(t, m, cmd)
+}
+
+func TestTerminal_ResizesOutsideRendering(t *testing.T) {
+	m := newPane(t, "read line; stty size", true)
+
+	cmd := m.Start(20, 5)
+	test.RenderImmediate(m, 40, 12)
+	assert.Equal(t, 20, m.width, "rendering keeps the size")
+
+	m.Resize(38, 10)
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	runToExit(t, m, cmd)
+
+	assert.Contains(t, test.RenderImmediate(m, 40, 12), "10 38")
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/idursun/jjui/internal/ui/helpkeys"
 	"github.com/idursun/jjui/internal/ui/intents"
 	"github.com/idursun/jjui/internal/ui/layout"
+	"github.com/idursun/jjui/internal/ui/pager"
 	"github.com/idursun/jjui/internal/ui/password"
 	"github.com/idursun/jjui/internal/ui/render"
 
@@ -27,6 +29,7 @@ import (
 	"github.com/idursun/jjui/internal/ui/bookmarks"
 	"github.com/idursun/jjui/internal/ui/choose"
 	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/console"
 	"github.com/idursun/jjui/internal/ui/context"
 	"github.com/idursun/jjui/internal/ui/diff"
 	"github.com/idursun/jjui/internal/ui/exec_process"
@@ -34,12 +37,15 @@ import (
 	"github.com/idursun/jjui/internal/ui/help"
 
 	"github.com/idursun/jjui/internal/ui/input"
+	"github.com/idursun/jjui/internal/ui/notifications"
 	"github.com/idursun/jjui/internal/ui/oplog"
+	"github.com/idursun/jjui/internal/ui/plugins"
 	"github.com/idursun/jjui/internal/ui/preview"
 	"github.com/idursun/jjui/internal/ui/redo"
 	"github.com/idursun/jjui/internal/ui/revisions"
 	"github.com/idursun/jjui/internal/ui/revset"
 	"github.com/idursun/jjui/internal/ui/status"
+	"github.com/idursun/jjui/internal/ui/terminal"
 	"github.com/idursun/jjui/internal/ui/undo"
 )
 
@@ -55,25 +61,43 @@ type Model struct {
 	password         *password.Model
 	context          *context.MainContext
 	scriptRunner     *scripting.Runner
+	slowScript       *scripting.Runner
+	hookRunners      []*scripting.Runner
+	redecorate       bool
 	sequenceHelp     []helpkeys.Entry
 	sequenceAutoOpen bool
 	resolver         *dispatch.Resolver
 	stacked          common.StackedModel
+	console          *console.Model
+	terminal         *terminal.Model
 	displayContext   *render.DisplayContext
 	width            int
 	height           int
 	revisionsSplit   *split
 	activeSplit      *split
+	runningCommands  map[int]common.CommandRunningMsg
+	fetching         bool
 }
 
 type triggerAutoRefreshMsg struct{}
 
+type scriptSlowMsg struct {
+	runner *scripting.Runner
+}
+
+// scriptIndicatorDelay is how long a script runs before the status tells
+// that it is running, so that quick scripts don't flicker it.
+const scriptIndicatorDelay = 300 * time.Millisecond
+
+type triggerAutoFetchMsg struct{}
+
 const (
-	scopeUi keybindings.Scope = "ui"
+	scopeUi       keybindings.Scope = "ui"
+	scopeTerminal keybindings.Scope = "terminal"
 )
 
 func (m *Model) Init() tea.Cmd {
-	return tea.Batch(m.revisions.Init(), m.scheduleAutoRefresh())
+	return tea.Batch(m.revisions.Init(), m.scheduleAutoRefresh(), m.scheduleAutoFetch(), scripting.WaitForTimers(m.context), scripting.ReportPluginErrors(m.context))
 }
 
 func (m *Model) closeTopLayer(msg common.CloseViewMsg) (tea.Cmd, bool) {
@@ -111,6 +135,7 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 		}
 		return nil
 	case tea.FocusMsg:
+		m.flash.Update(msg)
 		if m.state == common.Ready {
 			return common.RefreshAndKeepSelections
 		}
@@ -142,7 +167,7 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			}
 			m.clearSequenceStatusHelp()
 			if result.LuaScript != "" {
-				return luaCmd(result.LuaScript)
+				return luaCmd(result.LuaAction, result.LuaScript)
 			}
 			if result.Intent != nil {
 				return m.routeIntent(result.Owner, result.Intent)
@@ -160,26 +185,73 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 		return exec_process.ExecLine(m.context, msg)
 	case common.ExecProcessCompletedMsg:
 		cmds = append(cmds, common.Refresh)
+	case common.RunInTerminalMsg:
+		return m.startTerminal(msg)
 	case common.UpdateRevisionsSuccessMsg:
 		m.state = common.Ready
+		cmds = append(cmds, m.startHooks(scripting.RunRefreshCompletedHooks(m.context)))
+		cmds = append(cmds, scripting.LoadDecorations(m.context, m.redecorate))
+		m.redecorate = false
+	case common.RefreshMsg:
+		m.redecorate = true
+	case scripting.DecorationsLoadedMsg:
+		return scripting.ApplyDecorations(m.context, msg)
+	case common.SelectionChangedMsg:
+		cmds = append(cmds, m.startHooks(scripting.RunSelectionChangedHooks(m.context, msg.Item)))
+	case scripting.BeforeCommandMsg:
+		return m.startHooks(scripting.RunBeforeCommandHooks(m.context, msg))
+	case scripting.ConsoleResultMsg:
+		// the result of input that waited is shown even if the console was
+		// closed in the meantime
+		if m.stacked == nil {
+			m.stacked = m.console
+		}
+		return m.console.Update(msg)
+	case console.WaitingMsg:
+		return m.startHooks(msg.Runner, nil)
+	case scripting.TimerFiredMsg:
+		return tea.Batch(m.startHooks(scripting.RunTimer(m.context, msg)), scripting.WaitForTimers(m.context))
 	case triggerAutoRefreshMsg:
 		return tea.Batch(m.scheduleAutoRefresh(), func() tea.Msg {
 			return common.AutoRefreshMsg{}
 		})
+	case triggerAutoFetchMsg:
+		// skip this round rather than racing a command the user started
+		if m.fetching || len(m.runningCommands) > 0 {
+			return m.scheduleAutoFetch()
+		}
+		m.fetching = true
+		return tea.Batch(m.scheduleAutoFetch(), git.BackgroundFetch(m.context))
+	case git.BackgroundFetchMsg:
+		m.fetching = false
+		return m.handleBackgroundFetch(msg)
+	case common.CommandRunningMsg:
+		if !msg.Background {
+			m.runningCommands[msg.ID] = msg
+		}
+	case common.CommandCompletedMsg:
+		if running, ok := m.runningCommands[msg.ID]; ok {
+			cmds = append(cmds, m.startHooks(scripting.RunAfterCommandHooks(m.context, running.Command, running.Args, msg)))
+		}
+		delete(m.runningCommands, msg.ID)
 	case common.UpdateRevSetMsg:
+		previous := m.context.CurrentRevset
 		m.context.CurrentRevset = string(msg)
 		if m.context.CurrentRevset == "" {
 			m.context.CurrentRevset = m.context.DefaultRevset
 		}
 		m.revsetModel.AddToHistory(m.context.CurrentRevset)
 		m.revsetModel.Update(msg)
+		if m.context.CurrentRevset != previous {
+			return tea.Batch(common.Refresh, m.startHooks(scripting.RunRevsetChangedHooks(m.context, previous)))
+		}
 		return common.Refresh
 	case common.RunLuaScriptMsg:
 		if m.scriptRunner != nil && !m.scriptRunner.Done() {
 			err := fmt.Errorf("lua script is already running")
 			return intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err})
 		}
-		runner, cmd, err := scripting.RunScript(m.context, msg.Script)
+		runner, cmd, err := scripting.RunAction(m.context, msg.Action, msg.Script)
 		if err != nil {
 			return func() tea.Msg {
 				return common.CommandCompletedMsg{Err: err}
@@ -188,8 +260,16 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 		m.scriptRunner = runner
 		if cmd == nil && (runner == nil || runner.Done()) {
 			m.scriptRunner = nil
+			return nil
 		}
-		return cmd
+		return tea.Batch(cmd, tea.Tick(scriptIndicatorDelay, func(time.Time) tea.Msg {
+			return scriptSlowMsg{runner: runner}
+		}))
+	case scriptSlowMsg:
+		if msg.runner == m.scriptRunner {
+			m.slowScript = msg.runner
+		}
+		return nil
 	case common.DispatchActionMsg:
 		if actionmeta.IsBuiltInAction(msg.Action) {
 			if err := actionmeta.ValidateBuiltInActionArgs(msg.Action, msg.Args); err != nil {
@@ -204,7 +284,7 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			result = m.resolver.ResolveAction(action, msg.Args)
 		}
 		if result.LuaScript != "" {
-			return luaCmd(result.LuaScript)
+			return luaCmd(result.LuaAction, result.LuaScript)
 		}
 		if result.Intent != nil {
 			return m.routeIntent(result.Owner, result.Intent)
@@ -224,6 +304,9 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 		return m.stacked.Init()
 	case input.SelectedMsg, input.CancelledMsg:
 		m.stacked = nil
+	case common.ShowPagerMsg:
+		m.stacked = pager.New(msg.Title, msg.Content)
+		return m.stacked.Init()
 	case common.ShowPreview:
 		m.previewModel.SetVisible(bool(msg))
 		cmds = append(cmds, common.SelectionChanged(m.context.SelectedItem))
@@ -250,6 +333,9 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		if m.terminal != nil {
+			m.terminal.Resize(m.width-2, m.terminalHeight()-2)
+		}
 	}
 
 	// Unhandled key messages go to the main view (oplog or revisions)
@@ -274,6 +360,10 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 		cmds = append(cmds, m.stacked.Update(msg))
 	}
 
+	if m.terminal != nil {
+		cmds = append(cmds, m.updateTerminal(msg))
+	}
+
 	if m.scriptRunner != nil {
 		if cmd := m.scriptRunner.HandleMsg(msg); cmd != nil {
 			cmds = append(cmds, cmd)
@@ -282,6 +372,10 @@ func (m *Model) Update(msg tea.Msg) tea.Cmd {
 			m.scriptRunner = nil
 		}
 	}
+	for _, runner := range m.hookRunners {
+		cmds = append(cmds, runner.HandleMsg(msg))
+	}
+	m.hookRunners = slices.DeleteFunc(m.hookRunners, (*scripting.Runner).Done)
 
 	if m.oplog != nil {
 		cmds = append(cmds, m.oplog.Update(msg))
@@ -308,10 +402,14 @@ func (m *Model) updateStatus() {
 		m.status.SetMode(mode)
 	}
 	m.status.SetHelp(entries)
+	// a script waiting for a view is ended by closing the view instead
+	m.status.SetScriptRunning(m.scriptRunner != nil && m.scriptRunner == m.slowScript && m.stacked == nil)
 }
 
 func (m *Model) statusMode() string {
 	switch {
+	case m.terminalFocused():
+		return "terminal"
 	case m.commandHistoryOpen():
 		return "history"
 	case m.stacked != nil:
@@ -327,6 +425,14 @@ func (m *Model) statusMode() string {
 	}
 }
 
+// startHooks keeps the hooks that wait for a message until they are done.
+func (m *Model) startHooks(runner *scripting.Runner, cmd tea.Cmd) tea.Cmd {
+	if runner != nil && !runner.Done() {
+		m.hookRunners = append(m.hookRunners, runner)
+	}
+	return cmd
+}
+
 func (m *Model) UpdatePreviewPosition() {
 	if m.previewModel.AutoPosition() {
 		atBottom := m.height >= m.width/2
@@ -346,6 +452,12 @@ func (m *Model) View() string {
 	box := layout.NewBox(layout.Rect(0, 0, m.width, m.height))
 	screenBuf := render.NewScreenBuffer(m.width, m.height)
 
+	if m.terminal != nil {
+		var pane layout.Box
+		box, pane = box.CutBottom(m.terminalHeight())
+		m.terminal.ViewRect(m.displayContext, pane)
+	}
+
 	if m.diff != nil {
 		m.renderDiffLayout(box)
 	} else {
@@ -364,7 +476,8 @@ func (m *Model) View() string {
 		m.stacked.ViewRect(m.displayContext, box)
 	}
 
-	if !m.commandHistoryOpen() {
+	// both list the flash messages themselves
+	if !m.commandHistoryOpen() && !m.notificationsOpen() {
 		m.flash.ViewRect(m.displayContext, box)
 	}
 
@@ -435,6 +548,36 @@ func (m *Model) initSplit() {
 	)
 }
 
+func (m *Model) scheduleAutoFetch() tea.Cmd {
+	interval := config.Current.Git.AutoFetchInterval
+	if interval > 0 {
+		return tea.Tick(time.Duration(interval)*time.Second, func(time.Time) tea.Msg {
+			return triggerAutoFetchMsg{}
+		})
+	}
+	return nil
+}
+
+func (m *Model) handleBackgroundFetch(msg git.BackgroundFetchMsg) tea.Cmd {
+	if msg.Err != nil {
+		err := fmt.Errorf("background fetch failed: %w", msg.Err)
+		return intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err})
+	}
+	if len(msg.Changes) == 0 {
+		return nil
+	}
+	text := "Fetched: " + jj.SummarizeRemoteBookmarkChanges(msg.Changes)
+	if key := bindingKeyHint("ui.rebase_onto_trunk"); key != "" {
+		text += fmt.Sprintf("\nPress %s to rebase your stacks onto trunk()", key)
+	}
+	return tea.Batch(intents.Invoke(intents.AddMessage{Text: text}), common.RefreshAndKeepSelections)
+}
+
+// bindingKeyHint returns the first key bound to the action, or an empty string.
+func bindingKeyHint(action string) string {
+	return config.GetBindingKeyHint(config.Current, action)
+}
+
 func (m *Model) scheduleAutoRefresh() tea.Cmd {
 	interval := config.Current.UI.AutoRefreshInterval
 	if interval > 0 {
@@ -502,7 +645,12 @@ func (m *Model) handleUiRootIntent(intent intents.Intent) (tea.Cmd, bool) {
 		if !m.revisions.InNormalMode() {
 			return nil, true
 		}
-		return m.status.StartExec(common.ExecJJ), true
+		return m.status.StartExecWithInput(common.ExecJJ, intent.Line), true
+	case intents.TerminalToggleFocus:
+		if m.terminal != nil {
+			m.terminal.SetFocused(!m.terminal.IsFocused())
+		}
+		return nil, true
 	case intents.ExecShell:
 		if !m.revisions.InNormalMode() {
 			return nil, true
@@ -534,6 +682,12 @@ func (m *Model) handleUiRootIntent(intent intents.Intent) (tea.Cmd, bool) {
 		model := bookmarks.NewModel(m.context, current, changeIds)
 		m.stacked = model
 		return m.stacked.Init(), true
+	case intents.OpenBookmarkCleanup:
+		if !m.revisions.InNormalMode() {
+			return nil, true
+		}
+		m.stacked = bookmarks.NewCleanupModel(m.context)
+		return m.stacked.Init(), true
 	case intents.OpenGit:
 		if !m.revisions.InNormalMode() {
 			return nil, true
@@ -541,6 +695,34 @@ func (m *Model) handleUiRootIntent(intent intents.Intent) (tea.Cmd, bool) {
 		model := git.NewModel(m.context, m.revisions.SelectedRevisions())
 		m.stacked = model
 		return m.stacked.Init(), true
+	case intents.CancelCommand:
+		if id, ok := m.flash.LatestPendingCommand(); ok {
+			if err := m.context.CancelCommand(id); err != nil {
+				return intents.Invoke(intents.AddMessage{Text: err.Error(), Err: err}), true
+			}
+		}
+		return nil, true
+	case intents.RecoverCommand:
+		failed, ok := m.flash.TakeFailedCommand()
+		if !ok {
+			return nil, true
+		}
+		if failed.Recovery.Kind == jj.RecoveryReload {
+			return common.RefreshAndKeepSelections, true
+		}
+		args := jj.Args(failed.Recovery.RetryArgs(failed.Args)...)
+		if failed.Interactive {
+			return m.context.RunInteractiveCommand(args, common.Refresh), true
+		}
+		return m.context.RunCommand(args, common.Refresh), true
+	case intents.RebaseOntoTrunk:
+		if !m.revisions.InNormalMode() {
+			return nil, true
+		}
+		return m.context.RunCommand(jj.RebaseStacksOntoTrunk(), common.Refresh), true
+	case intents.OpenGitRemotes:
+		m.stacked = git.NewRemotesModel(m.context)
+		return m.stacked.Init(), true
 	case intents.OpLogOpen:
 		if !m.revisions.InNormalMode() {
 			return nil, true
@@ -613,14 +795,26 @@ func (m *Model) handleUiRootIntent(intent intents.Intent) (tea.Cmd, bool) {
 		}
 		m.stacked = commandhistory.New(m.context, m.flash)
 		return m.stacked.Init(), true
+	case intents.OpenNotifications:
+		m.stacked = notifications.New(m.flash)
+		return m.stacked.Init(), true
+	case intents.OpenPlugins:
+		m.stacked = plugins.New(scripting.Plugins(m.context))
+		return m.stacked.Init(), true
+	case intents.OpenConsole:
+		if m.console == nil {
+			m.console = console.New(m.context)
+		}
+		m.stacked = m.console
+		return m.stacked.Init(), true
 	default:
 		return nil, false
 	}
 }
 
-func luaCmd(script string) tea.Cmd {
+func luaCmd(action string, script string) tea.Cmd {
 	return func() tea.Msg {
-		return common.RunLuaScriptMsg{Script: script}
+		return common.RunLuaScriptMsg{Action: action, Script: script}
 	}
 }
 
@@ -645,6 +839,12 @@ func (m *Model) routeCancel(owner string, cancel intents.Cancel) tea.Cmd {
 		return common.Close
 	}
 
+	if m.scriptRunner != nil {
+		cmd := m.scriptRunner.Abort()
+		m.scriptRunner = nil
+		return cmd
+	}
+
 	if m.shouldRouteCancelToRevisions() {
 		if cmd, handled := m.revisions.HandleDispatchedAction(keybindings.Action("ui.cancel"), nil); handled {
 			return cmd
@@ -695,8 +895,14 @@ func (m *Model) routeIntentByOwner(owner string, intent intents.Intent) (tea.Cmd
 			return m.oplog.Update(intent), true
 		}
 	case actions.OwnerCommandHistory,
+		actions.OwnerNotifications,
+		actions.OwnerPlugins,
+		actions.OwnerConsole,
+		actions.OwnerPager,
 		actions.OwnerBookmarks,
+		actions.OwnerBookmarksCleanup,
 		actions.OwnerGit,
+		actions.OwnerGitRemotes,
 		actions.OwnerChoose,
 		actions.OwnerUndo,
 		actions.OwnerRedo,
@@ -735,6 +941,10 @@ func (m *Model) handleUnmatched(msg tea.KeyMsg) tea.Cmd {
 		return nil
 	}
 
+	if m.terminalFocused() {
+		return m.updateTerminal(msg)
+	}
+
 	if m.status.IsFocused() {
 		return m.status.Update(msg)
 	}
@@ -818,6 +1028,9 @@ func (m *Model) alwaysOnScopes() []keybindings.Scope {
 }
 
 func (m *Model) dispatchScopes() []keybindings.Scope {
+	if m.terminalFocused() {
+		return []keybindings.Scope{scopeTerminal}
+	}
 	if m.commandHistoryOpen() {
 		return []keybindings.Scope{keybindings.Scope(actions.OwnerCommandHistory)}
 	}
@@ -842,6 +1055,46 @@ func (m *Model) commandHistoryOpen() bool {
 	return m.stacked != nil && m.stacked.StackedActionOwner() == actions.OwnerCommandHistory
 }
 
+func (m *Model) notificationsOpen() bool {
+	return m.stacked != nil && m.stacked.StackedActionOwner() == actions.OwnerNotifications
+}
+
+// startTerminal runs the command in the terminal pane. The pane runs one
+// command at a time, a command started while it is busy suspends the UI as
+// it does without the pane.
+func (m *Model) startTerminal(msg common.RunInTerminalMsg) tea.Cmd {
+	if m.terminal != nil && m.terminal.Running() {
+		return tea.ExecProcess(msg.Cmd, msg.Done)
+	}
+	m.terminal = terminal.New(msg)
+	// the border of the pane takes two columns and two lines
+	cmd := m.terminal.Start(m.width-2, m.terminalHeight()-2)
+	if m.terminal.Closed() {
+		m.terminal = nil
+	}
+	return cmd
+}
+
+func (m *Model) updateTerminal(msg tea.Msg) tea.Cmd {
+	cmd := m.terminal.Update(msg)
+	if m.terminal.Closed() {
+		m.terminal = nil
+	}
+	return cmd
+}
+
+func (m *Model) terminalFocused() bool {
+	return m.terminal != nil && m.terminal.IsFocused()
+}
+
+func (m *Model) terminalHeight() int {
+	percentage := config.Current.UI.Terminal.HeightPercentage
+	if percentage <= 0 || percentage >= 100 {
+		percentage = 40
+	}
+	return max(3, int(float64(m.height)*percentage/100))
+}
+
 var _ tea.Model = (*wrapper)(nil)
 
 type (
@@ -896,13 +1149,14 @@ func NewUI(c *context.MainContext) *Model {
 	revsetModel := revset.New(c)
 
 	ui := &Model{
-		context:      c,
-		state:        common.Loading,
-		revisions:    revisionsModel,
-		previewModel: previewModel,
-		status:       statusModel,
-		revsetModel:  revsetModel,
-		flash:        flashView,
+		context:         c,
+		state:           common.Loading,
+		revisions:       revisionsModel,
+		previewModel:    previewModel,
+		status:          statusModel,
+		revsetModel:     revsetModel,
+		flash:           flashView,
+		runningCommands: make(map[int]common.CommandRunningMsg),
 	}
 	ui.initResolver()
 	ui.initSplit()
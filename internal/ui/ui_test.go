@@ -1,7 +1,10 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"os/exec"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -31,7 +34,7 @@ import (
 func dispatchAction(model *Model, action keybindings.Action, args map[string]any) (tea.Cmd, bool) {
 	result := model.resolver.ResolveAction(action, args)
 	if result.LuaScript != "" {
-		return luaCmd(result.LuaScript), true
+		return luaCmd(result.LuaAction, result.LuaScript), true
 	}
 	if result.Intent != nil {
 		return model.routeIntent(result.Owner, result.Intent), true
@@ -217,6 +220,31 @@ func Test_HandleDispatchedAction_UsesFlashScopeWhenVisible(t *testing.T) {
 	assert.False(t, model.commandHistoryOpen())
 }
 
+func Test_Update_TerminalPaneTakesKeysUntilFocusToggled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pseudo-terminals aren't supported on windows")
+	}
+	commandRunner := test.NewTestCommandRunner(t)
+	ctx := test.NewTestContext(commandRunner)
+	model := NewUI(ctx)
+	model.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+
+	model.Update(common.RunInTerminalMsg{Title: "sh", Cmd: exec.Command("sh", "-c", "read line")})
+	require.NotNil(t, model.terminal)
+	t.Cleanup(func() {
+		model.terminal.SetFocused(true)
+		model.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	})
+	assert.Equal(t, []keybindings.Scope{scopeTerminal}, model.dispatchScopes())
+	assert.Equal(t, "terminal", model.statusMode())
+
+	cmd, handled := dispatchAction(model, keybindings.Action("ui.toggle_terminal_focus"), nil)
+	assert.True(t, handled)
+	assert.Nil(t, cmd)
+	assert.False(t, model.terminal.IsFocused())
+	assert.NotContains(t, model.dispatchScopes(), scopeTerminal)
+}
+
 // this test verifies that when `git` is activated and `status` is expanded,
 // pressing `esc` closes expanded `status`
 func Test_GitWithExpandedStatus_EscClosesStackedFirst(t *testing.T) {
@@ -863,6 +891,41 @@ func Test_Update_LuaChooseEscViaUiCancelFinishesScript(t *testing.T) {
 	assert.Nil(t, model.scriptRunner, "script should finish after choose cancel")
 }
 
+func Test_Update_EscAbortsWaitingLuaScript(t *testing.T) {
+	origBindings := config.Current.Bindings
+	defer func() {
+		config.Current.Bindings = origBindings
+	}()
+	config.Current.Bindings = []config.BindingConfig{
+		{Action: "ui.cancel", Scope: "ui", Key: config.StringList{"esc"}},
+	}
+
+	commandRunner := test.NewTestCommandRunner(t)
+	defer commandRunner.Verify()
+
+	ctx := test.NewTestContext(commandRunner)
+	require.NoError(t, scripting.InitVM(ctx))
+	defer scripting.CloseVM(ctx)
+	model := NewUI(ctx)
+	model.width, model.height = 120, 20
+
+	cmd := model.Update(common.RunLuaScriptMsg{Action: "slow", Script: `wait_refresh()`})
+	require.NotNil(t, cmd)
+	test.SimulateModel(model, cmd)
+	require.NotNil(t, model.scriptRunner, "script should wait for a refresh")
+	assert.Contains(t, model.View(), "script running… press esc to abort")
+
+	cmd = model.Update(tea.KeyPressMsg{Code: tea.KeyEsc})
+	require.NotNil(t, cmd)
+	test.SimulateModel(model, cmd)
+
+	assert.Nil(t, model.scriptRunner, "esc should abort the script")
+	assert.NotContains(t, model.View(), "script running")
+	if notifications := model.flash.Notifications(); assert.NotEmpty(t, notifications) {
+		assert.Equal(t, "action slow: script aborted", notifications[len(notifications)-1].Text)
+	}
+}
+
 func Test_Update_LuaActionRejectsInvalidBuiltInArgs(t *testing.T) {
 	commandRunner := test.NewTestCommandRunner(t)
 	defer commandRunner.Verify()
@@ -1080,3 +1143,62 @@ func Test_Update_SetBookmarkTypingDoesNotTogglePreview(t *testing.T) {
 	test.SimulateModel(model, test.Type("p"))
 	assert.True(t, model.previewModel.Visible(), "typing in set_bookmark should not toggle preview")
 }
+
+func Test_Update_AutoFetchSkippedWhileCommandRunning(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.BookmarkListAll()).SetOutput([]byte("main;origin;true;false;false;1a"))
+	commandRunner.Expect(jj.GitFetch())
+	defer commandRunner.Verify()
+
+	ctx := test.NewTestContext(commandRunner)
+	model := NewUI(ctx)
+
+	model.Update(common.CommandRunningMsg{ID: 1, Command: "jj describe"})
+	model.Update(triggerAutoFetchMsg{})
+	assert.False(t, model.fetching, "fetch should wait for the running command")
+
+	model.Update(common.CommandCompletedMsg{ID: 1})
+	cmd := model.Update(triggerAutoFetchMsg{})
+	assert.True(t, model.fetching)
+
+	msg := cmd()
+	fetched, ok := msg.(git.BackgroundFetchMsg)
+	require.True(t, ok)
+	assert.Empty(t, fetched.Changes)
+	assert.Nil(t, model.Update(fetched), "unchanged bookmarks produce no notification")
+	assert.False(t, model.fetching)
+}
+
+func Test_BindingKeyHint(t *testing.T) {
+	origBindings := config.Current.Bindings
+	defer func() {
+		config.Current.Bindings = origBindings
+	}()
+	config.Current.Bindings = []config.BindingConfig{
+		{Action: "ui.rebase_onto_trunk", Scope: "revisions", Key: config.StringList{"shift+t"}},
+		{Action: "ui.open_git", Scope: "revisions", Seq: config.StringList{"g", "g"}},
+	}
+	assert.Equal(t, "shift+t", bindingKeyHint("ui.rebase_onto_trunk"))
+	assert.Equal(t, "g g", bindingKeyHint("ui.open_git"))
+	assert.Equal(t, "", bindingKeyHint("ui.unknown"))
+}
+
+func Test_Update_RecoverCommandRetriesWithSuggestedFlag(t *testing.T) {
+	commandRunner := test.NewTestCommandRunner(t)
+	commandRunner.Expect(jj.Args("abandon", "-r", "abc", "--ignore-immutable"))
+	defer commandRunner.Verify()
+
+	ctx := test.NewTestContext(commandRunner)
+	model := NewUI(ctx)
+
+	model.Update(common.CommandRunningMsg{ID: 1, Command: "jj abandon -r abc", Args: []string{"abandon", "-r", "abc"}})
+	model.Update(common.CommandCompletedMsg{ID: 1, Err: errors.New("Error: Commit abc is immutable")})
+
+	cmd := model.Update(intents.RecoverCommand{})
+	require.NotNil(t, cmd)
+	batch, ok := cmd().(tea.BatchMsg)
+	require.True(t, ok)
+	batch[0]()
+
+	assert.Nil(t, model.Update(intents.RecoverCommand{}), "the recovery is only offered once")
+}
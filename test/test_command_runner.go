@@ -3,6 +3,7 @@ package test
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"slices"
 	"sync"
@@ -37,6 +38,9 @@ type CommandRunner struct {
 	*testing.T
 	expectations map[string][]*ExpectedCommand
 	mutex        sync.Mutex
+	running      []appContext.RunningCommand
+	canceled     []int
+	queued       int
 }
 
 func (t *CommandRunner) RunCommandImmediate(args []string) ([]byte, error) {
@@ -63,6 +67,30 @@ func (t *CommandRunner) RunCommandImmediateWithEnv(args []string, _ []string) ([
 	return t.RunCommandImmediate(args)
 }
 
+func (t *CommandRunner) RunCommandImmediateWithClass(args []string, _ string) ([]byte, error) {
+	return t.RunCommandImmediate(args)
+}
+
+func (t *CommandRunner) RunCommandImmediateWithLimit(args []string, _ string, limit int) ([]byte, error) {
+	output, err := t.RunCommandImmediate(args)
+	if err == nil && limit > 0 && len(output) > limit {
+		return nil, fmt.Errorf("%w of %d bytes", appContext.ErrOutputTooLarge, limit)
+	}
+	return output, err
+}
+
+func (t *CommandRunner) RunCommandImmediateIfIdle(args []string, class string, limit int) ([]byte, error) {
+	return t.RunCommandImmediateWithLimit(args, class, limit)
+}
+
+func (t *CommandRunner) RunCommandImmediateCombined(args []string) ([]byte, error) {
+	return t.RunCommandImmediate(args)
+}
+
+func (t *CommandRunner) RunCommandBackground(args []string) ([]byte, error) {
+	return t.RunCommandImmediate(args)
+}
+
 func (t *CommandRunner) RunCommandStreaming(_ context.Context, args []string) (*appContext.StreamingCommand, error) {
 	reader, err := t.RunCommandImmediate(args)
 	return &appContext.StreamingCommand{
@@ -91,6 +119,52 @@ func (t *CommandRunner) RunCommand(args []string, continuations ...tea.Cmd) tea.
 	return tea.Batch(cmds...)
 }
 
+func (t *CommandRunner) CancelCommand(id int) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.canceled = append(t.canceled, id)
+	return nil
+}
+
+func (t *CommandRunner) RunningCommands() []appContext.RunningCommand {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return slices.Clone(t.running)
+}
+
+// SetRunning sets the commands reported by RunningCommands.
+func (t *CommandRunner) SetRunning(commands ...appContext.RunningCommand) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.running = commands
+}
+
+func (t *CommandRunner) QueuedCommands() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.queued
+}
+
+func (t *CommandRunner) QueueBusy() bool {
+	return t.QueuedCommands() > 0
+}
+
+func (t *CommandRunner) WaitForQueue() {}
+
+// SetQueued sets the number of commands reported as waiting in the queue.
+func (t *CommandRunner) SetQueued(n int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.queued = n
+}
+
+// Canceled returns the ids passed to CancelCommand.
+func (t *CommandRunner) Canceled() []int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return slices.Clone(t.canceled)
+}
+
 func (t *CommandRunner) RunInteractiveCommand(args []string, continuation tea.Cmd) tea.Cmd {
 	return t.RunCommand(args, continuation)
 }